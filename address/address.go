@@ -0,0 +1,163 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package address validates and normalizes chain addresses offline.
+//
+// The rules depend on the chain family: EVM addresses are hex with an optional EIP-55 checksum,
+// Bitcoin addresses are base58check or bech32, Solana addresses are base58 encoded public keys,
+// Tron addresses are base58check with a 0x41 prefix and Sui addresses are 32 byte hex.
+// Addresses of other families are accepted as they are.
+package address
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/errcode"
+)
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+// Error describes why an address is not valid for a chain family.
+type Error struct {
+	Family  chains.Family
+	Address string
+	Reason  string
+}
+
+func (e *Error) Error() string {
+	return "invalid " + string(e.Family) + " address " + e.Address + ": " + e.Reason
+}
+
+func (e *Error) Is(target error) bool {
+	return target == ErrInvalidAddress
+}
+
+func invalid(family chains.Family, addr, reason string) error {
+	return &Error{Family: family, Address: addr, Reason: reason}
+}
+
+// Validate reports whether addr is a well-formed address on chainId.
+// Addresses of chains whose family is unknown are always valid.
+func Validate(chainId chains.ChainID, addr string) error {
+	_, err := normalize(family(chainId, addr), addr)
+	return err
+}
+
+// Normalize returns the canonical form of addr on chainId, the form OKX OS expects:
+// EVM and bech32 addresses are lowercased, Tron hex addresses are converted to base58check
+// and Sui addresses are lowercased and padded to 32 bytes.
+func Normalize(chainId chains.ChainID, addr string) (string, error) {
+	return normalize(family(chainId, addr), addr)
+}
+
+// Canonical is like Normalize but returns addr unchanged when it is not valid.
+// An empty chainId normalizes addresses whose shape identifies the family, such as EVM addresses.
+func Canonical(chainId chains.ChainID, addr string) string {
+	if s, err := Normalize(chainId, addr); err == nil {
+		return s
+	}
+	return addr
+}
+
+// Check records an error on v when addr is set but is not a valid address on chainId.
+func Check(v *errcode.ValidationError, field string, chainId chains.ChainID, addr string) {
+	if addr == "" || chainId == "" {
+		return
+	}
+	if err := Validate(chainId, addr); err != nil {
+		var e *Error
+		if errors.As(err, &e) {
+			v.Addf(field, "is not a valid %s address: %s", e.Family, e.Reason)
+			return
+		}
+		v.Add(field, err.Error())
+	}
+}
+
+// CheckToken is like Check for token addresses. Only EVM, Solana and Tron tokens are
+// identified by an address; Bitcoin and Sui tokens use other identifiers and are not checked.
+func CheckToken(v *errcode.ValidationError, field string, chainId chains.ChainID, addr string) {
+	switch chainId.Family() {
+	case chains.FamilyEVM, chains.FamilySolana, chains.FamilyTron:
+		Check(v, field, chainId, addr)
+	}
+}
+
+// NormalizeToken is like Canonical for token addresses.
+func NormalizeToken(chainId chains.ChainID, addr string) string {
+	switch chainId.Family() {
+	case chains.FamilyEVM, chains.FamilyTron:
+		return Canonical(chainId, addr)
+	}
+	return addr
+}
+
+func family(chainId chains.ChainID, addr string) chains.Family {
+	if chainId != "" {
+		return chainId.Family()
+	}
+	if isHexAddress(addr) {
+		return chains.FamilyEVM
+	}
+	return chains.FamilyUnknown
+}
+
+func normalize(family chains.Family, addr string) (string, error) {
+	switch family {
+	case chains.FamilyEVM:
+		return normalizeEVM(addr)
+	case chains.FamilyUTXO:
+		return normalizeBitcoin(addr)
+	case chains.FamilySolana:
+		return normalizeSolana(addr)
+	case chains.FamilyTron:
+		return normalizeTron(addr)
+	case chains.FamilySui:
+		return normalizeSui(addr)
+	}
+	return addr, nil
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func has0xPrefix(s string) bool {
+	return len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}
+
+func isHexAddress(s string) bool {
+	return len(s) == 42 && has0xPrefix(s) && isHex(s[2:])
+}
+
+func isLower(s string) bool {
+	return strings.ToLower(s) == s
+}
+
+func isUpper(s string) bool {
+	return strings.ToUpper(s) == s
+}
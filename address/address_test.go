@@ -0,0 +1,94 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package address
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/errcode"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		chainId chains.ChainID
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{chains.Ethereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", false},
+		{chains.Ethereum, "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", false},
+		{chains.Ethereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", "", true},
+		{chains.Ethereum, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", "", true},
+		{chains.Bitcoin, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", false},
+		{chains.Bitcoin, "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", false},
+		{chains.Bitcoin, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3", "", true},
+		{chains.Bitcoin, "BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", false},
+		{chains.Bitcoin, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", false},
+		{chains.Bitcoin, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdr", "", true},
+		{chains.Tron, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", false},
+		{chains.Tron, "41a614f803b6fd780986a42c78ec9c7f77e6ded13c", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", false},
+		{chains.Tron, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", "", true},
+		{chains.Solana, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", false},
+		{chains.Solana, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "", true},
+		{chains.Sui, "0x2", "0x0000000000000000000000000000000000000000000000000000000000000002", false},
+		{chains.Sui, "0xZZ", "", true},
+		{chains.TON, "anything", "anything", false},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.chainId, tt.addr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Normalize(%s, %s) error = %v, wantErr %v", tt.chainId, tt.addr, err, tt.wantErr)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("Normalize(%s, %s) error = %v, want ErrInvalidAddress", tt.chainId, tt.addr, err)
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%s, %s) = %s, want %s", tt.chainId, tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	for _, want := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		got, err := ChecksumAddress(want)
+		if err != nil || got != want {
+			t.Errorf("ChecksumAddress(%s) = %s, %v", want, got, err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	v := new(errcode.ValidationError)
+	Check(v, "address", chains.Ethereum, "0x123")
+	Check(v, "address", chains.Ethereum, "")
+	CheckToken(v, "tokenAddress", chains.Sui, "0x2::sui::SUI")
+	CheckToken(v, "tokenAddress", chains.Solana, "So11111111111111111111111111111111111111112")
+	if len(v.Fields) != 1 || v.Fields[0].Field != "address" {
+		t.Fatalf("fields = %v", v.Fields)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package address
+
+import (
+	"strings"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/internal/base58"
+)
+
+const (
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+
+	bitcoinHRP = "bc"
+)
+
+func normalizeBitcoin(addr string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(addr), bitcoinHRP+"1") {
+		if err := validateSegwit(addr); err != nil {
+			return "", err
+		}
+		return strings.ToLower(addr), nil
+	}
+
+	payload, err := base58.CheckDecode(addr)
+	if err != nil {
+		return "", invalid(chains.FamilyUTXO, addr, err.Error())
+	}
+	// P2PKH addresses use version 0x00 and P2SH addresses use version 0x05.
+	if len(payload) != 21 || payload[0] != 0x00 && payload[0] != 0x05 {
+		return "", invalid(chains.FamilyUTXO, addr, "unknown address version")
+	}
+	return addr, nil
+}
+
+func validateSegwit(addr string) error {
+	hrp, data, spec, err := bech32Decode(addr)
+	if err != nil {
+		return invalid(chains.FamilyUTXO, addr, err.Error())
+	}
+	if hrp != bitcoinHRP {
+		return invalid(chains.FamilyUTXO, addr, "unknown human-readable part "+hrp)
+	}
+	if len(data) == 0 || data[0] > 16 {
+		return invalid(chains.FamilyUTXO, addr, "invalid witness version")
+	}
+	program, ok := convertBits(data[1:], 5, 8, false)
+	if !ok || len(program) < 2 || len(program) > 40 {
+		return invalid(chains.FamilyUTXO, addr, "invalid witness program")
+	}
+
+	version := data[0]
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return invalid(chains.FamilyUTXO, addr, "invalid witness program length")
+	}
+	// BIP-350: witness version 0 uses bech32, later versions use bech32m.
+	if version == 0 && spec != bech32Const || version != 0 && spec != bech32mConst {
+		return invalid(chains.FamilyUTXO, addr, "wrong checksum variant for witness version")
+	}
+	return nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// bech32Decode decodes a bech32 or bech32m string and returns its human-readable part,
+// the data without the checksum and the checksum constant that matched.
+func bech32Decode(s string) (hrp string, data []byte, spec uint32, err error) {
+	if len(s) > 90 {
+		return "", nil, 0, errorString("too long")
+	}
+	if !isLower(s) && !isUpper(s) {
+		return "", nil, 0, errorString("mixed case")
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, 0, errorString("invalid separator position")
+	}
+
+	hrp = s[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, errorString("invalid character in human-readable part")
+		}
+	}
+	for i := pos + 1; i < len(s); i++ {
+		d := strings.IndexByte(bech32Charset, s[i])
+		if d < 0 {
+			return "", nil, 0, errorString("invalid character in data part")
+		}
+		data = append(data, byte(d))
+	}
+
+	spec = bech32Polymod(append(bech32HRPExpand(hrp), data...))
+	if spec != bech32Const && spec != bech32mConst {
+		return "", nil, 0, errorString("invalid checksum")
+	}
+	return hrp, data[:len(data)-6], spec, nil
+}
+
+func convertBits(data []byte, from, to uint, pad bool) ([]byte, bool) {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, v := range data {
+		if uint(v)>>from != 0 {
+			return nil, false
+		}
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+type errorString string
+
+func (e errorString) Error() string {
+	return string(e)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package address
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/internal/keccak"
+)
+
+// ChecksumAddress returns addr with the EIP-55 mixed-case checksum applied.
+func ChecksumAddress(addr string) (string, error) {
+	if !isHexAddress(addr) {
+		return "", invalid(chains.FamilyEVM, addr, "must be 0x followed by 40 hex characters")
+	}
+	return checksum(strings.ToLower(addr[2:])), nil
+}
+
+func checksum(lower string) string {
+	sum := keccak.Sum256([]byte(lower))
+	digest := hex.EncodeToString(sum[:])
+
+	b := []byte(lower)
+	for i, c := range b {
+		if 'a' <= c && c <= 'f' && digest[i] >= '8' {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(b)
+}
+
+func normalizeEVM(addr string) (string, error) {
+	if !isHexAddress(addr) {
+		return "", invalid(chains.FamilyEVM, addr, "must be 0x followed by 40 hex characters")
+	}
+	body := addr[2:]
+	lower := strings.ToLower(body)
+	// all lower or all upper case addresses carry no checksum.
+	if body != lower && !isUpper(body) && checksum(lower)[2:] != body {
+		return "", invalid(chains.FamilyEVM, addr, "bad EIP-55 checksum")
+	}
+	return "0x" + lower, nil
+}
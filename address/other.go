@@ -0,0 +1,66 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package address
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/internal/base58"
+)
+
+func normalizeSolana(addr string) (string, error) {
+	if len(addr) < 32 || len(addr) > 44 {
+		return "", invalid(chains.FamilySolana, addr, "must be 32 to 44 base58 characters")
+	}
+	b, err := base58.Decode(addr)
+	if err != nil {
+		return "", invalid(chains.FamilySolana, addr, err.Error())
+	}
+	if len(b) != 32 {
+		return "", invalid(chains.FamilySolana, addr, "must decode to a 32 byte public key")
+	}
+	return addr, nil
+}
+
+func normalizeTron(addr string) (string, error) {
+	// Tron addresses are also written as hex with the 0x41 prefix, e.g. 41a614f803b6fd780986a42c78ec9c7f77e6ded13c.
+	if len(addr) == 42 && strings.HasPrefix(addr, "41") && isHex(addr) {
+		b, _ := hex.DecodeString(addr)
+		return base58.CheckEncode(b), nil
+	}
+
+	payload, err := base58.CheckDecode(addr)
+	if err != nil {
+		return "", invalid(chains.FamilyTron, addr, err.Error())
+	}
+	if len(payload) != 21 || payload[0] != 0x41 {
+		return "", invalid(chains.FamilyTron, addr, "must be 21 bytes starting with 0x41")
+	}
+	return addr, nil
+}
+
+func normalizeSui(addr string) (string, error) {
+	if !has0xPrefix(addr) || len(addr) < 3 || len(addr) > 66 || !isHex(addr[2:]) {
+		return "", invalid(chains.FamilySui, addr, "must be 0x followed by up to 64 hex characters")
+	}
+	return "0x" + strings.Repeat("0", 66-len(addr)) + strings.ToLower(addr[2:]), nil
+}
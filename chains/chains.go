@@ -0,0 +1,187 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package chains identifies the chains supported by OKX OS.
+//
+// The DEX API calls the identifier chainId and the wallet API calls it chainIndex, but both use
+// the same values, e.g. "1" for Ethereum and "501" for Solana, so a single ChainID is used for both.
+package chains
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChainID identifies a chain, e.g. "1" for Ethereum.
+type ChainID string
+
+const (
+	Bitcoin      ChainID = "0"
+	Ethereum     ChainID = "1"
+	Optimism     ChainID = "10"
+	Cronos       ChainID = "25"
+	BNBChain     ChainID = "56"
+	OKTC         ChainID = "66"
+	Gnosis       ChainID = "100"
+	Polygon      ChainID = "137"
+	XLayer       ChainID = "196"
+	Fantom       ChainID = "250"
+	ZkSyncEra    ChainID = "324"
+	PolygonZkEVM ChainID = "1101"
+	Mantle       ChainID = "5000"
+	Base         ChainID = "8453"
+	Arbitrum     ChainID = "42161"
+	Avalanche    ChainID = "43114"
+	Linea        ChainID = "59144"
+	Blast        ChainID = "81457"
+	Scroll       ChainID = "534352"
+	Tron         ChainID = "195"
+	Solana       ChainID = "501"
+	TON          ChainID = "607"
+	Sui          ChainID = "784"
+)
+
+// FromInt64 returns the ChainID of a numeric chain id, as returned by the DEX supported chains API.
+func FromInt64(id int64) ChainID {
+	return ChainID(strconv.FormatInt(id, 10))
+}
+
+func (id ChainID) String() string {
+	return string(id)
+}
+
+// Int64 returns id as a number.
+func (id ChainID) Int64() (int64, error) {
+	return strconv.ParseInt(string(id), 10, 64)
+}
+
+// Family returns the chain family of a built-in chain, or FamilyUnknown.
+func (id ChainID) Family() Family {
+	if c, ok := builtin[id]; ok {
+		return c.Family
+	}
+	return FamilyUnknown
+}
+
+// IsEVM reports whether id is a built-in EVM chain.
+func (id ChainID) IsEVM() bool {
+	return id.Family() == FamilyEVM
+}
+
+// UnmarshalJSON accepts both quoted and bare numeric ids, as the API uses both.
+func (id *ChainID) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*id = ChainID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("chains: invalid chain id %s", b)
+	}
+	*id = ChainID(n.String())
+	return nil
+}
+
+// Family is a group of chains that share address formats and transaction encoding.
+type Family string
+
+const (
+	FamilyUnknown Family = ""
+	FamilyEVM     Family = "evm"
+	FamilyUTXO    Family = "utxo"
+	FamilySolana  Family = "solana"
+	FamilyTron    Family = "tron"
+	FamilySui     Family = "sui"
+	FamilyTON     Family = "ton"
+)
+
+// Chain describes a chain and what OKX OS supports on it.
+type Chain struct {
+	ID        ChainID `json:"id"`
+	Name      string  `json:"name"`
+	ShortName string  `json:"shortName"`
+	Family    Family  `json:"family"`
+
+	// NativeTokenAddress is the address the DEX API uses for the native token of the chain,
+	// e.g. 0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee on EVM chains.
+	NativeTokenAddress string `json:"nativeTokenAddress"`
+	NativeSymbol       string `json:"nativeSymbol"`
+	NativeDecimals     int    `json:"nativeDecimals"`
+
+	// ExplorerURL is the base URL of the block explorer.
+	ExplorerURL string `json:"explorerUrl"`
+	// DexTokenApproveAddress is the spender to approve before swapping on OKX DEX.
+	DexTokenApproveAddress string `json:"dexTokenApproveAddress"`
+
+	// Dex, CrossChain and Wallet report which OKX OS APIs support the chain.
+	Dex        bool `json:"dex"`
+	CrossChain bool `json:"crossChain"`
+	Wallet     bool `json:"wallet"`
+
+	txPath      string
+	addressPath string
+}
+
+// IsNativeToken reports whether tokenAddress is the native token sentinel of the chain.
+func (c *Chain) IsNativeToken(tokenAddress string) bool {
+	if c.NativeTokenAddress == "" {
+		return false
+	}
+	if c.Family == FamilyEVM {
+		return strings.EqualFold(tokenAddress, c.NativeTokenAddress)
+	}
+	return tokenAddress == c.NativeTokenAddress
+}
+
+// fillExplorerPaths sets the explorer paths of the chain's family if it has none.
+func (c *Chain) fillExplorerPaths() {
+	if c.txPath != "" || c.addressPath != "" {
+		return
+	}
+	if paths, ok := explorerPaths[c.Family]; ok {
+		c.txPath, c.addressPath = paths[0], paths[1]
+	}
+}
+
+// TxURL returns the block explorer page of a transaction, or "" if the explorer is unknown.
+func (c *Chain) TxURL(hash string) string {
+	if c.ExplorerURL == "" || c.txPath == "" {
+		return ""
+	}
+	return c.ExplorerURL + c.txPath + hash
+}
+
+// AddressURL returns the block explorer page of an address, or "" if the explorer is unknown.
+func (c *Chain) AddressURL(address string) string {
+	if c.ExplorerURL == "" || c.addressPath == "" {
+		return ""
+	}
+	return c.ExplorerURL + c.addressPath + address
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package chains
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+type staticSource []Chain
+
+func (s staticSource) ListChains(ctx context.Context) ([]Chain, error) {
+	return s, nil
+}
+
+func TestChainIDUnmarshalJSON(t *testing.T) {
+	var ids []ChainID
+	if err := json.Unmarshal([]byte(`[1, "501", null, 42161]`), &ids); err != nil {
+		t.Fatal(err)
+	}
+	want := []ChainID{Ethereum, Solana, "", Arbitrum}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("ids[%d] = %q, want %q", i, ids[i], want[i])
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	err := r.Load(context.Background(),
+		staticSource{{ID: Ethereum, Name: "Ethereum Mainnet", DexTokenApproveAddress: "0x40aa958dd87fc8305b97f2ba922cdca374bcd7f", Dex: true}},
+		staticSource{{ID: Ethereum, Wallet: true}, {ID: "9999", Name: "New Chain", Wallet: true}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eth, ok := r.Get(Ethereum)
+	if !ok {
+		t.Fatal("expected Ethereum in the registry")
+	}
+	if eth.Name != "Ethereum" || eth.DexTokenApproveAddress == "" || !eth.Dex || !eth.Wallet || eth.CrossChain {
+		t.Errorf("unexpected merge result %+v", eth)
+	}
+	if !eth.IsNativeToken("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE") {
+		t.Error("expected the EVM native token sentinel to match case-insensitively")
+	}
+	if got := eth.TxURL("0xabc"); got != "https://etherscan.io/tx/0xabc" {
+		t.Errorf("TxURL = %s", got)
+	}
+
+	c, ok := r.Get("9999")
+	if !ok || c.Family != FamilyUnknown || !c.Wallet {
+		t.Errorf("unexpected new chain %+v", c)
+	}
+	if c.TxURL("0xabc") != "" {
+		t.Error("expected no explorer for a chain only known from the APIs")
+	}
+	r.Merge(Chain{ID: "8888", Family: FamilyEVM, ExplorerURL: "https://scan.example"})
+	if c, _ := r.Get("8888"); c.AddressURL("0x1") != "https://scan.example/address/0x1" {
+		t.Errorf("AddressURL = %s", c.AddressURL("0x1"))
+	}
+	if Solana.Family() != FamilySolana || !Base.IsEVM() {
+		t.Error("unexpected built-in families")
+	}
+	if n := len(r.Filter(func(c *Chain) bool { return c.Dex })); n != 1 {
+		t.Errorf("Filter returned %d DEX chains, want 1", n)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package chains
+
+import (
+	"context"
+	"sync"
+)
+
+// Source lists the chains supported by one of the OKX OS APIs.
+// DexAPI, CrossChainAPI and WalletAPI implement it.
+type Source interface {
+	ListChains(ctx context.Context) ([]Chain, error)
+}
+
+// Registry merges the supported chains of the OKX OS APIs with the built-in table.
+type Registry struct {
+	mu     sync.RWMutex
+	chains map[ChainID]*Chain
+}
+
+// NewRegistry creates a Registry that knows the built-in chains.
+func NewRegistry() *Registry {
+	r := &Registry{chains: make(map[ChainID]*Chain, len(builtin))}
+	for id, c := range builtin {
+		cp := *c
+		r.chains[id] = &cp
+	}
+	return r
+}
+
+// Load fetches the chains of every source and merges them into the registry.
+// Fields already known are kept, and the Dex, CrossChain and Wallet flags are combined.
+func (r *Registry) Load(ctx context.Context, sources ...Source) error {
+	for _, src := range sources {
+		list, err := src.ListChains(ctx)
+		if err != nil {
+			return err
+		}
+		r.Merge(list...)
+	}
+	return nil
+}
+
+// Merge adds chains to the registry. The OKX OS APIs do not report block explorers, so chains
+// only known from them have none; chains merged with an ExplorerURL and a known Family get the
+// explorer paths of their family.
+func (r *Registry) Merge(chains ...Chain) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range chains {
+		existing, ok := r.chains[c.ID]
+		if !ok {
+			cp := c
+			cp.fillExplorerPaths()
+			r.chains[c.ID] = &cp
+			continue
+		}
+		merge(existing, &c)
+		existing.fillExplorerPaths()
+	}
+}
+
+func merge(dst, src *Chain) {
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	fill(&dst.Name, src.Name)
+	fill(&dst.ShortName, src.ShortName)
+	fill(&dst.NativeTokenAddress, src.NativeTokenAddress)
+	fill(&dst.NativeSymbol, src.NativeSymbol)
+	fill(&dst.ExplorerURL, src.ExplorerURL)
+	fill(&dst.DexTokenApproveAddress, src.DexTokenApproveAddress)
+	if dst.Family == FamilyUnknown {
+		dst.Family = src.Family
+	}
+	if dst.NativeDecimals == 0 {
+		dst.NativeDecimals = src.NativeDecimals
+	}
+	dst.Dex = dst.Dex || src.Dex
+	dst.CrossChain = dst.CrossChain || src.CrossChain
+	dst.Wallet = dst.Wallet || src.Wallet
+}
+
+// Get returns the chain with the given id.
+func (r *Registry) Get(id ChainID) (Chain, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.chains[id]
+	if !ok {
+		return Chain{}, false
+	}
+	return *c, true
+}
+
+// Family returns the family of the chain with the given id, or FamilyUnknown.
+func (r *Registry) Family(id ChainID) Family {
+	c, _ := r.Get(id)
+	return c.Family
+}
+
+// All returns every chain in the registry sorted by id.
+func (r *Registry) All() []Chain {
+	r.mu.RLock()
+	list := make([]Chain, 0, len(r.chains))
+	for _, c := range r.chains {
+		list = append(list, *c)
+	}
+	r.mu.RUnlock()
+	sortChains(list)
+	return list
+}
+
+// Filter returns the chains for which keep returns true, sorted by id.
+func (r *Registry) Filter(keep func(*Chain) bool) []Chain {
+	var list []Chain
+	for _, c := range r.All() {
+		if keep(&c) {
+			list = append(list, c)
+		}
+	}
+	return list
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package chains
+
+import "sort"
+
+// EVMNativeTokenAddress is the address the DEX API uses for the native token of EVM chains.
+const EVMNativeTokenAddress = "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"
+
+const (
+	evmTxPath      = "/tx/"
+	evmAddressPath = "/address/"
+)
+
+// explorerPaths are the transaction and address paths of the usual explorers of each family,
+// used for chains merged into a Registry from outside the built-in table.
+var explorerPaths = map[Family][2]string{
+	FamilyEVM:    {evmTxPath, evmAddressPath},
+	FamilyUTXO:   {"/tx/", "/address/"},
+	FamilySolana: {"/tx/", "/account/"},
+	FamilyTron:   {"/#/transaction/", "/#/address/"},
+	FamilyTON:    {"/transaction/", "/"},
+	FamilySui:    {"/tx/", "/account/"},
+}
+
+func evm(id ChainID, name, short, symbol, explorer string) *Chain {
+	return &Chain{
+		ID:                 id,
+		Name:               name,
+		ShortName:          short,
+		Family:             FamilyEVM,
+		NativeTokenAddress: EVMNativeTokenAddress,
+		NativeSymbol:       symbol,
+		NativeDecimals:     18,
+		ExplorerURL:        explorer,
+		txPath:             evmTxPath,
+		addressPath:        evmAddressPath,
+	}
+}
+
+// builtin is the table of well-known chains. The registry fills in what it lacks from the APIs.
+var builtin = map[ChainID]*Chain{
+	Ethereum:     evm(Ethereum, "Ethereum", "eth", "ETH", "https://etherscan.io"),
+	Optimism:     evm(Optimism, "Optimism", "op", "ETH", "https://optimistic.etherscan.io"),
+	Cronos:       evm(Cronos, "Cronos", "cro", "CRO", "https://cronoscan.com"),
+	BNBChain:     evm(BNBChain, "BNB Chain", "bsc", "BNB", "https://bscscan.com"),
+	OKTC:         evm(OKTC, "OKTC", "oktc", "OKT", "https://www.oklink.com/oktc"),
+	Gnosis:       evm(Gnosis, "Gnosis", "gnosis", "XDAI", "https://gnosisscan.io"),
+	Polygon:      evm(Polygon, "Polygon", "polygon", "POL", "https://polygonscan.com"),
+	XLayer:       evm(XLayer, "X Layer", "xlayer", "OKB", "https://www.oklink.com/xlayer"),
+	Fantom:       evm(Fantom, "Fantom", "ftm", "FTM", "https://ftmscan.com"),
+	ZkSyncEra:    evm(ZkSyncEra, "zkSync Era", "zksync", "ETH", "https://explorer.zksync.io"),
+	PolygonZkEVM: evm(PolygonZkEVM, "Polygon zkEVM", "polygon_zkevm", "ETH", "https://zkevm.polygonscan.com"),
+	Mantle:       evm(Mantle, "Mantle", "mantle", "MNT", "https://explorer.mantle.xyz"),
+	Base:         evm(Base, "Base", "base", "ETH", "https://basescan.org"),
+	Arbitrum:     evm(Arbitrum, "Arbitrum One", "arb", "ETH", "https://arbiscan.io"),
+	Avalanche:    evm(Avalanche, "Avalanche C-Chain", "avax", "AVAX", "https://snowtrace.io"),
+	Linea:        evm(Linea, "Linea", "linea", "ETH", "https://lineascan.build"),
+	Blast:        evm(Blast, "Blast", "blast", "ETH", "https://blastscan.io"),
+	Scroll:       evm(Scroll, "Scroll", "scroll", "ETH", "https://scrollscan.com"),
+	Bitcoin: {
+		ID:             Bitcoin,
+		Name:           "Bitcoin",
+		ShortName:      "btc",
+		Family:         FamilyUTXO,
+		NativeSymbol:   "BTC",
+		NativeDecimals: 8,
+		ExplorerURL:    "https://mempool.space",
+		txPath:         "/tx/",
+		addressPath:    "/address/",
+	},
+	Solana: {
+		ID:                 Solana,
+		Name:               "Solana",
+		ShortName:          "sol",
+		Family:             FamilySolana,
+		NativeTokenAddress: "11111111111111111111111111111111",
+		NativeSymbol:       "SOL",
+		NativeDecimals:     9,
+		ExplorerURL:        "https://solscan.io",
+		txPath:             "/tx/",
+		addressPath:        "/account/",
+	},
+	Tron: {
+		ID:                 Tron,
+		Name:               "Tron",
+		ShortName:          "trx",
+		Family:             FamilyTron,
+		NativeTokenAddress: "T9yD14Nj9j7xAB4dbGeiX9h8unkKHxuWwb",
+		NativeSymbol:       "TRX",
+		NativeDecimals:     6,
+		ExplorerURL:        "https://tronscan.org",
+		txPath:             "/#/transaction/",
+		addressPath:        "/#/address/",
+	},
+	TON: {
+		ID:                 TON,
+		Name:               "TON",
+		ShortName:          "ton",
+		Family:             FamilyTON,
+		NativeTokenAddress: "EQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAM9c",
+		NativeSymbol:       "TON",
+		NativeDecimals:     9,
+		ExplorerURL:        "https://tonviewer.com",
+		txPath:             "/transaction/",
+		addressPath:        "/",
+	},
+	Sui: {
+		ID:                 Sui,
+		Name:               "Sui",
+		ShortName:          "sui",
+		Family:             FamilySui,
+		NativeTokenAddress: "0x2::sui::SUI",
+		NativeSymbol:       "SUI",
+		NativeDecimals:     9,
+		ExplorerURL:        "https://suiscan.xyz/mainnet",
+		txPath:             "/tx/",
+		addressPath:        "/account/",
+	},
+}
+
+// Lookup returns the built-in description of a chain.
+func Lookup(id ChainID) (Chain, bool) {
+	c, ok := builtin[id]
+	if !ok {
+		return Chain{}, false
+	}
+	return *c, true
+}
+
+// Builtin returns the built-in chains sorted by id.
+func Builtin() []Chain {
+	list := make([]Chain, 0, len(builtin))
+	for _, c := range builtin {
+		list = append(list, *c)
+	}
+	sortChains(list)
+	return list
+}
+
+func sortChains(list []Chain) {
+	sort.Slice(list, func(i, j int) bool {
+		a, errA := list[i].ID.Int64()
+		b, errB := list[j].ID.Int64()
+		if errA != nil || errB != nil {
+			return list[i].ID < list[j].ID
+		}
+		return a < b
+	})
+}
@@ -42,6 +42,13 @@ type Client struct {
 
 	endpoint string
 	headers  http.Header
+
+	unknownFieldsHook func(*UnknownFields)
+	schemaHook        func(path string, report *SchemaReport)
+
+	limiter       Limiter
+	retryAttempts int
+	retryBackoff  time.Duration
 }
 
 func NewClient(key, secretKey, passphrase string, opts ...Option) *Client {
@@ -54,12 +61,38 @@ func NewClient(key, secretKey, passphrase string, opts ...Option) *Client {
 		client:     options.client,
 		endpoint:   options.endpoint,
 		headers:    options.headers,
+
+		unknownFieldsHook: options.unknownFieldsHook,
+		schemaHook:        options.schemaHook,
+
+		limiter:       options.limiter,
+		retryAttempts: options.retryAttempts,
+		retryBackoff:  options.retryBackoff,
 	}
 	return c
 }
 
-func (c *Client) request(method string, path string, params map[string]string, body any, result any) error {
-	req, err := c.newRequest(method, path, params, body)
+func (c *Client) request(ctx context.Context, method string, path string, params map[string]string, body any, result any) error {
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.do(ctx, method, path, params, body, result)
+		if err == nil || attempt >= c.retryAttempts || !errcode.IsRateLimitReached(err) {
+			return err
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, params map[string]string, body any, result any) error {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	req, err := c.newRequest(ctx, method, path, params, body)
 	if err != nil {
 		return err
 	}
@@ -69,10 +102,10 @@ func (c *Client) request(method string, path string, params map[string]string, b
 	}
 	defer resp.Body.Close()
 
-	return c.decode(resp.Body, result)
+	return c.decode(path, resp.Body, result)
 }
 
-func (c *Client) decode(body io.Reader, result any) error {
+func (c *Client) decode(path string, body io.Reader, result any) error {
 	resp := &Response{}
 	if err := json.NewDecoder(body).Decode(resp); err != nil {
 		return err
@@ -84,7 +117,17 @@ func (c *Client) decode(body io.Reader, result any) error {
 
 	// when the occurred error, resp.Data maybe is a `{}` or `[]`.
 	if resp.Data != nil {
-		return json.Unmarshal(resp.Data, result)
+		if c.schemaHook != nil {
+			if report, err := DiagnoseSchema(resp.Data, result); err == nil && report.HasIssues() {
+				c.schemaHook(path, report)
+			}
+		}
+		if err := json.Unmarshal(resp.Data, result); err != nil {
+			return err
+		}
+		if c.unknownFieldsHook != nil {
+			reportUnknownFields(path, result, c.unknownFieldsHook)
+		}
 	}
 
 	return nil
@@ -106,7 +149,7 @@ func (c *Client) sign(ts string, method string, path string, body *bytes.Buffer)
 	return sign(c.secretKey, buf)
 }
 
-func (c *Client) newRequest(method string, path string, params map[string]string, body any) (*http.Request, error) {
+func (c *Client) newRequest(ctx context.Context, method string, path string, params map[string]string, body any) (*http.Request, error) {
 	bodyBuf := bytes.NewBuffer(nil)
 	if body != nil {
 		if err := json.NewEncoder(bodyBuf).Encode(body); err != nil {
@@ -114,7 +157,7 @@ func (c *Client) newRequest(method string, path string, params map[string]string
 		}
 	}
 
-	req, err := http.NewRequest(method, c.endpoint+path, bodyBuf)
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, bodyBuf)
 	if err != nil {
 		return nil, err
 	}
@@ -141,11 +184,11 @@ func (c *Client) newRequest(method string, path string, params map[string]string
 }
 
 func (c *Client) Get(ctx context.Context, path string, params map[string]string, result any) error {
-	return c.request(http.MethodGet, path, params, nil, result)
+	return c.request(ctx, http.MethodGet, path, params, nil, result)
 }
 
 func (c *Client) Post(ctx context.Context, path string, body any, result any) error {
-	return c.request(http.MethodPost, path, nil, body, result)
+	return c.request(ctx, http.MethodPost, path, nil, body, result)
 }
 
 func sign(key []byte, reader io.Reader) string {
@@ -0,0 +1,160 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	knownFieldsCache sync.Map // map[reflect.Type]map[string]struct{}
+	rawMessageMap    = reflect.TypeOf(map[string]json.RawMessage(nil))
+)
+
+// DecodeExtra decodes the JSON object data into v, a pointer to a struct, and returns
+// the members of data that do not correspond to any field of v.
+//
+// Result types use it in their UnmarshalJSON to keep the fields OKX has added to a
+// response before this library declares them:
+//
+//	func (r *Result) UnmarshalJSON(data []byte) (err error) {
+//		type alias Result
+//		r.Extra, err = client.DecodeExtra(data, (*alias)(r))
+//		return
+//	}
+func DecodeExtra(data []byte, v any) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil || len(members) == 0 {
+		return nil, nil
+	}
+
+	known := knownFields(reflect.TypeOf(v).Elem())
+	for key := range members {
+		if _, ok := known[strings.ToLower(key)]; ok {
+			delete(members, key)
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// knownFields returns the lower-cased JSON names of the fields of the struct type t,
+// matching keys case-insensitively as encoding/json does.
+func knownFields(t reflect.Type) map[string]struct{} {
+	if v, ok := knownFieldsCache.Load(t); ok {
+		return v.(map[string]struct{})
+	}
+
+	known := make(map[string]struct{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			for k := range knownFields(indirectType(field.Type)) {
+				known[k] = struct{}{}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[strings.ToLower(name)] = struct{}{}
+	}
+
+	knownFieldsCache.Store(t, known)
+	return known
+}
+
+// UnknownFields describes response fields that a result type does not declare yet.
+type UnknownFields struct {
+	// Path is the request path of the response, e.g. /api/v5/dex/aggregator/quote.
+	Path string
+	// Type is the Go type that received the fields, e.g. dex.QuotesResult.
+	Type string
+	// Fields are the names of the unknown fields, sorted.
+	Fields []string
+}
+
+// reportUnknownFields walks the decoded result and calls hook for every struct whose
+// Extra field holds unknown members.
+func reportUnknownFields(path string, result any, hook func(*UnknownFields)) {
+	walkExtra(reflect.ValueOf(result), func(t reflect.Type, extra map[string]json.RawMessage) {
+		fields := make([]string, 0, len(extra))
+		for k := range extra {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+		hook(&UnknownFields{Path: path, Type: t.String(), Fields: fields})
+	})
+}
+
+func walkExtra(v reflect.Value, fn func(reflect.Type, map[string]json.RawMessage)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walkExtra(v.Elem(), fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkExtra(v.Index(i), fn)
+		}
+	case reflect.Map:
+		if v.Type() == rawMessageMap {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			walkExtra(iter.Value(), fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fv := v.Field(i)
+			if field.Name == "Extra" && field.Type == rawMessageMap {
+				if fv.Len() > 0 {
+					fn(t, fv.Interface().(map[string]json.RawMessage))
+				}
+				continue
+			}
+			walkExtra(fv, fn)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type extraResult struct {
+	Amount string                     `json:"amount"`
+	Extra  map[string]json.RawMessage `json:"-"`
+}
+
+func (r *extraResult) UnmarshalJSON(data []byte) (err error) {
+	type alias extraResult
+	r.Extra, err = DecodeExtra(data, (*alias)(r))
+	return
+}
+
+func TestDecodeExtra(t *testing.T) {
+	var r extraResult
+	if err := json.Unmarshal([]byte(`{"amount":"1","Amount":"2","newField":{"a":1}}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Amount != "2" {
+		t.Errorf("Amount = %q, want %q", r.Amount, "2")
+	}
+	want := map[string]json.RawMessage{"newField": json.RawMessage(`{"a":1}`)}
+	if !reflect.DeepEqual(r.Extra, want) {
+		t.Errorf("Extra = %s, want %s", r.Extra, want)
+	}
+}
+
+func TestWithStrictDecoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"0","msg":"","data":[{"amount":"1"},{"amount":"2","fee":"3","id":"4"}]}`))
+	}))
+	defer srv.Close()
+
+	var reports []*UnknownFields
+	c := NewClient("key", "secret", "passphrase",
+		WithEndpoint(srv.URL),
+		WithStrictDecoding(func(u *UnknownFields) { reports = append(reports, u) }),
+	)
+
+	var results []*extraResult
+	if err := c.Get(context.Background(), "/api/test", nil, &results); err != nil {
+		t.Fatal(err)
+	}
+	want := []*UnknownFields{{Path: "/api/test", Type: "client.extraResult", Fields: []string{"fee", "id"}}}
+	if !reflect.DeepEqual(reports, want) {
+		t.Errorf("reports = %+v, want %+v", reports, want)
+	}
+}
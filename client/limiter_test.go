@@ -0,0 +1,107 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/imzhongqi/okxos/errcode"
+)
+
+type countingLimiter struct{ n int }
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.n++
+	return nil
+}
+
+func TestWithRateLimitRetry(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits < 3 {
+			w.Write([]byte(`{"code":"50011","msg":"Rate limit reached","data":[]}`))
+			return
+		}
+		w.Write([]byte(`{"code":"0","msg":"","data":[]}`))
+	}))
+	defer srv.Close()
+
+	limiter := new(countingLimiter)
+	c := NewClient("key", "secret", "passphrase",
+		WithEndpoint(srv.URL),
+		WithRateLimiter(limiter),
+		WithRateLimitRetry(2, time.Millisecond),
+	)
+	var result []any
+	if err := c.Get(context.Background(), "/api/test", nil, &result); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 || limiter.n != 3 {
+		t.Errorf("hits = %d, limiter waits = %d, want 3 and 3", hits, limiter.n)
+	}
+
+	hits = 0
+	c = NewClient("key", "secret", "passphrase", WithEndpoint(srv.URL), WithRateLimitRetry(1, time.Millisecond))
+	if err := c.Get(context.Background(), "/api/test", nil, &result); !errcode.IsRateLimitReached(err) {
+		t.Errorf("err = %v, want rate limit reached", err)
+	}
+}
+
+func TestNewLimiter(t *testing.T) {
+	l := NewLimiter(1000, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < time.Millisecond {
+		t.Errorf("4 waits at 1000/s with burst 2 took %s, want at least 1ms", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := NewLimiter(0.001, 1)
+	slow.Wait(ctx)
+	if err := slow.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait on a canceled context = %v", err)
+	}
+}
+
+func TestNewLimiterUnlimited(t *testing.T) {
+	for _, rps := range []float64{0, -1, math.NaN(), math.Inf(1), 1e12} {
+		l := NewLimiter(rps, 1)
+		start := time.Now()
+		for i := 0; i < 100; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Fatalf("rps %v: %v", rps, err)
+			}
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("rps %v: 100 waits took %s", rps, d)
+		}
+	}
+}
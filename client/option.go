@@ -21,12 +21,20 @@ package client
 
 import (
 	"net/http"
+	"time"
 )
 
 type Options struct {
 	endpoint string
 	headers  http.Header
 	client   *http.Client
+
+	unknownFieldsHook func(*UnknownFields)
+	schemaHook        func(path string, report *SchemaReport)
+
+	limiter       Limiter
+	retryAttempts int
+	retryBackoff  time.Duration
 }
 
 type Option interface {
@@ -70,6 +78,40 @@ func WithProjectID(projectID string) Option {
 	return WithHeader("OK-ACCESS-PROJECT", projectID)
 }
 
+// WithStrictDecoding reports the response fields that the result types do not declare yet.
+// The hook is called after a response is decoded, once for every value whose Extra field
+// holds unknown fields, so schema drift can be logged without failing the request.
+func WithStrictDecoding(hook func(*UnknownFields)) Option {
+	return optionFunc(func(o *Options) {
+		o.unknownFieldsHook = hook
+	})
+}
+
+// WithSchemaDiagnostics compares every response with the result type it is decoded into and
+// calls hook with the report when they differ. The comparison runs before decoding, so the
+// report is available even when a drifted field makes decoding fail.
+func WithSchemaDiagnostics(hook func(path string, report *SchemaReport)) Option {
+	return optionFunc(func(o *Options) {
+		o.schemaHook = hook
+	})
+}
+
+// WithRateLimiter makes every request wait for limiter before it is sent.
+func WithRateLimiter(limiter Limiter) Option {
+	return optionFunc(func(o *Options) {
+		o.limiter = limiter
+	})
+}
+
+// WithRateLimitRetry retries a request rejected with 50011 (rate limit reached) up to attempts
+// times, waiting backoff before the first retry and doubling it for every following one.
+func WithRateLimitRetry(attempts int, backoff time.Duration) Option {
+	return optionFunc(func(o *Options) {
+		o.retryAttempts = attempts
+		o.retryBackoff = backoff
+	})
+}
+
 func newOptions(opts ...Option) Options {
 	o := Options{
 		endpoint: "https://www.okx.com",
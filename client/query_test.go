@@ -0,0 +1,72 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEncodeQuery(t *testing.T) {
+	yes := true
+	type embedded struct {
+		Page string `json:"page,omitempty"`
+	}
+	type request struct {
+		embedded
+		ChainId  string   `json:"chainId"`
+		Amount   string   `json:"amount"`
+		DexIds   []string `json:"dexIds,omitempty"`
+		Sort     int      `json:"sort,omitempty"`
+		Limit    int64    `json:"limit"`
+		Auto     bool     `json:"auto,omitempty"`
+		Exclude  *bool    `json:"exclude,omitempty"`
+		Filter   *bool    `json:"filter"`
+		Ignored  string   `json:"-"`
+		internal string
+	}
+
+	params, err := EncodeQuery(&request{
+		embedded: embedded{Page: "2"},
+		ChainId:  "1",
+		DexIds:   []string{"1", "50", "180"},
+		Exclude:  &yes,
+		Ignored:  "x",
+		internal: "y",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"page":    "2",
+		"chainId": "1",
+		"amount":  "",
+		"dexIds":  "1,50,180",
+		"limit":   "0",
+		"exclude": "true",
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("EncodeQuery() = %v, want %v", params, want)
+	}
+
+	if _, err := EncodeQuery(map[string]string{}); err == nil {
+		t.Error("expected error for non-struct value")
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// SchemaIssueKind classifies a difference between a response and the Go type it is decoded into.
+type SchemaIssueKind string
+
+const (
+	// SchemaMissingField means a declared field is absent from the response.
+	SchemaMissingField SchemaIssueKind = "missing_field"
+	// SchemaTypeMismatch means a response value cannot be decoded into the declared field type.
+	SchemaTypeMismatch SchemaIssueKind = "type_mismatch"
+	// SchemaNewField means the response has a field that is not declared.
+	SchemaNewField SchemaIssueKind = "new_field"
+)
+
+// SchemaIssue is a single difference found by DiagnoseSchema.
+type SchemaIssue struct {
+	Kind SchemaIssueKind `json:"kind"`
+	// Path locates the value in the response, e.g. $[0].routerList[1].toTokenAmount.
+	Path string `json:"path"`
+	// Expected is the declared Go type, empty for new fields.
+	Expected string `json:"expected,omitempty"`
+	// Actual is the JSON type found in the response, empty for missing fields.
+	Actual string `json:"actual,omitempty"`
+	// Error is the decoding error of a type mismatch.
+	Error string `json:"error,omitempty"`
+}
+
+func (i *SchemaIssue) String() string {
+	switch i.Kind {
+	case SchemaMissingField:
+		return fmt.Sprintf("%s: missing field of type %s", i.Path, i.Expected)
+	case SchemaTypeMismatch:
+		return fmt.Sprintf("%s: expected %s, got %s", i.Path, i.Expected, i.Actual)
+	default:
+		return fmt.Sprintf("%s: new field of type %s", i.Path, i.Actual)
+	}
+}
+
+// SchemaReport lists the differences between a response and the Go type it is decoded into.
+type SchemaReport struct {
+	// Type is the Go type the response was compared against.
+	Type   string         `json:"type"`
+	Issues []*SchemaIssue `json:"issues"`
+}
+
+// HasIssues reports whether any difference was found.
+func (r *SchemaReport) HasIssues() bool {
+	return len(r.Issues) > 0
+}
+
+// Filter returns the issues of the given kind.
+func (r *SchemaReport) Filter(kind SchemaIssueKind) []*SchemaIssue {
+	var issues []*SchemaIssue
+	for _, i := range r.Issues {
+		if i.Kind == kind {
+			issues = append(issues, i)
+		}
+	}
+	return issues
+}
+
+func (r *SchemaReport) String() string {
+	if !r.HasIssues() {
+		return r.Type + ": no schema issues"
+	}
+	lines := make([]string, 0, len(r.Issues)+1)
+	lines = append(lines, fmt.Sprintf("%s: %d schema issues", r.Type, len(r.Issues)))
+	for _, i := range r.Issues {
+		lines = append(lines, "  "+i.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiagnoseSchema compares the JSON document data against the type of v, which is usually the
+// pointer passed to json.Unmarshal, and reports missing fields, type mismatches and new fields.
+//
+// Unlike json.Unmarshal it does not stop at the first error, so a single drifted field does not
+// hide the rest of the report. It can be run against recorded responses in tests:
+//
+//	var results []*dex.QuotesResult
+//	report, err := client.DiagnoseSchema(recorded, &results)
+func DiagnoseSchema(data []byte, v any) (*SchemaReport, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("client: cannot diagnose schema of nil")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("client: invalid JSON document")
+	}
+
+	report := &SchemaReport{Type: t.String()}
+	diagnose(report, "$", data, t)
+	return report, nil
+}
+
+func diagnose(report *SchemaReport, path string, raw json.RawMessage, t reflect.Type) {
+	actual := jsonKind(raw)
+	if actual == "null" {
+		return
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	mismatch := func(err error) {
+		issue := &SchemaIssue{Kind: SchemaTypeMismatch, Path: path, Expected: t.String(), Actual: actual}
+		if err != nil {
+			issue.Error = err.Error()
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	custom := t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType)
+	switch {
+	case t.Kind() == reflect.Struct && actual == "object" && (!custom || hasExtra(t)):
+		diagnoseStruct(report, path, raw, t)
+	case t.Kind() == reflect.Interface:
+	case custom:
+		if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+			mismatch(err)
+		}
+	case t.Kind() == reflect.Struct:
+		mismatch(nil)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if actual != "array" {
+			mismatch(nil)
+			return
+		}
+		var items []json.RawMessage
+		_ = json.Unmarshal(raw, &items)
+		for i, item := range items {
+			diagnose(report, fmt.Sprintf("%s[%d]", path, i), item, t.Elem())
+		}
+	case t.Kind() == reflect.Map:
+		if actual != "object" {
+			mismatch(nil)
+			return
+		}
+		var members map[string]json.RawMessage
+		_ = json.Unmarshal(raw, &members)
+		for _, k := range sortedKeys(members) {
+			diagnose(report, path+"."+k, members[k], t.Elem())
+		}
+	default:
+		if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+			mismatch(err)
+		}
+	}
+}
+
+func diagnoseStruct(report *SchemaReport, path string, raw json.RawMessage, t reflect.Type) {
+	var members map[string]json.RawMessage
+	_ = json.Unmarshal(raw, &members)
+
+	seen := make(map[string]bool, len(members))
+	lookup := make(map[string]string, len(members))
+	for k := range members {
+		lookup[strings.ToLower(k)] = k
+	}
+
+	for _, field := range schemaFields(t) {
+		key, ok := lookup[strings.ToLower(field.name)]
+		if !ok {
+			if !field.omitempty {
+				report.Issues = append(report.Issues, &SchemaIssue{
+					Kind:     SchemaMissingField,
+					Path:     path + "." + field.name,
+					Expected: field.typ.String(),
+				})
+			}
+			continue
+		}
+		seen[key] = true
+		diagnose(report, path+"."+key, members[key], field.typ)
+	}
+
+	for _, k := range sortedKeys(members) {
+		if !seen[k] {
+			report.Issues = append(report.Issues, &SchemaIssue{
+				Kind:   SchemaNewField,
+				Path:   path + "." + k,
+				Actual: jsonKind(members[k]),
+			})
+		}
+	}
+}
+
+type schemaField struct {
+	name      string
+	typ       reflect.Type
+	omitempty bool
+}
+
+func schemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			fields = append(fields, schemaFields(indirectType(field.Type))...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, schemaField{
+			name:      name,
+			typ:       field.Type,
+			omitempty: strings.Contains(opts, "omitempty"),
+		})
+	}
+	return fields
+}
+
+// hasExtra reports whether the struct type t decodes with DecodeExtra, so its fields
+// can be compared even though it implements json.Unmarshaler.
+func hasExtra(t reflect.Type) bool {
+	field, ok := t.FieldByName("Extra")
+	return ok && field.Type == rawMessageMap
+}
+
+func jsonKind(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "null"
+	}
+	switch raw[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnoseSchema(t *testing.T) {
+	type order struct {
+		ChainIndex int64   `json:"chainIndex"`
+		OrderId    string  `json:"orderId"`
+		TxStatus   string  `json:"txStatus"`
+		Code       Integer `json:"code"`
+		Limit      string  `json:"limit,omitempty"`
+	}
+
+	recorded := []byte(`[
+		{"chainIndex":1,"orderId":"a","txStatus":"2","code":"0"},
+		{"chainIndex":"56","orderId":"b","code":1,"gasUsed":"21000"}
+	]`)
+
+	var results []*order
+	report, err := DiagnoseSchema(recorded, &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*SchemaIssue{
+		{Kind: SchemaTypeMismatch, Path: "$[1].chainIndex", Expected: "int64", Actual: "string"},
+		{Kind: SchemaMissingField, Path: "$[1].txStatus", Expected: "string"},
+		{Kind: SchemaNewField, Path: "$[1].gasUsed", Actual: "string"},
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("got %d issues, want %d:\n%s", len(report.Issues), len(want), report)
+	}
+	for i, issue := range report.Issues {
+		issue.Error = ""
+		if !reflect.DeepEqual(issue, want[i]) {
+			t.Errorf("Issues[%d] = %+v, want %+v", i, issue, want[i])
+		}
+	}
+	if got := len(report.Filter(SchemaTypeMismatch)); got != 1 {
+		t.Errorf("Filter(SchemaTypeMismatch) returned %d issues, want 1", got)
+	}
+}
@@ -22,28 +22,48 @@ package dex
 import (
 	"context"
 
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type ApproveTransactionsRequest struct {
 	// ChainId is the chain ID (e.g., 1 for Ethereum. See Chain IDs)
-	ChainId string `json:"chainId"`
+	ChainId chains.ChainID `json:"chainId"`
 	// TokenContractAddress is the contract address of a token to be sold (e.g., 0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee)
 	TokenContractAddress string `json:"tokenContractAddress"`
 	// ApproveAmount is the amount of token that needs to be permitted (set in minimal divisible units,
-	// e.g., 1.00 USDT set as 1000000, 1.00 DAI set as 1000000000000000000)
+	// e.g., 1.00 USDT set as 1000000, 1.00 DAI set as 1000000000000000000). 0 revokes the allowance.
 	ApproveAmount string `json:"approveAmount"`
 }
 
+// Validate reports the fields of the request that are missing or malformed.
+func (r *ApproveTransactionsRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainId", r.ChainId.String())
+	v.Required("tokenContractAddress", r.TokenContractAddress)
+	address.CheckToken(v, "tokenContractAddress", r.ChainId, r.TokenContractAddress)
+	v.AmountOrZero("approveAmount", r.ApproveAmount)
+	return v.Err()
+}
+
+func (r *ApproveTransactionsRequest) normalize() *ApproveTransactionsRequest {
+	n := *r
+	n.TokenContractAddress = address.NormalizeToken(r.ChainId, r.TokenContractAddress)
+	return &n
+}
+
 type ApproveTransactionsResult struct {
 	// Data is the call data
 	Data string `json:"data"`
 	// DexContractAddress is the contract address of OKX DEX approve (e.g., 0x6f9ffea7370310cd0f890dfde5e0e061059dcfd9)
 	DexContractAddress string `json:"dexContractAddress"`
 	// GasLimit is the gas limit (e.g., 50000)
-	GasLimit string `json:"gasLimit"`
+	GasLimit types.Uint256 `json:"gasLimit"`
 	// GasPrice is the gas price in wei (e.g., 110000000)
-	GasPrice string `json:"gasPrice"`
+	GasPrice types.Uint256 `json:"gasPrice"`
 }
 
 // GetApproveTx According to the ERC-20 standard,
@@ -51,10 +71,12 @@ type ApproveTransactionsResult struct {
 //
 // This API will generate the relevant data for calling the contract.
 func (s *DexAPI) GetApproveTx(ctx context.Context, req *ApproveTransactionsRequest) (*ApproveTransactionsResult, error) {
-	params := map[string]string{
-		"chainId":              req.ChainId,
-		"tokenContractAddress": req.TokenContractAddress,
-		"approveAmount":        req.ApproveAmount,
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := client.EncodeQuery(req.normalize())
+	if err != nil {
+		return nil, err
 	}
 	var results []*ApproveTransactionsResult
 	if err := s.tr.Get(ctx, "/api/v5/dex/aggregator/approve-transaction", params, &results); err != nil {
@@ -0,0 +1,262 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package calldata decodes the calldata of OKX DEX router transactions and ERC-20 approvals,
+// such as dex.Tx.Data and dex.ApproveTransactionsResult.Data.
+package calldata
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/imzhongqi/okxos/internal/keccak"
+	"github.com/imzhongqi/okxos/types"
+)
+
+var (
+	ErrUnknownSelector = errors.New("calldata: unknown function selector")
+	ErrMalformed       = errors.New("calldata: malformed calldata")
+)
+
+// Function names of the decoded calls.
+const (
+	SmartSwapByOrderId = "smartSwapByOrderId"
+	SmartSwapTo        = "smartSwapTo"
+	UnxswapByOrderId   = "unxswapByOrderId"
+	UnxswapTo          = "unxswapTo"
+	UniswapV3SwapTo    = "uniswapV3SwapTo"
+	Approve            = "approve"
+)
+
+const (
+	baseRequest = "(uint256,address,uint256,uint256,uint256)"
+	routerPath  = "(address[],address[],uint256[],bytes[],uint256)[][]"
+	pmmRequest  = "(uint256,address,address,address,uint256,uint256,uint256,uint256,bool,bytes)[]"
+)
+
+// signatures are the functions the decoder knows, by name. The selectors are derived from them.
+var signatures = map[string]string{
+	SmartSwapByOrderId: "smartSwapByOrderId(uint256," + baseRequest + ",uint256[]," + routerPath + "," + pmmRequest + ")",
+	SmartSwapTo:        "smartSwapTo(uint256,address," + baseRequest + ",uint256[]," + routerPath + "," + pmmRequest + ")",
+	UnxswapByOrderId:   "unxswapByOrderId(uint256,uint256,uint256,bytes32[])",
+	UnxswapTo:          "unxswapTo(uint256,uint256,uint256,address,bytes32[])",
+	UniswapV3SwapTo:    "uniswapV3SwapTo(uint256,uint256,uint256,uint256[])",
+	Approve:            "approve(address,uint256)",
+}
+
+var selectors = make(map[[4]byte]string, len(signatures))
+
+func init() {
+	for name, sig := range signatures {
+		selectors[Selector(sig)] = name
+	}
+}
+
+// Selector returns the 4 byte selector of a function signature, e.g. "approve(address,uint256)".
+func Selector(signature string) [4]byte {
+	var s [4]byte
+	sum := keccak.Sum256([]byte(signature))
+	copy(s[:], sum[:4])
+	return s
+}
+
+// Call is a decoded router call or approval. Fields that the function does not encode are left empty:
+// the unxswap and uniswapV3 functions only encode the pools, not the output token, and
+// uniswapV3SwapTo does not encode the input token either.
+type Call struct {
+	Function string `json:"function"`
+	Selector string `json:"selector"`
+
+	OrderId   types.Uint256 `json:"orderId,omitempty"`
+	FromToken string        `json:"fromToken,omitempty"`
+	ToToken   string        `json:"toToken,omitempty"`
+	Amount    types.Uint256 `json:"amount"`
+	MinReturn types.Uint256 `json:"minReturn,omitempty"`
+	// Deadline is the unix time in seconds after which the swap reverts.
+	Deadline types.Uint256 `json:"deadline,omitempty"`
+	// Receiver receives the output token. Empty means the sender.
+	Receiver string `json:"receiver,omitempty"`
+	// Pools are the pool words of the unxswap and uniswapV3 functions, as hex.
+	Pools []string `json:"pools,omitempty"`
+
+	// Spender is the approved address of an approval.
+	Spender string `json:"spender,omitempty"`
+
+	// Commission is the referrer commission appended to the calldata, if any.
+	Commission *Commission `json:"commission,omitempty"`
+}
+
+// IsSwap reports whether the call is a router swap rather than an approval.
+func (c *Call) IsSwap() bool {
+	return c.Function != Approve
+}
+
+// CommissionSide tells which token a commission is taken from.
+type CommissionSide string
+
+const (
+	CommissionFromToken CommissionSide = "fromToken"
+	CommissionToToken   CommissionSide = "toToken"
+)
+
+// Commission is the commission trailer the API appends to swaps with a feePercent: a word with the
+// token, followed by a word with a 6 byte flag, a 6 byte rate and the 20 byte referrer address.
+type Commission struct {
+	Side     CommissionSide `json:"side"`
+	Token    string         `json:"token"`
+	Referrer string         `json:"referrer"`
+	// Rate is the commission rate in parts per 10^9 of the amount.
+	Rate uint64 `json:"rate"`
+}
+
+var commissionFlags = map[string]CommissionSide{
+	"3ca20afc2aaa": CommissionFromToken,
+	"3ca20afc2bbb": CommissionToToken,
+}
+
+// Decode decodes hex calldata, with or without the 0x prefix.
+func Decode(data string) (*Call, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(data, "0x"), "0X"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return DecodeBytes(b)
+}
+
+// DecodeBytes decodes calldata.
+func DecodeBytes(data []byte) (*Call, error) {
+	if len(data) < 4 {
+		return nil, ErrMalformed
+	}
+	var sel [4]byte
+	copy(sel[:], data)
+	name, ok := selectors[sel]
+	if !ok {
+		return nil, fmt.Errorf("%w 0x%x", ErrUnknownSelector, sel)
+	}
+
+	a := &args{b: data[4:]}
+	c := &Call{Function: name, Selector: "0x" + hex.EncodeToString(sel[:])}
+	var err error
+	switch name {
+	case SmartSwapByOrderId:
+		c.OrderId = a.uint(0)
+		a.baseRequest(1, c)
+	case SmartSwapTo:
+		c.OrderId = a.uint(0)
+		c.Receiver = a.address(1)
+		a.baseRequest(2, c)
+	case UnxswapByOrderId:
+		c.FromToken, c.Amount, c.MinReturn = a.address(0), a.uint(1), a.uint(2)
+		c.Pools, err = a.words(3)
+	case UnxswapTo:
+		c.FromToken, c.Amount, c.MinReturn = a.address(0), a.uint(1), a.uint(2)
+		c.Receiver = a.address(3)
+		c.Pools, err = a.words(4)
+	case UniswapV3SwapTo:
+		c.Receiver, c.Amount, c.MinReturn = a.address(0), a.uint(1), a.uint(2)
+		c.Pools, err = a.words(3)
+	case Approve:
+		c.Spender, c.Amount = a.address(0), a.uint(1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if a.short {
+		return nil, ErrMalformed
+	}
+	if c.IsSwap() {
+		c.Commission = decodeCommission(data)
+	}
+	return c, nil
+}
+
+func decodeCommission(data []byte) *Commission {
+	if len(data) < 4+64 {
+		return nil
+	}
+	info := data[len(data)-32:]
+	side, ok := commissionFlags[hex.EncodeToString(info[:6])]
+	if !ok {
+		return nil
+	}
+	token := data[len(data)-64 : len(data)-32]
+	return &Commission{
+		Side:     side,
+		Token:    "0x" + hex.EncodeToString(token[12:]),
+		Referrer: "0x" + hex.EncodeToString(info[12:]),
+		Rate:     new(big.Int).SetBytes(info[6:12]).Uint64(),
+	}
+}
+
+// args reads the 32 byte words of ABI encoded arguments. Reading past the end sets short.
+type args struct {
+	b     []byte
+	short bool
+}
+
+func (a *args) word(i int) []byte {
+	start := i * 32
+	if start < 0 || start+32 > len(a.b) {
+		a.short = true
+		return make([]byte, 32)
+	}
+	return a.b[start : start+32]
+}
+
+func (a *args) uint(i int) types.Uint256 {
+	return types.NewUint256(new(big.Int).SetBytes(a.word(i)))
+}
+
+// address reads the low 20 bytes of a word, which also holds the token of the uint256 token
+// arguments of the unxswap functions and the receiver of uniswapV3SwapTo.
+func (a *args) address(i int) string {
+	return "0x" + hex.EncodeToString(a.word(i)[12:])
+}
+
+// words reads the dynamic bytes32 or uint256 array whose offset is at word i.
+func (a *args) words(i int) ([]string, error) {
+	offset := new(big.Int).SetBytes(a.word(i))
+	if !offset.IsInt64() || offset.Int64()%32 != 0 || offset.Int64() > int64(len(a.b)) {
+		return nil, ErrMalformed
+	}
+	at := int(offset.Int64() / 32)
+	n := new(big.Int).SetBytes(a.word(at))
+	if !n.IsInt64() || n.Int64() > int64(len(a.b)/32) {
+		return nil, ErrMalformed
+	}
+	out := make([]string, n.Int64())
+	for j := range out {
+		out[j] = "0x" + hex.EncodeToString(a.word(at+1+j))
+	}
+	return out, nil
+}
+
+// baseRequest reads the BaseRequest tuple (fromToken, toToken, fromTokenAmount, minReturnAmount,
+// deadLine) encoded in place from word i, fromToken being a uint256 holding the token address.
+func (a *args) baseRequest(i int, c *Call) {
+	c.FromToken = a.address(i)
+	c.ToToken = a.address(i + 1)
+	c.Amount = a.uint(i + 2)
+	c.MinReturn = a.uint(i + 3)
+	c.Deadline = a.uint(i + 4)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package calldata
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// word left-pads hex to a 32 byte word.
+func word(hex string) string {
+	return strings.Repeat("0", 64-len(hex)) + hex
+}
+
+func TestSelector(t *testing.T) {
+	for sig, want := range map[string]string{
+		signatures[SmartSwapByOrderId]: "b80c2f09",
+		signatures[Approve]:            "095ea7b3",
+	} {
+		if got := fmt.Sprintf("%x", Selector(sig)); got != want {
+			t.Errorf("Selector(%s) = %s, want %s", sig, got, want)
+		}
+	}
+}
+
+func TestDecodeSmartSwap(t *testing.T) {
+	data := "0xb80c2f09" +
+		word("3039") + // orderId
+		word("a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48") +
+		word("eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee") +
+		word("f4240") + // 1000000
+		word("3e8") + // 1000
+		word("6553f100") +
+		word("e0") + word("100") + word("120") + // offsets of the dynamic arrays
+		word("0") + word("0") + word("0") + // empty arrays
+		word("a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48") +
+		"3ca20afc2aaa" + "000001312d00" + "3f6a3f57569358a512ccc0e513f171516b0fd42a"
+
+	c, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Function != SmartSwapByOrderId || c.OrderId.String() != "12345" ||
+		c.FromToken != "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" ||
+		c.ToToken != "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee" ||
+		c.Amount.String() != "1000000" || c.MinReturn.String() != "1000" || c.Deadline.String() != "1700000000" {
+		t.Errorf("Decode = %+v", c)
+	}
+	want := Commission{Side: CommissionFromToken, Token: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Referrer: "0x3f6a3f57569358a512ccc0e513f171516b0fd42a", Rate: 20000000}
+	if c.Commission == nil || *c.Commission != want {
+		t.Errorf("Commission = %+v, want %+v", c.Commission, want)
+	}
+}
+
+func TestDecodeUnxswapTo(t *testing.T) {
+	data := "08298b5a" +
+		word("a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48") +
+		word("f4240") +
+		word("3e8") +
+		word("3f6a3f57569358a512ccc0e513f171516b0fd42a") +
+		word("a0") + word("1") +
+		"80000000000000003b6d0340b4e16d0168e52d35cacd2c6185b44281ec28c9dc"
+
+	c, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Function != UnxswapTo || c.Receiver != "0x3f6a3f57569358a512ccc0e513f171516b0fd42a" || len(c.Pools) != 1 || c.Commission != nil {
+		t.Errorf("Decode = %+v", c)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	if _, err := Decode("0xdeadbeef"); !errors.Is(err, ErrUnknownSelector) {
+		t.Errorf("unknown selector: err = %v", err)
+	}
+	if _, err := Decode("0x095ea7b3" + word("1")); !errors.Is(err, ErrMalformed) {
+		t.Errorf("short approve: err = %v", err)
+	}
+	if _, err := Decode("0x08298b5a" + word("1") + word("1") + word("1") + word("1") + word("ffff")); !errors.Is(err, ErrMalformed) {
+		t.Errorf("bad offset: err = %v", err)
+	}
+}
@@ -21,21 +21,40 @@ package dex
 
 import (
 	"context"
-	"strconv"
+
+	"github.com/imzhongqi/okxos/chains"
 )
 
 type ChainInfo struct {
-	ChainId             int64  `json:"chainId"`
-	ChainName           string `json:"chainName"`
-	DexTokenApproveAddr string `json:"dexTokenApproveAddress"`
+	ChainId             chains.ChainID `json:"chainId"`
+	ChainName           string         `json:"chainName"`
+	DexTokenApproveAddr string         `json:"dexTokenApproveAddress"`
 }
 
 // Get Supported Chains
-func (d *DexAPI) GetSupportedChains(ctx context.Context, chainId ...int64) (result []ChainInfo, err error) {
+func (d *DexAPI) GetSupportedChains(ctx context.Context, chainId ...chains.ChainID) (result []ChainInfo, err error) {
 	params := map[string]string{}
-	if len(chainId) > 0 && chainId[0] != 0 {
-		params["chainId"] = strconv.FormatInt(chainId[0], 10)
+	if len(chainId) > 0 && chainId[0] != "" {
+		params["chainId"] = chainId[0].String()
 	}
 	err = d.tr.Get(ctx, "/api/v5/dex/aggregator/supported/chain", params, &result)
 	return
 }
+
+// ListChains lists the chains supported by the DEX API, it implements chains.Source.
+func (d *DexAPI) ListChains(ctx context.Context) ([]chains.Chain, error) {
+	infos, err := d.GetSupportedChains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]chains.Chain, 0, len(infos))
+	for _, info := range infos {
+		list = append(list, chains.Chain{
+			ID:                     info.ChainId,
+			Name:                   info.ChainName,
+			DexTokenApproveAddress: info.DexTokenApproveAddr,
+			Dex:                    true,
+		})
+	}
+	return list, nil
+}
@@ -19,21 +19,25 @@
 
 package crosschain
 
-import "context"
+import (
+	"context"
+
+	"github.com/imzhongqi/okxos/chains"
+)
 
 type BridgeInfo struct {
-	BridgeName             string   `json:"bridgeName"`
-	BridgeId               int64    `json:"bridgeId"`
-	RequiredOtherNativeFee bool     `json:"requiredOtherNativeFee"`
-	Logo                   string   `json:"logo"`
-	SupportedChains        []string `json:"supportedChains"`
+	BridgeName             string           `json:"bridgeName"`
+	BridgeId               int64            `json:"bridgeId"`
+	RequiredOtherNativeFee bool             `json:"requiredOtherNativeFee"`
+	Logo                   string           `json:"logo"`
+	SupportedChains        []chains.ChainID `json:"supportedChains"`
 }
 
 // GetSupportedBridges Get supported bridges
-func (c *CrossChainAPI) GetSupportedBridges(ctx context.Context, chainId string) (result []BridgeInfo, err error) {
+func (c *CrossChainAPI) GetSupportedBridges(ctx context.Context, chainId chains.ChainID) (result []BridgeInfo, err error) {
 	params := map[string]string{}
 	if chainId != "" {
-		params["chainId"] = chainId
+		params["chainId"] = chainId.String()
 	}
 	err = c.tr.Get(ctx, "/api/v5/dex/cross-chain/supported/bridges", params, &result)
 	return
@@ -21,20 +21,40 @@ package crosschain
 
 import (
 	"context"
+
+	"github.com/imzhongqi/okxos/chains"
 )
 
 type ChainInfo struct {
-	ChainId                string `json:"chainId"`
-	ChainName              string `json:"chainName"`
-	DexTokenApproveAddress string `json:"dexTokenApproveAddress"`
+	ChainId                chains.ChainID `json:"chainId"`
+	ChainName              string         `json:"chainName"`
+	DexTokenApproveAddress string         `json:"dexTokenApproveAddress"`
 }
 
 // GetSupportedChains Get supported chains
-func (c *CrossChainAPI) GetSupportedChains(ctx context.Context, chainId string) (result []ChainInfo, err error) {
+func (c *CrossChainAPI) GetSupportedChains(ctx context.Context, chainId chains.ChainID) (result []ChainInfo, err error) {
 	params := map[string]string{}
 	if chainId != "" {
-		params["chainId"] = chainId
+		params["chainId"] = chainId.String()
 	}
 	err = c.tr.Get(ctx, "/api/v5/dex/cross-chain/supported/chain", params, &result)
 	return
 }
+
+// ListChains lists the chains supported by the cross-chain API, it implements chains.Source.
+func (c *CrossChainAPI) ListChains(ctx context.Context) ([]chains.Chain, error) {
+	infos, err := c.GetSupportedChains(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	list := make([]chains.Chain, 0, len(infos))
+	for _, info := range infos {
+		list = append(list, chains.Chain{
+			ID:                     info.ChainId,
+			Name:                   info.ChainName,
+			DexTokenApproveAddress: info.DexTokenApproveAddress,
+			CrossChain:             true,
+		})
+	}
+	return list, nil
+}
@@ -21,37 +21,57 @@ package crosschain
 
 import (
 	"context"
-	"strconv"
-	"strings"
+	"encoding/json"
 
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 // GetQuoteRequest Get quote request
 // Docs: https://www.okx.com/web3/build/docs/waas/dex-get-route-information#get-route-information
 type GetQuoteRequest struct {
-	FromChainId                     string   `json:"fromChainId"`
-	ToChainId                       string   `json:"toChainId"`
-	FromTokenAddress                string   `json:"fromTokenAddress"`
-	ToTokenAddress                  string   `json:"toTokenAddress"`
-	Amount                          string   `json:"amount"`
-	Slippage                        string   `json:"slippage"`
-	Sort                            int      `json:"sort"`
-	FeePercent                      string   `json:"feePercent"`
-	AllowBridge                     []string `json:"allowBridge"`
-	DenyBridge                      []string `json:"denyBridge"`
-	PriceImpactProtectionPercentage string   `json:"priceImpactProtectionPercentage"`
+	FromChainId                     chains.ChainID `json:"fromChainId"`
+	ToChainId                       chains.ChainID `json:"toChainId"`
+	FromTokenAddress                string         `json:"fromTokenAddress"`
+	ToTokenAddress                  string         `json:"toTokenAddress"`
+	Amount                          string         `json:"amount"`
+	Slippage                        string         `json:"slippage"`
+	Sort                            int            `json:"sort,omitempty"`
+	FeePercent                      string         `json:"feePercent,omitempty"`
+	AllowBridge                     []string       `json:"allowBridge,omitempty"`
+	DenyBridge                      []string       `json:"denyBridge,omitempty"`
+	PriceImpactProtectionPercentage string         `json:"priceImpactProtectionPercentage,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetQuoteRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("fromChainId", r.FromChainId.String())
+	v.Required("toChainId", r.ToChainId.String())
+	v.Required("fromTokenAddress", r.FromTokenAddress)
+	v.Required("toTokenAddress", r.ToTokenAddress)
+	v.Amount("amount", r.Amount)
+	v.Required("slippage", r.Slippage)
+	v.Range("slippage", r.Slippage, 0.002, 0.5)
+	if r.Sort < 0 || r.Sort > 2 {
+		v.Add("sort", "must be one of 0, 1, 2")
+	}
+	v.Range("feePercent", r.FeePercent, 0, 3)
+	v.Range("priceImpactProtectionPercentage", r.PriceImpactProtectionPercentage, 0, 1)
+	return v.Err()
 }
 
 type QuoteTokenInfo struct {
-	Decimals             int64  `json:"decimals"`
-	TokenContractAddress string `json:"tokenContractAddress"`
-	TokenSymbol          string `json:"tokenSymbol"`
+	Decimals             types.Int `json:"decimals"`
+	TokenContractAddress string    `json:"tokenContractAddress"`
+	TokenSymbol          string    `json:"tokenSymbol"`
 }
 
 type DexProtocol struct {
-	Percent string `json:"percent"`
-	DexName string `json:"dexName"`
+	Percent types.Decimal `json:"percent"`
+	DexName string        `json:"dexName"`
 }
 
 type SubRouter struct {
@@ -67,22 +87,22 @@ type DexRouter struct {
 }
 
 type BridgeRouter struct {
-	BridgeId                  int    `json:"bridgeId"`
-	BridgeName                string `json:"bridgeName"`
-	CrossChainFee             string `json:"crossChainFee"`
-	CrossChainFeeTokenAddress string `json:"crossChainFeeTokenAddress"`
-	OtherNativeFee            string `json:"otherNativeFee"`
-	EstimateGasFee            string `json:"estimateGasFee"`
-	EstimatedTime             string `json:"estimatedTime"`
+	BridgeId                  int           `json:"bridgeId"`
+	BridgeName                string        `json:"bridgeName"`
+	CrossChainFee             types.Decimal `json:"crossChainFee"`
+	CrossChainFeeTokenAddress string        `json:"crossChainFeeTokenAddress"`
+	OtherNativeFee            types.Decimal `json:"otherNativeFee"`
+	EstimateGasFee            types.Uint256 `json:"estimateGasFee"`
+	EstimatedTime             types.Int     `json:"estimatedTime"`
 }
 
 type Router struct {
-	EstimateTime        string        `json:"estimateTime"`
-	EstimateGasFee      string        `json:"estimateGasFee"`
-	FromChainNetworkFee string        `json:"fromChainNetworkFee"`
-	ToChainNetworkFee   string        `json:"toChainNetworkFee"`
-	ToTokenAmount       string        `json:"toTokenAmount"`
-	MinimumReceived     string        `json:"minimumReceived"`
+	EstimateTime        types.Int     `json:"estimateTime"`
+	EstimateGasFee      types.Uint256 `json:"estimateGasFee"`
+	FromChainNetworkFee types.Decimal `json:"fromChainNetworkFee"`
+	ToChainNetworkFee   types.Decimal `json:"toChainNetworkFee"`
+	ToTokenAmount       types.Uint256 `json:"toTokenAmount"`
+	MinimumReceived     types.Uint256 `json:"minimumReceived"`
 	NeedApprove         int           `json:"needApprove"`
 	Router              *BridgeRouter `json:"router"`
 	FromDexRouterList   []DexRouter   `json:"fromDexRouterList"`
@@ -90,38 +110,31 @@ type Router struct {
 }
 
 type QuoteResult struct {
-	FromChainId     string         `json:"fromChainId"`
-	ToChainId       string         `json:"toChainId"`
-	FromTokenAmount string         `json:"fromTokenAmount"`
+	FromChainId     chains.ChainID `json:"fromChainId"`
+	ToChainId       chains.ChainID `json:"toChainId"`
+	FromTokenAmount types.Uint256  `json:"fromTokenAmount"`
 	FromToken       QuoteTokenInfo `json:"fromToken"`
 	ToToken         QuoteTokenInfo `json:"toToken"`
 	RouterList      []Router       `json:"routerList"`
+
+	// Extra holds the response fields that are not declared above yet.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *QuoteResult) UnmarshalJSON(data []byte) (err error) {
+	type alias QuoteResult
+	r.Extra, err = client.DecodeExtra(data, (*alias)(r))
+	return
 }
 
 // GetQuote Get quote
 func (c *CrossChainAPI) GetQuote(ctx context.Context, quote *GetQuoteRequest) (result *QuoteResult, err error) {
-	params := map[string]string{
-		"fromChainId":      quote.FromChainId,
-		"toChainId":        quote.ToChainId,
-		"fromTokenAddress": quote.FromTokenAddress,
-		"toTokenAddress":   quote.ToTokenAddress,
-		"amount":           quote.Amount,
-		"slippage":         quote.Slippage,
-	}
-	if quote.Sort != 0 {
-		params["sort"] = strconv.Itoa(quote.Sort)
-	}
-	if quote.FeePercent != "" {
-		params["feePercent"] = quote.FeePercent
-	}
-	if len(quote.AllowBridge) > 0 {
-		params["allowBridge"] = strings.Join(quote.AllowBridge, ",")
-	}
-	if len(quote.DenyBridge) > 0 {
-		params["denyBridge"] = strings.Join(quote.DenyBridge, ",")
+	if err = quote.Validate(); err != nil {
+		return nil, err
 	}
-	if quote.PriceImpactProtectionPercentage != "" {
-		params["priceImpactProtectionPercentage"] = quote.PriceImpactProtectionPercentage
+	params, err := client.EncodeQuery(quote)
+	if err != nil {
+		return nil, err
 	}
 
 	var results []*QuoteResult
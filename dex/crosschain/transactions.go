@@ -21,59 +21,80 @@ package crosschain
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type CrossChainFee struct {
-	Symbol  string `json:"symbol"`
-	Address string `json:"address"`
-	Amount  string `json:"amount"`
+	Symbol  string        `json:"symbol"`
+	Address string        `json:"address"`
+	Amount  types.Decimal `json:"amount"`
 }
 
 type CrossChainInfo struct {
-	Memo                   string `json:"memo"`
-	DestinationChainGasfee string `json:"destinationChainGasfee"`
-	DetailStatus           string `json:"detailStatus"`
-	Status                 string `json:"status"`
+	Memo                   string        `json:"memo"`
+	DestinationChainGasfee types.Decimal `json:"destinationChainGasfee"`
+	DetailStatus           string        `json:"detailStatus"`
+	Status                 string        `json:"status"`
 }
 
 type TransactionStatus struct {
-	FromChainId        string         `json:"fromChainId"`
-	ToChainId          string         `json:"toChainId"`
+	FromChainId        chains.ChainID `json:"fromChainId"`
+	ToChainId          chains.ChainID `json:"toChainId"`
 	FromTxHash         string         `json:"fromTxHash"`
 	ToTxHash           string         `json:"toTxHash"`
-	FromAmount         string         `json:"fromAmount"`
+	FromAmount         types.Decimal  `json:"fromAmount"`
 	FromTokenAddress   string         `json:"fromTokenAddress"`
-	ToAmount           string         `json:"toAmount"`
+	ToAmount           types.Decimal  `json:"toAmount"`
 	ToTokenAddress     string         `json:"toTokenAddress"`
 	ErrorMsg           string         `json:"errorMsg"`
 	BridgeHash         string         `json:"bridgeHash"`
-	RefundChainId      string         `json:"refundChainId"`
+	RefundChainId      chains.ChainID `json:"refundChainId"`
 	RefundTokenAddress string         `json:"refundTokenAddress"`
 	RefundTxHash       string         `json:"refundTxHash"`
-	SourceChainGasfee  string         `json:"sourceChainGasfee"`
+	SourceChainGasfee  types.Decimal  `json:"sourceChainGasfee"`
 	CrossChainFee      CrossChainFee  `json:"crossChainFee"`
 	Symbol             string         `json:"symbol"`
 	Address            string         `json:"address"`
-	Amount             string         `json:"amount"`
+	Amount             types.Decimal  `json:"amount"`
 	CrossChainInfo     CrossChainInfo `json:"crossChainInfo"`
+
+	// Extra holds the response fields that are not declared above yet.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *TransactionStatus) UnmarshalJSON(data []byte) (err error) {
+	type alias TransactionStatus
+	r.Extra, err = client.DecodeExtra(data, (*alias)(r))
+	return
 }
 
 type GetTransactionStatusRequest struct {
 	// Hash address of the source chain
 	Hash string `json:"hash"`
 	// ChainId Source chain ID (e.g., 1 for Ethereum. See Chain IDs)
-	ChainId string `json:"chainId"`
+	ChainId chains.ChainID `json:"chainId,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTransactionStatusRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("hash", r.Hash)
+	return v.Err()
 }
 
 // GetTransactionStatus Check the final status of the cross-chain swap according to transaction hash.
 func (c *CrossChainAPI) GetTransactionStatus(ctx context.Context, req *GetTransactionStatusRequest) (result *TransactionStatus, err error) {
-	params := map[string]string{
-		"hash": req.Hash,
+	if err = req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.ChainId != "" {
-		params["chainId"] = req.ChainId
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 	var results []*TransactionStatus
 	if err = c.tr.Get(ctx, "/api/v5/dex/cross-chain/status", params, &results); err != nil {
@@ -29,11 +29,38 @@ type DexAPI struct {
 	CrossChain *crosschain.CrossChainAPI
 	LimitOrder *limitorder.LimitOrderAPI
 	tr         client.Transport
+	guard      Guard
 }
 
-func NewDexAPI(tr client.Transport) *DexAPI {
+type Options struct {
+	guard Guard
+}
+
+type Option interface {
+	apply(o *Options)
+}
+
+type optionFunc func(o *Options)
+
+func (f optionFunc) apply(o *Options) {
+	f(o)
+}
+
+// WithGuard vets the tokens of quotes and swaps with guard.
+func WithGuard(guard Guard) Option {
+	return optionFunc(func(o *Options) {
+		o.guard = guard
+	})
+}
+
+func NewDexAPI(tr client.Transport, opts ...Option) *DexAPI {
+	var o Options
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
 	return &DexAPI{
 		tr:         tr,
+		guard:      o.guard,
 		CrossChain: crosschain.NewCrossChainAPI(tr),
 		LimitOrder: limitorder.NewLimitOrderAPI(tr),
 	}
@@ -0,0 +1,203 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/imzhongqi/okxos/types"
+)
+
+var ErrExactOutputNotConverged = errors.New("dex: exact output quote did not converge")
+
+// ExactOutputRequest asks how much of a token must be sold to receive exactly AmountOut of another.
+type ExactOutputRequest struct {
+	// Quote holds the swap. Its Amount is required and is the first guess of the input amount,
+	// in minimal divisible units of the input token, e.g. AmountOut converted at the market price.
+	Quote GetQuotesRequest
+	// AmountOut is the output amount to receive, in minimal divisible units.
+	AmountOut string
+	// Tolerance is how much more than AmountOut a quote may return and still be accepted,
+	// as a fraction of AmountOut. Defaults to 0.001.
+	Tolerance string
+	// SafetyMargin is added to the input amount, as a fraction of it, to absorb price moves
+	// between the quote and the swap. Defaults to 0.005.
+	SafetyMargin string
+	// MaxQuotes bounds the number of quotes requested. Defaults to 12.
+	MaxQuotes int
+}
+
+// ExactOutputResult is the input amount found by QuoteExactOutput.
+type ExactOutputResult struct {
+	// AmountIn is the smallest input amount found whose quote returns at least AmountOut.
+	AmountIn types.Uint256
+	// AmountInWithMargin is AmountIn plus the safety margin, the amount to swap.
+	AmountInWithMargin types.Uint256
+	// Quote is the quote of AmountIn, with the route.
+	Quote *QuotesResult
+	// Quotes is the number of quotes requested.
+	Quotes int
+}
+
+// SwapTxRequest returns a swap request selling AmountInWithMargin along the quoted swap.
+func (r *ExactOutputResult) SwapTxRequest(req *ExactOutputRequest, userWalletAddress, slippage string) *GetSwapTxRequest {
+	return &GetSwapTxRequest{
+		ChainId:                         req.Quote.ChainId,
+		Amount:                          r.AmountInWithMargin.String(),
+		FromTokenAddress:                req.Quote.FromTokenAddress,
+		ToTokenAddress:                  req.Quote.ToTokenAddress,
+		Slippage:                        slippage,
+		UserWalletAddress:               userWalletAddress,
+		FeePercent:                      req.Quote.FeePercent,
+		DexIds:                          req.Quote.DexIds,
+		PriceImpactProtectionPercentage: req.Quote.PriceImpactProtectionPercentage,
+	}
+}
+
+// QuoteExactOutput searches for the input amount that quotes AmountOut, since the aggregator only
+// quotes exact inputs. The output grows with the input, so the search scales the input by the
+// ratio of the wanted and quoted outputs and falls back to bisection between the closest input
+// amounts seen. Each step is a GetQuotes call, which waits for the client rate limiter.
+func (d *DexAPI) QuoteExactOutput(ctx context.Context, req *ExactOutputRequest) (*ExactOutputResult, error) {
+	target, ok := new(big.Int).SetString(req.AmountOut, 10)
+	if !ok || target.Sign() <= 0 {
+		return nil, fmt.Errorf("dex: invalid amountOut %q", req.AmountOut)
+	}
+	tolerance, err := parseFraction(req.Tolerance, "0.001")
+	if err != nil {
+		return nil, fmt.Errorf("dex: invalid tolerance: %w", err)
+	}
+	margin, err := parseFraction(req.SafetyMargin, "0.005")
+	if err != nil {
+		return nil, fmt.Errorf("dex: invalid safety margin: %w", err)
+	}
+	maxQuotes := req.MaxQuotes
+	if maxQuotes <= 0 {
+		maxQuotes = 12
+	}
+
+	// the output amount is in units of another token, so it cannot stand in for the first guess.
+	guess, ok := new(big.Int).SetString(req.Quote.Amount, 10)
+	if !ok || guess.Sign() <= 0 {
+		return nil, fmt.Errorf("dex: invalid first guess amount %q", req.Quote.Amount)
+	}
+
+	// an output within [target, limit] is accepted.
+	limit := mulFraction(target, new(big.Rat).Add(big.NewRat(1, 1), tolerance))
+
+	// lo quotes below the target and hi quotes at least the target.
+	var lo, hi *big.Int
+	var hiQuote *QuotesResult
+	quotes := 0
+	for quotes < maxQuotes {
+		q := req.Quote
+		q.Amount = guess.String()
+		result, err := d.GetQuotes(ctx, &q)
+		quotes++
+		if err != nil {
+			return nil, err
+		}
+		out := result.ToTokenAmount.Big()
+		if out == nil || out.Sign() <= 0 {
+			return nil, errors.New("dex: quote returned no output")
+		}
+
+		if out.Cmp(target) >= 0 {
+			if hi == nil || guess.Cmp(hi) < 0 {
+				hi, hiQuote = new(big.Int).Set(guess), result
+			}
+			if out.Cmp(limit) <= 0 {
+				break
+			}
+		} else if lo == nil || guess.Cmp(lo) > 0 {
+			lo = new(big.Int).Set(guess)
+		}
+		// hi is the smallest input reaching the target once no input lies between lo and hi.
+		if lo != nil && hi != nil && new(big.Int).Sub(hi, lo).Cmp(big.NewInt(1)) <= 0 {
+			break
+		}
+
+		next := nextGuess(guess, out, target, lo, hi)
+		if next.Cmp(guess) == 0 {
+			break
+		}
+		guess = next
+	}
+
+	if hi == nil {
+		return nil, fmt.Errorf("%w after %d quotes", ErrExactOutputNotConverged, quotes)
+	}
+	return &ExactOutputResult{
+		AmountIn:           types.NewUint256(hi),
+		AmountInWithMargin: types.NewUint256(mulFraction(hi, new(big.Rat).Add(big.NewRat(1, 1), margin))),
+		Quote:              hiQuote,
+		Quotes:             quotes,
+	}, nil
+}
+
+// nextGuess scales guess by target/out, aiming slightly above the target, and bisects between
+// lo and hi when the scaled guess leaves the bracket. With only one side of the bracket known,
+// it steps past that side instead.
+func nextGuess(guess, out, target, lo, hi *big.Int) *big.Int {
+	// aim for half of a basis point above the target, so the guess tends to land inside the tolerance.
+	aim := mulFraction(target, big.NewRat(200001, 200000))
+	next := new(big.Int).Mul(guess, aim)
+	next.Quo(next, out)
+	if next.Sign() <= 0 {
+		next.SetInt64(1)
+	}
+
+	switch {
+	case (lo == nil || next.Cmp(lo) > 0) && (hi == nil || next.Cmp(hi) < 0):
+	case lo != nil && hi != nil:
+		next.Add(lo, hi)
+		next.Rsh(next, 1)
+	case lo != nil:
+		// rounding kept the scaled guess at or below an input known to fall short.
+		next.Add(lo, big.NewInt(1))
+	case hi.Cmp(big.NewInt(1)) > 0:
+		next.Sub(hi, big.NewInt(1))
+	}
+	return next
+}
+
+func parseFraction(s, def string) (*big.Rat, error) {
+	if s == "" {
+		s = def
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not a non-negative number", s)
+	}
+	return r, nil
+}
+
+// mulFraction returns n*f rounded up.
+func mulFraction(n *big.Int, f *big.Rat) *big.Int {
+	num := new(big.Int).Mul(n, f.Num())
+	q, m := new(big.Int).QuoRem(num, f.Denom(), new(big.Int))
+	if m.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
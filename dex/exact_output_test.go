@@ -0,0 +1,100 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/internal/transporttest"
+)
+
+// quoteCurve serves quotes whose output is curve(amount).
+func quoteCurve(curve func(amount *big.Int) *big.Int) *transporttest.Transport {
+	tr := transporttest.New(nil)
+	tr.Handle("/api/v5/dex/aggregator/quote", func(req *transporttest.Request) (string, error) {
+		amount, ok := new(big.Int).SetString(req.Params["amount"], 10)
+		if !ok {
+			return "", errors.New("bad amount")
+		}
+		return fmt.Sprintf(`[{"fromTokenAmount":"%s","toTokenAmount":"%s"}]`, amount, curve(amount)), nil
+	})
+	return tr
+}
+
+func TestQuoteExactOutput(t *testing.T) {
+	// sells USDC (6 decimals) for ETH (18 decimals) at 2000 USDC per ETH, with a price impact
+	// of 1% per 10000 USDC.
+	tr := quoteCurve(func(amount *big.Int) *big.Int {
+		out := new(big.Int).Mul(amount, big.NewInt(500_000_000))
+		impact := new(big.Int).Mul(out, amount)
+		impact.Quo(impact, big.NewInt(1_000_000_000_000))
+		return out.Sub(out, impact)
+	})
+	d := NewDexAPI(tr)
+	req := &ExactOutputRequest{
+		Quote:     GetQuotesRequest{ChainId: chains.Ethereum, Amount: "1000000", FromTokenAddress: testUSDC, ToTokenAddress: testWETH},
+		AmountOut: "500000000000000000", // 0.5 ETH
+	}
+
+	result, err := d.QuoteExactOutput(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := result.Quote.ToTokenAmount.Big()
+	limit := big.NewInt(500_500_000_000_000_000)
+	if out.Cmp(big.NewInt(500_000_000_000_000_000)) < 0 || out.Cmp(limit) > 0 {
+		t.Errorf("quoted output %s outside the tolerance", out)
+	}
+	if result.AmountIn.Big().Cmp(big.NewInt(1_000_000_000)) <= 0 || result.Quotes > 6 {
+		t.Errorf("AmountIn = %s after %d quotes", result.AmountIn, result.Quotes)
+	}
+	if result.AmountInWithMargin.Big().Cmp(result.AmountIn.Big()) <= 0 {
+		t.Errorf("AmountInWithMargin = %s", result.AmountInWithMargin)
+	}
+
+	req.Quote.Amount = ""
+	if _, err := d.QuoteExactOutput(context.Background(), req); err == nil {
+		t.Error("expected an error without a first guess")
+	}
+}
+
+func TestQuoteExactOutputAdjacent(t *testing.T) {
+	// no input returns exactly 10 within the tolerance, so the search stops once 3 and 4 bracket it.
+	tr := quoteCurve(func(amount *big.Int) *big.Int {
+		return new(big.Int).Mul(amount, big.NewInt(3))
+	})
+	result, err := NewDexAPI(tr).QuoteExactOutput(context.Background(), &ExactOutputRequest{
+		Quote:     GetQuotesRequest{ChainId: chains.Ethereum, Amount: "1", FromTokenAddress: testUSDC, ToTokenAddress: testWETH},
+		AmountOut: "10",
+		Tolerance: "0",
+		MaxQuotes: 50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AmountIn.String() != "4" || result.Quotes > 5 {
+		t.Errorf("AmountIn = %s after %d quotes", result.AmountIn, result.Quotes)
+	}
+}
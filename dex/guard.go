@@ -0,0 +1,52 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+
+	"github.com/imzhongqi/okxos/chains"
+)
+
+// Guard vets the tokens of swaps, refusing risky ones with an error. It is set with WithGuard.
+//
+// GetQuotes calls it with the token info of the quote. GetSwapTx calls it with nil info before the
+// calldata is requested, and again with the token info of the route before returning it.
+// GetSolSwapInstruction only makes the first call, as its response carries no token info: a guard
+// that relies on token info needs the tokens quoted with GetQuotes first.
+type Guard interface {
+	CheckToken(ctx context.Context, chainId chains.ChainID, tokenAddress string, info *TokenInfo) error
+}
+
+func (d *DexAPI) checkToken(ctx context.Context, chainId chains.ChainID, tokenAddress string, info *TokenInfo) error {
+	if d.guard == nil {
+		return nil
+	}
+	return d.guard.CheckToken(ctx, chainId, tokenAddress, info)
+}
+
+func (d *DexAPI) checkTokens(ctx context.Context, chainId chains.ChainID, tokenAddresses ...string) error {
+	for _, token := range tokenAddresses {
+		if err := d.checkToken(ctx, chainId, token, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
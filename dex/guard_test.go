@@ -0,0 +1,71 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/internal/transporttest"
+)
+
+var errHoneyPot = errors.New("honeypot")
+
+type honeyPotGuard struct {
+	checks []string
+}
+
+func (g *honeyPotGuard) CheckToken(ctx context.Context, chainId chains.ChainID, tokenAddress string, info *TokenInfo) error {
+	g.checks = append(g.checks, tokenAddress)
+	if info != nil && info.IsHoneyPot {
+		return errHoneyPot
+	}
+	return nil
+}
+
+func TestGuardSwapTx(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/dex/aggregator/swap": `[{"routerResult":{"fromToken":{"isHoneyPot":false},"toToken":{"isHoneyPot":true}},"tx":{"data":"0x"}}]`,
+	})
+	guard := &honeyPotGuard{}
+	req := &GetSwapTxRequest{
+		ChainId:           chains.Ethereum,
+		Amount:            "1000000",
+		FromTokenAddress:  testUSDC,
+		ToTokenAddress:    testWETH,
+		Slippage:          "0.01",
+		UserWalletAddress: "0x3f6a3f57569358a512ccc0e513f171516b0fd42a",
+	}
+
+	// the honeypot is only flagged by the route of the response.
+	if _, err := NewDexAPI(tr, WithGuard(guard)).GetSwapTx(context.Background(), req); !errors.Is(err, errHoneyPot) {
+		t.Fatalf("GetSwapTx = %v", err)
+	}
+	if got := strings.Join(guard.checks, ","); got != strings.Join([]string{testUSDC, testWETH, testUSDC, testWETH}, ",") {
+		t.Errorf("checks = %s", got)
+	}
+
+	if _, err := NewDexAPI(tr).GetSwapTx(context.Background(), req); err != nil {
+		t.Fatalf("GetSwapTx without guard = %v", err)
+	}
+}
@@ -22,8 +22,10 @@ package limitorder
 import (
 	"context"
 
+	"github.com/imzhongqi/okxos/chains"
 	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 // LimitOrderAPI provides API operations for OKX Web3 limit orders
@@ -55,35 +57,55 @@ type OrderData struct {
 
 // CreateOrderRequest represents the request parameters for creating a limit order
 type CreateOrderRequest struct {
-	OrderHash string    `json:"orderHash"`
-	ChainId   string    `json:"chainId"`
-	Signature string    `json:"signature"`
-	Data      OrderData `json:"data"`
+	OrderHash string         `json:"orderHash"`
+	ChainId   chains.ChainID `json:"chainId"`
+	Signature string         `json:"signature"`
+	Data      OrderData      `json:"data"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *CreateOrderRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("orderHash", r.OrderHash)
+	v.Required("chainId", r.ChainId.String())
+	v.Required("signature", r.Signature)
+	v.Required("data.salt", r.Data.Salt)
+	v.Required("data.makerToken", r.Data.MakerToken)
+	v.Required("data.takerToken", r.Data.TakerToken)
+	v.Required("data.maker", r.Data.Maker)
+	v.Amount("data.makingAmount", r.Data.MakingAmount)
+	v.Amount("data.takingAmount", r.Data.TakingAmount)
+	v.Required("data.deadLine", r.Data.DeadLine)
+	return v.Err()
 }
 
 // OrderDetail represents the response data for a limit order
 type OrderDetail struct {
-	ChainId              string `json:"chainId"`
-	CreateTime           string `json:"createTime"`
-	ExpireTime           string `json:"expireTime"`
-	MakerAssetAddress    string `json:"makerAssetAddress"`
-	MakerRate            string `json:"makerRate"`
-	MakerTokenAddress    string `json:"makerTokenAddress"`
-	MakingAmount         string `json:"makingAmount"`
-	OrderHash            string `json:"orderHash"`
-	Receiver             string `json:"receiver"`
-	RemainingMakerAmount string `json:"remainingMakerAmount"`
-	Salt                 string `json:"salt"`
-	Signature            string `json:"signature"`
-	Status               string `json:"status"`
-	TakerAssetAddress    string `json:"takerAssetAddress"`
-	TakerRate            string `json:"takerRate"`
-	TakerTokenAddress    string `json:"takerTokenAddress"`
-	TakingAmount         string `json:"takingAmount"`
+	ChainId              chains.ChainID `json:"chainId"`
+	CreateTime           string         `json:"createTime"`
+	ExpireTime           string         `json:"expireTime"`
+	MakerAssetAddress    string         `json:"makerAssetAddress"`
+	MakerRate            string         `json:"makerRate"`
+	MakerTokenAddress    string         `json:"makerTokenAddress"`
+	MakingAmount         types.Uint256  `json:"makingAmount"`
+	OrderHash            string         `json:"orderHash"`
+	Receiver             string         `json:"receiver"`
+	RemainingMakerAmount types.Uint256  `json:"remainingMakerAmount"`
+	Salt                 string         `json:"salt"`
+	Signature            string         `json:"signature"`
+	Status               string         `json:"status"`
+	TakerAssetAddress    string         `json:"takerAssetAddress"`
+	TakerRate            string         `json:"takerRate"`
+	TakerTokenAddress    string         `json:"takerTokenAddress"`
+	TakingAmount         types.Uint256  `json:"takingAmount"`
 }
 
 // CreateOrder creates a limit order
 func (api *LimitOrderAPI) CreateOrder(ctx context.Context, req CreateOrderRequest) (*OrderDetail, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	var result []OrderDetail
 	err := api.tr.Post(ctx, "/dex/aggregator/limit-order/save-order", req, &result)
 	if err != nil {
@@ -99,42 +121,34 @@ func (api *LimitOrderAPI) CreateOrder(ctx context.Context, req CreateOrderReques
 
 // ListOrdersRequest represents the request parameters for listing limit orders
 type ListOrdersRequest struct {
-	ChainId    string `json:"chainId"`
-	Page       string `json:"page,omitempty"`
-	Limit      string `json:"limit,omitempty"`
-	Statuses   string `json:"statuses,omitempty"`
-	TakerAsset string `json:"takerAsset,omitempty"`
-	MakerAsset string `json:"makerAsset,omitempty"`
+	ChainId    chains.ChainID `json:"chainId"`
+	Page       string         `json:"page,omitempty"`
+	Limit      string         `json:"limit,omitempty"`
+	Statuses   string         `json:"statuses,omitempty"`
+	TakerAsset string         `json:"takerAsset,omitempty"`
+	MakerAsset string         `json:"makerAsset,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *ListOrdersRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainId", r.ChainId.String())
+	return v.Err()
 }
 
 // ListOrders lists limit orders based on the provided parameters
 func (api *LimitOrderAPI) ListOrders(ctx context.Context, req ListOrdersRequest) ([]*OrderDetail, error) {
-	params := map[string]string{
-		"chainId": req.ChainId,
-	}
-
-	if req.Page != "" {
-		params["page"] = req.Page
-	}
-
-	if req.Limit != "" {
-		params["limit"] = req.Limit
-	}
-
-	if req.Statuses != "" {
-		params["statuses"] = req.Statuses
-	}
-
-	if req.TakerAsset != "" {
-		params["takerAsset"] = req.TakerAsset
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
-	if req.MakerAsset != "" {
-		params["makerAsset"] = req.MakerAsset
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 
 	var result []*OrderDetail
-	err := api.tr.Get(ctx, "/dex/aggregator/limit-order/all", params, &result)
+	err = api.tr.Get(ctx, "/dex/aggregator/limit-order/all", params, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -148,14 +162,14 @@ func (api *LimitOrderAPI) ListOrders(ctx context.Context, req ListOrdersRequest)
 
 // GetOrderRequest represents the request parameters for getting a limit order
 type GetOrderRequest struct {
-	ChainId   string `json:"chainId"`
-	OrderHash string `json:"orderHash"`
+	ChainId   chains.ChainID `json:"chainId"`
+	OrderHash string         `json:"orderHash"`
 }
 
 // GetOrder gets the details of a specific limit order
-func (api *LimitOrderAPI) GetOrder(ctx context.Context, chainId, orderHash string) (*OrderDetail, error) {
+func (api *LimitOrderAPI) GetOrder(ctx context.Context, chainId chains.ChainID, orderHash string) (*OrderDetail, error) {
 	params := map[string]string{
-		"chainId":   chainId,
+		"chainId":   chainId.String(),
 		"orderHash": orderHash,
 	}
 
@@ -178,7 +192,7 @@ type CancelOrderRequest struct {
 }
 
 // CancelOrder gets the calldata for canceling a limit order
-func (api *LimitOrderAPI) CancelOrder(ctx context.Context, chainId, orderHash string) (calldata string, err error) {
+func (api *LimitOrderAPI) CancelOrder(ctx context.Context, chainId chains.ChainID, orderHash string) (calldata string, err error) {
 	params := map[string]string{
 		"orderHash": orderHash,
 	}
@@ -21,7 +21,8 @@ package dex
 
 import (
 	"context"
-	"strconv"
+
+	"github.com/imzhongqi/okxos/chains"
 )
 
 type Liquidity struct {
@@ -31,9 +32,9 @@ type Liquidity struct {
 }
 
 // Get Liquidity
-func (d *DexAPI) GetLiquidity(ctx context.Context, chainId int64) (result []Liquidity, err error) {
+func (d *DexAPI) GetLiquidity(ctx context.Context, chainId chains.ChainID) (result []Liquidity, err error) {
 	params := map[string]string{
-		"chainId": strconv.FormatInt(chainId, 10),
+		"chainId": chainId.String(),
 	}
 	err = d.tr.Get(ctx, "/api/v5/dex/aggregator/get-liquidity", params, &result)
 	return
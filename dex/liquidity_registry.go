@@ -0,0 +1,198 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/imzhongqi/okxos/chains"
+)
+
+var ErrLiquidityNotFound = errors.New("no liquidity source matches")
+
+// LiquidityFilter selects the liquidity sources of a chain by name.
+//
+// Names are matched case-insensitively, ignoring spaces, dashes, underscores and dots, so
+// "uniswap-v3" matches "Uniswap V3". A name that contains *, ? or [ is a pattern in the syntax
+// of path.Match, matched case-insensitively against the source name as listed, so "uniswap v*"
+// matches "Uniswap V2" and "Uniswap V3". A plain Allow name that matches no source exactly
+// matches every source it prefixes, so "Curve" allows "Curve V1" and "Curve V2"; Deny names
+// must match exactly, so denying "Uni" does not remove "Uniswap V3".
+type LiquidityFilter struct {
+	// Allow keeps only the sources matching one of the names. Empty keeps every source.
+	Allow []string
+	// Deny removes the sources matching one of the names, after Allow is applied.
+	Deny []string
+}
+
+// LiquidityRegistry resolves liquidity source names to the ids taken by the DexIds field of
+// GetQuotesRequest and GetSwapTxRequest. The sources of a chain are fetched once, by a single
+// request shared by concurrent lookups, that does not block the lookups of other chains.
+type LiquidityRegistry struct {
+	dex        *DexAPI
+	onUnlisted func(chainId chains.ChainID, name string)
+
+	mu    sync.Mutex
+	calls map[chains.ChainID]*liquidityCall
+}
+
+type liquidityCall struct {
+	done chan struct{}
+	list []Liquidity
+	err  error
+}
+
+// NewLiquidityRegistry creates a LiquidityRegistry. onUnlisted, if not nil, is called with every
+// name that no longer matches a liquidity source of the chain, so stale configuration can be logged.
+func NewLiquidityRegistry(d *DexAPI, onUnlisted func(chainId chains.ChainID, name string)) *LiquidityRegistry {
+	return &LiquidityRegistry{
+		dex:        d,
+		onUnlisted: onUnlisted,
+		calls:      make(map[chains.ChainID]*liquidityCall),
+	}
+}
+
+// Sources returns the liquidity sources of chainId. Failed fetches are not cached.
+func (r *LiquidityRegistry) Sources(ctx context.Context, chainId chains.ChainID) ([]Liquidity, error) {
+	r.mu.Lock()
+	c, ok := r.calls[chainId]
+	if !ok {
+		c = &liquidityCall{done: make(chan struct{})}
+		r.calls[chainId] = c
+		r.mu.Unlock()
+
+		c.list, c.err = r.dex.GetLiquidity(ctx, chainId)
+		if c.err != nil {
+			r.mu.Lock()
+			if r.calls[chainId] == c {
+				delete(r.calls, chainId)
+			}
+			r.mu.Unlock()
+		}
+		close(c.done)
+		return c.list, c.err
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.list, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Refresh drops the cached liquidity sources of chainId, so they are fetched again on the next lookup.
+func (r *LiquidityRegistry) Refresh(chainId chains.ChainID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.calls, chainId)
+}
+
+// Resolve returns the ids of the liquidity sources of chainId matching names.
+// Names that match nothing are reported to the onUnlisted hook and skipped, but
+// ErrLiquidityNotFound is returned if none of them matches, because an empty
+// DexIds would quote against every source instead.
+func (r *LiquidityRegistry) Resolve(ctx context.Context, chainId chains.ChainID, names ...string) ([]string, error) {
+	return r.Select(ctx, chainId, LiquidityFilter{Allow: names})
+}
+
+// Select returns the ids of the liquidity sources of chainId kept by filter.
+func (r *LiquidityRegistry) Select(ctx context.Context, chainId chains.ChainID, filter LiquidityFilter) ([]string, error) {
+	list, err := r.Sources(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make([]bool, len(list))
+	if len(filter.Allow) == 0 {
+		for i := range keep {
+			keep[i] = true
+		}
+	}
+	for _, name := range filter.Allow {
+		for _, i := range r.match(chainId, list, name, true) {
+			keep[i] = true
+		}
+	}
+	for _, name := range filter.Deny {
+		for _, i := range r.match(chainId, list, name, false) {
+			keep[i] = false
+		}
+	}
+
+	var ids []string
+	for i, ok := range keep {
+		if ok {
+			ids = append(ids, list[i].Id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, ErrLiquidityNotFound
+	}
+	return ids, nil
+}
+
+// match returns the indexes of the sources in list matching name. A plain name falls back to
+// the sources it prefixes if prefix is set.
+func (r *LiquidityRegistry) match(chainId chains.ChainID, list []Liquidity, name string, prefix bool) []int {
+	pattern := strings.ContainsAny(name, "*?[")
+	want := normalizeLiquidityName(name)
+	if pattern {
+		want = strings.ToLower(name)
+	}
+
+	var exact, prefixed []int
+	for i, l := range list {
+		if pattern {
+			if ok, _ := path.Match(want, strings.ToLower(l.Name)); ok {
+				exact = append(exact, i)
+			}
+			continue
+		}
+		got := normalizeLiquidityName(l.Name)
+		if got == want {
+			exact = append(exact, i)
+		} else if prefix && strings.HasPrefix(got, want) {
+			prefixed = append(prefixed, i)
+		}
+	}
+	if len(exact) == 0 {
+		exact = prefixed
+	}
+	if len(exact) == 0 && r.onUnlisted != nil {
+		r.onUnlisted(chainId, name)
+	}
+	return exact
+}
+
+func normalizeLiquidityName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
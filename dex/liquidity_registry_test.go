@@ -0,0 +1,121 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/internal/transporttest"
+)
+
+func TestLiquidityRegistry(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/dex/aggregator/get-liquidity": `[
+			{"id":"1","name":"Uniswap V2"},
+			{"id":"2","name":"Uniswap V3"},
+			{"id":"3","name":"Curve V1"},
+			{"id":"4","name":"Curve V2"},
+			{"id":"5","name":"Uni-Fi"},
+			{"id":"6","name":"SushiSwap"}
+		]`,
+	})
+	var unlisted []string
+	r := NewLiquidityRegistry(NewDexAPI(tr), func(chainId chains.ChainID, name string) {
+		unlisted = append(unlisted, name)
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		filter LiquidityFilter
+		want   []string
+	}{
+		{LiquidityFilter{Allow: []string{"uniswap-v3"}}, []string{"2"}},
+		{LiquidityFilter{Allow: []string{"Curve"}}, []string{"3", "4"}},
+		{LiquidityFilter{Allow: []string{"uniswap v*"}}, []string{"1", "2"}},
+		{LiquidityFilter{Allow: []string{"curve v[2-9]"}}, []string{"4"}},
+		{LiquidityFilter{Deny: []string{"Uni"}}, []string{"1", "2", "3", "4", "5", "6"}},
+		{LiquidityFilter{Deny: []string{"uni.fi", "uniswap *"}}, []string{"3", "4", "6"}},
+		{LiquidityFilter{Allow: []string{"Curve"}, Deny: []string{"curve_v1"}}, []string{"4"}},
+	}
+	for _, tt := range tests {
+		got, err := r.Select(ctx, chains.Ethereum, tt.filter)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Select(%+v) = %v, %v, want %v", tt.filter, got, err, tt.want)
+		}
+	}
+
+	if _, err := r.Resolve(ctx, chains.Ethereum, "Balancer"); !errors.Is(err, ErrLiquidityNotFound) {
+		t.Errorf("Resolve(Balancer) error = %v", err)
+	}
+	if !reflect.DeepEqual(unlisted, []string{"Uni", "Balancer"}) {
+		t.Errorf("unlisted = %v", unlisted)
+	}
+	if n := tr.Calls("/api/v5/dex/aggregator/get-liquidity"); n != 1 {
+		t.Errorf("liquidity fetched %d times, want 1", n)
+	}
+}
+
+func TestLiquidityRegistryConcurrent(t *testing.T) {
+	tr := transporttest.New(nil)
+	release := make(chan struct{})
+	tr.Handle("/api/v5/dex/aggregator/get-liquidity", func(req *transporttest.Request) (string, error) {
+		if req.Params["chainId"] == "56" {
+			<-release
+		}
+		return `[{"id":"1","name":"Uniswap V3"}]`, nil
+	})
+	r := NewLiquidityRegistry(NewDexAPI(tr), nil)
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := r.Sources(context.Background(), chains.BNBChain)
+		slow <- err
+	}()
+	for tr.Calls("/api/v5/dex/aggregator/get-liquidity") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// a slow fetch of one chain blocks neither other chains nor waiters whose ctx is done.
+	if _, err := r.Select(context.Background(), chains.Ethereum, LiquidityFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	r.Refresh(chains.Ethereum)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := r.Sources(ctx, chains.BNBChain); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("waiting Sources = %v", err)
+	}
+
+	close(release)
+	if err := <-slow; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Sources(context.Background(), chains.BNBChain); err != nil {
+		t.Fatal(err)
+	}
+	if n := tr.Calls("/api/v5/dex/aggregator/get-liquidity"); n != 2 {
+		t.Errorf("liquidity fetched %d times, want 2", n)
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex/crosschain"
+	"github.com/imzhongqi/okxos/types"
+)
+
+// QuoteVariant is one way of quoting a swap.
+type QuoteVariant struct {
+	// Name identifies the variant in the ranking, e.g. "uniswap only".
+	Name string
+	// DexIds limits the variant to these liquidity sources. Empty quotes against every source.
+	DexIds []string
+	// Splits, when set, divides the amount between several quotes by weight, each limited to
+	// its own liquidity sources, and DexIds is ignored. Each split is a separate swap, so its
+	// fees are counted once per split.
+	Splits []QuoteSplit
+}
+
+// QuoteSplit is a share of the amount of a split QuoteVariant.
+type QuoteSplit struct {
+	Weight int64
+	DexIds []string
+}
+
+// QuoteBestRequest describes the swap to compare quotes for.
+type QuoteBestRequest struct {
+	// Quote holds the swap. Its DexIds is used by the default variant only.
+	Quote GetQuotesRequest
+	// Variants are quoted concurrently. When empty, a single variant using Quote.DexIds is quoted.
+	Variants []QuoteVariant
+
+	// ToChainId, when set and different from Quote.ChainId, compares the routes of the
+	// cross-chain quote instead, and Quote.ToTokenAddress is a token of ToChainId.
+	ToChainId chains.ChainID
+	// Slippage is required by the cross-chain quote.
+	Slippage string
+
+	// NativePrices are the USD prices of the native tokens by chain. They price the gas of
+	// quotes that do not report a trade fee, and the network fees of cross-chain routes.
+	NativePrices map[chains.ChainID]types.Decimal
+	// ToTokenPrice is the USD price of the output token. It defaults to the price reported by the quote.
+	ToTokenPrice types.Decimal
+	// Concurrency limits the number of quotes in flight, 4 by default.
+	Concurrency int
+}
+
+// RankedQuote is the outcome of one variant, or of one cross-chain route.
+// Amounts are in the output token, in human-readable units.
+type RankedQuote struct {
+	Name   string
+	DexIds []string
+
+	// Quotes holds one quote per split, or a single quote.
+	Quotes []*QuotesResult
+	// CrossChain and Route are set for cross-chain routes.
+	CrossChain *crosschain.QuoteResult
+	Route      *crosschain.Router
+
+	Gross types.Decimal
+	Fees  types.Decimal
+	Net   types.Decimal
+	// Priced reports whether every fee could be priced in the output token. Unpriced fees are
+	// left out of Fees, so Net overstates the output.
+	Priced bool
+	// Reason explains how the net output was computed and how it compares to the best quote.
+	Reason string
+	// Err is set when the variant could not be quoted.
+	Err error
+}
+
+// QuoteBestResult holds the ranked quotes, best first, and the variants that failed.
+type QuoteBestResult struct {
+	Ranked []*RankedQuote
+	Failed []*RankedQuote
+}
+
+// Best returns the quote with the highest net output, or nil if every variant failed.
+func (r *QuoteBestResult) Best() *RankedQuote {
+	if len(r.Ranked) == 0 {
+		return nil
+	}
+	return r.Ranked[0]
+}
+
+// QuoteBest quotes several variants of a swap concurrently and ranks them by their net output,
+// the output amount minus the gas and trade fees priced in the output token.
+func (d *DexAPI) QuoteBest(ctx context.Context, req *QuoteBestRequest) (*QuoteBestResult, error) {
+	var quotes []*RankedQuote
+	var err error
+	if req.ToChainId != "" && req.ToChainId != req.Quote.ChainId {
+		quotes, err = d.quoteCrossChain(ctx, req)
+	} else {
+		quotes, err = d.quoteVariants(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(QuoteBestResult)
+	for _, q := range quotes {
+		if q.Err != nil {
+			q.Reason = "failed: " + q.Err.Error()
+			result.Failed = append(result.Failed, q)
+		} else {
+			result.Ranked = append(result.Ranked, q)
+		}
+	}
+	if len(result.Ranked) == 0 {
+		if len(result.Failed) > 0 {
+			return result, result.Failed[0].Err
+		}
+		return result, errors.New("dex: no quote variants")
+	}
+
+	sort.SliceStable(result.Ranked, func(i, j int) bool {
+		return result.Ranked[i].Net.Cmp(result.Ranked[j].Net) > 0
+	})
+	best := result.Ranked[0].Net
+	for i, q := range result.Ranked {
+		if i == 0 {
+			q.Reason += "; best net output"
+		} else {
+			q.Reason += fmt.Sprintf("; %s less than the best", best.Sub(q.Net).Text())
+		}
+	}
+	return result, nil
+}
+
+func (d *DexAPI) quoteVariants(ctx context.Context, req *QuoteBestRequest) ([]*RankedQuote, error) {
+	amount, ok := new(big.Int).SetString(req.Quote.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("dex: invalid amount %q", req.Quote.Amount)
+	}
+
+	variants := req.Variants
+	if len(variants) == 0 {
+		variants = []QuoteVariant{{Name: "best route", DexIds: req.Quote.DexIds}}
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	sem := make(chan struct{}, concurrency)
+
+	results := make([]*RankedQuote, len(variants))
+	errs := make([][]error, len(variants))
+	var wg sync.WaitGroup
+	for i := range variants {
+		v := variants[i]
+		q := &RankedQuote{Name: v.Name, DexIds: v.DexIds}
+		results[i] = q
+
+		legs := []QuoteSplit{{Weight: 1, DexIds: v.DexIds}}
+		if len(v.Splits) > 0 {
+			legs = v.Splits
+		}
+		parts, err := splitAmount(amount, legs)
+		if err != nil {
+			q.Err = err
+			continue
+		}
+		q.Quotes = make([]*QuotesResult, len(legs))
+		errs[i] = make([]error, len(legs))
+
+		for j := range legs {
+			wg.Add(1)
+			go func(i, j int, amount string, dexIds []string) {
+				defer wg.Done()
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					errs[i][j] = ctx.Err()
+					return
+				}
+				defer func() { <-sem }()
+
+				leg := req.Quote
+				leg.Amount = amount
+				leg.DexIds = dexIds
+				results[i].Quotes[j], errs[i][j] = d.GetQuotes(ctx, &leg)
+			}(i, j, parts[j].String(), legs[j].DexIds)
+		}
+	}
+	wg.Wait()
+
+	for i, q := range results {
+		for _, err := range errs[i] {
+			if err != nil {
+				q.Err = err
+				break
+			}
+		}
+		if q.Err == nil {
+			q.Err = req.rankSameChain(q)
+		}
+	}
+	return results, nil
+}
+
+// splitAmount divides amount by the weights of legs, giving the remainder to the last leg.
+func splitAmount(amount *big.Int, legs []QuoteSplit) ([]*big.Int, error) {
+	total := new(big.Int)
+	for _, l := range legs {
+		if l.Weight <= 0 {
+			return nil, errors.New("dex: split weights must be positive")
+		}
+		total.Add(total, big.NewInt(l.Weight))
+	}
+
+	parts := make([]*big.Int, len(legs))
+	rest := new(big.Int).Set(amount)
+	for i, l := range legs {
+		if i == len(legs)-1 {
+			parts[i] = rest
+			break
+		}
+		parts[i] = new(big.Int).Mul(amount, big.NewInt(l.Weight))
+		parts[i].Quo(parts[i], total)
+		rest.Sub(rest, parts[i])
+	}
+	for _, p := range parts {
+		if p.Sign() <= 0 {
+			return nil, errors.New("dex: amount is too small to split")
+		}
+	}
+	return parts, nil
+}
+
+func (req *QuoteBestRequest) rankSameChain(q *RankedQuote) error {
+	gross := types.DecimalFromInt64(0)
+	fees := types.DecimalFromInt64(0)
+	q.Priced = true
+
+	var notes []string
+	for _, r := range q.Quotes {
+		decimals := int(r.ToToken.Decimal)
+		amount, err := types.FromMinimalUnits(r.ToTokenAmount, decimals)
+		if err != nil {
+			return err
+		}
+		gross = gross.Add(amount)
+
+		price := req.ToTokenPrice
+		if !price.IsSet() || price.IsZero() {
+			price = r.ToToken.TokenUnitPrice
+		}
+
+		usd, how := req.networkFeeUSD(r)
+		if usd.IsSet() && price.IsSet() && !price.IsZero() {
+			fee := usd.Quo(price, int32(decimals))
+			fees = fees.Add(fee)
+			notes = append(notes, fmt.Sprintf("%s fee %s", how, fee.Text()))
+		} else {
+			q.Priced = false
+			notes = append(notes, "fee not priced")
+		}
+	}
+
+	q.Gross, q.Fees, q.Net = gross, fees, gross.Sub(fees)
+	q.Reason = fmt.Sprintf("%d quote(s), gross %s, %s, net %s", len(q.Quotes), gross.Text(), strings.Join(notes, ", "), q.Net.Text())
+	return nil
+}
+
+// networkFeeUSD prices the network fee of a quote in USD, preferring the reported trade fee.
+func (req *QuoteBestRequest) networkFeeUSD(r *QuotesResult) (types.Decimal, string) {
+	if r.TradeFee.IsSet() {
+		return r.TradeFee, "trade"
+	}
+	if r.EstimateGasFee.IsSet() {
+		gas, err := types.FromMinimalUnits(r.EstimateGasFee, nativeDecimals(req.Quote.ChainId))
+		if err != nil {
+			return types.Decimal{}, ""
+		}
+		if usd, ok := req.nativeUSD(req.Quote.ChainId, gas); ok {
+			return usd, "gas"
+		}
+	}
+	return types.Decimal{}, ""
+}
+
+func (req *QuoteBestRequest) nativeUSD(chainId chains.ChainID, amount types.Decimal) (types.Decimal, bool) {
+	price, ok := req.NativePrices[chainId]
+	if !ok || !price.IsSet() {
+		return types.Decimal{}, false
+	}
+	return amount.Mul(price), true
+}
+
+func nativeDecimals(chainId chains.ChainID) int {
+	if c, ok := chains.Lookup(chainId); ok && c.NativeDecimals > 0 {
+		return c.NativeDecimals
+	}
+	return 18
+}
+
+func (d *DexAPI) quoteCrossChain(ctx context.Context, req *QuoteBestRequest) ([]*RankedQuote, error) {
+	result, err := d.CrossChain.GetQuote(ctx, &crosschain.GetQuoteRequest{
+		FromChainId:                     req.Quote.ChainId,
+		ToChainId:                       req.ToChainId,
+		FromTokenAddress:                req.Quote.FromTokenAddress,
+		ToTokenAddress:                  req.Quote.ToTokenAddress,
+		Amount:                          req.Quote.Amount,
+		Slippage:                        req.Slippage,
+		FeePercent:                      req.Quote.FeePercent,
+		PriceImpactProtectionPercentage: req.Quote.PriceImpactProtectionPercentage,
+	})
+	if err != nil {
+		return []*RankedQuote{{Name: "cross-chain", Err: err}}, nil
+	}
+
+	decimals := int(result.ToToken.Decimals)
+	quotes := make([]*RankedQuote, 0, len(result.RouterList))
+	for i := range result.RouterList {
+		route := &result.RouterList[i]
+		q := &RankedQuote{Name: "cross-chain", CrossChain: result, Route: route, Priced: true}
+		if route.Router != nil {
+			q.Name = "cross-chain via " + route.Router.BridgeName
+		}
+
+		var err error
+		if q.Gross, err = types.FromMinimalUnits(route.ToTokenAmount, decimals); err != nil {
+			q.Err = err
+			quotes = append(quotes, q)
+			continue
+		}
+		q.Fees = types.DecimalFromInt64(0)
+
+		usd := types.DecimalFromInt64(0)
+		var notes []string
+		for _, fee := range []struct {
+			chainId chains.ChainID
+			amount  types.Decimal
+		}{
+			{req.Quote.ChainId, route.FromChainNetworkFee},
+			{req.ToChainId, route.ToChainNetworkFee},
+		} {
+			if !fee.amount.IsSet() {
+				continue
+			}
+			if v, ok := req.nativeUSD(fee.chainId, fee.amount); ok {
+				usd = usd.Add(v)
+			} else {
+				q.Priced = false
+			}
+		}
+
+		price := req.ToTokenPrice
+		if price.IsSet() && !price.IsZero() {
+			q.Fees = usd.Quo(price, int32(decimals))
+			notes = append(notes, "network fees "+q.Fees.Text())
+		} else if !usd.IsZero() {
+			q.Priced = false
+		}
+
+		// the bridge fee is only comparable when it is charged in the output token.
+		if route.Router != nil && route.Router.CrossChainFee.IsSet() && !route.Router.CrossChainFee.IsZero() {
+			if strings.EqualFold(route.Router.CrossChainFeeTokenAddress, result.ToToken.TokenContractAddress) {
+				q.Fees = q.Fees.Add(route.Router.CrossChainFee)
+				notes = append(notes, "bridge fee "+route.Router.CrossChainFee.Text())
+			} else {
+				q.Priced = false
+			}
+		}
+		if !q.Priced {
+			notes = append(notes, "some fees not priced")
+		}
+
+		if len(notes) == 0 {
+			notes = append(notes, "no fees")
+		}
+		q.Net = q.Gross.Sub(q.Fees)
+		q.Reason = fmt.Sprintf("gross %s, %s, net %s", q.Gross.Text(), strings.Join(notes, ", "), q.Net.Text())
+		quotes = append(quotes, q)
+	}
+	if len(quotes) == 0 {
+		return []*RankedQuote{{Name: "cross-chain", CrossChain: result, Err: errors.New("dex: cross-chain quote has no routes")}}, nil
+	}
+	return quotes, nil
+}
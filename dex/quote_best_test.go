@@ -0,0 +1,134 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/internal/transporttest"
+	"github.com/imzhongqi/okxos/types"
+)
+
+const (
+	testUSDC = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	testWETH = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+)
+
+func TestQuoteBest(t *testing.T) {
+	tr := transporttest.New(nil)
+	tr.Handle("/api/v5/dex/aggregator/quote", func(req *transporttest.Request) (string, error) {
+		switch req.Params["dexIds"] {
+		case "1": // the highest gross output pays the highest trade fee.
+			return `[{"toTokenAmount":"1000000000","tradeFee":"5","toToken":{"decimal":"6","tokenUnitPrice":"1"}}]`, nil
+		case "2":
+			return `[{"toTokenAmount":"998000000","tradeFee":"1","toToken":{"decimal":"6","tokenUnitPrice":"1"}}]`, nil
+		case "3": // no trade fee, the gas of 0.002 ETH is priced with the native price.
+			return `[{"toTokenAmount":"1000500000","estimateGasFee":"2000000000000000","toToken":{"decimal":"6","tokenUnitPrice":"1"}}]`, nil
+		}
+		return "", errors.New("no liquidity")
+	})
+	d := NewDexAPI(tr)
+
+	result, err := d.QuoteBest(context.Background(), &QuoteBestRequest{
+		Quote: GetQuotesRequest{ChainId: chains.Ethereum, Amount: "1000000000000000000", FromTokenAddress: testWETH, ToTokenAddress: testUSDC},
+		Variants: []QuoteVariant{
+			{Name: "uni", DexIds: []string{"1"}},
+			{Name: "curve", DexIds: []string{"2"}},
+			{Name: "gas", DexIds: []string{"3"}},
+			{Name: "none", DexIds: []string{"9"}},
+		},
+		NativePrices: map[chains.ChainID]types.Decimal{chains.Ethereum: types.DecimalFromInt64(2000)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ name, net string }{{"curve", "997"}, {"gas", "996.5"}, {"uni", "995"}}
+	if len(result.Ranked) != len(want) {
+		t.Fatalf("ranked %d quotes, want %d", len(result.Ranked), len(want))
+	}
+	for i, w := range want {
+		q := result.Ranked[i]
+		if q.Name != w.name || q.Net.Text() != w.net || !q.Priced {
+			t.Errorf("Ranked[%d] = %s net %s priced %v, want %s net %s", i, q.Name, q.Net.Text(), q.Priced, w.name, w.net)
+		}
+	}
+	if best := result.Best(); best.Gross.Text() != "998" || best.Fees.Text() != "1" {
+		t.Errorf("best gross %s fees %s", best.Gross.Text(), best.Fees.Text())
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Name != "none" || result.Failed[0].Err == nil {
+		t.Errorf("unexpected failed quotes %+v", result.Failed)
+	}
+}
+
+func TestQuoteBestCrossChain(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/dex/cross-chain/quote": `[{
+			"toToken":{"decimals":"18","tokenContractAddress":"0x55d398326f99059ff775485246999027b3197955"},
+			"routerList":[
+				{"toTokenAmount":"1000000000000000000000","fromChainNetworkFee":"0.001","toChainNetworkFee":"0.001",
+				 "router":{"bridgeName":"fast","crossChainFee":"1","crossChainFeeTokenAddress":"0x55d398326f99059ff775485246999027b3197955"}},
+				{"toTokenAmount":"998000000000000000000","router":{"bridgeName":"cheap"}},
+				{"toTokenAmount":"999000000000000000000",
+				 "router":{"bridgeName":"other","crossChainFee":"0.1","crossChainFeeTokenAddress":"0x0000000000000000000000000000000000000001"}}
+			]
+		}]`,
+	})
+	d := NewDexAPI(tr)
+
+	result, err := d.QuoteBest(context.Background(), &QuoteBestRequest{
+		Quote:     GetQuotesRequest{ChainId: chains.Ethereum, Amount: "1000000000", FromTokenAddress: testUSDC, ToTokenAddress: "0x55d398326f99059ff775485246999027b3197955"},
+		ToChainId: chains.BNBChain,
+		Slippage:  "0.01",
+		NativePrices: map[chains.ChainID]types.Decimal{
+			chains.Ethereum: types.DecimalFromInt64(2000),
+			chains.BNBChain: types.DecimalFromInt64(600),
+		},
+		ToTokenPrice: types.DecimalFromInt64(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the network fees are 2.6 USD, the bridge fee of "other" is charged in another token.
+	want := []struct {
+		name, net string
+		priced    bool
+	}{
+		{"cross-chain via other", "999", false},
+		{"cross-chain via cheap", "998", true},
+		{"cross-chain via fast", "996.4", true},
+	}
+	if len(result.Ranked) != len(want) {
+		t.Fatalf("ranked %d routes, want %d", len(result.Ranked), len(want))
+	}
+	for i, w := range want {
+		q := result.Ranked[i]
+		if q.Name != w.name || q.Net.Text() != w.net || q.Priced != w.priced {
+			t.Errorf("Ranked[%d] = %s net %s priced %v, want %s net %s priced %v", i, q.Name, q.Net.Text(), q.Priced, w.name, w.net, w.priced)
+		}
+	}
+	if n := tr.Calls("/api/v5/dex/cross-chain/quote"); n != 1 {
+		t.Errorf("cross-chain quote fetched %d times, want 1", n)
+	}
+}
@@ -21,14 +21,18 @@ package dex
 
 import (
 	"context"
-	"strings"
+	"encoding/json"
 
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type GetQuotesRequest struct {
 	// ChainId is the chain ID (e.g., 1 for Ethereum. See Chain IDs)
-	ChainId string `json:"chainId"`
+	ChainId chains.ChainID `json:"chainId"`
 	// Amount is the input amount of a token to be sold (set in minimal divisible units,
 	// e.g., 1.00 USDT set as 1000000, 1.00 DAI set as 1000000000000000000), you could get the minimal divisible units from Tokenlist.
 	Amount string `json:"amount"`
@@ -37,26 +41,47 @@ type GetQuotesRequest struct {
 	// ToTokenAddress is the contract address of a token to be bought (e.g., 0xa892e1fef8b31acc44ce78e7db0a2dc610f92d00)
 	ToTokenAddress string `json:"toTokenAddress"`
 	// DexIds is DexId of the liquidity pool for limited quotes, multiple combinations separated by , (e.g.,1,50,180, see liquidity list for more)
-	DexIds []string `json:"dexIds"`
+	DexIds []string `json:"dexIds,omitempty"`
 	// PriceImpactProtectionPercentage is the percentage (between 0 - 1.0) of the price impact allowed.
-	PriceImpactProtectionPercentage string `json:"priceImpactProtectionPercentage"`
+	PriceImpactProtectionPercentage string `json:"priceImpactProtectionPercentage,omitempty"`
 	// FeePercent is the percentage of fromTokenAmount will be sent to the referrer's address,
 	// the rest will be set as the input amount to be sold. min percentage：0
-	FeePercent string `json:"feePercent"`
+	FeePercent string `json:"feePercent,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetQuotesRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainId", r.ChainId.String())
+	v.Amount("amount", r.Amount)
+	v.Required("fromTokenAddress", r.FromTokenAddress)
+	v.Required("toTokenAddress", r.ToTokenAddress)
+	address.CheckToken(v, "fromTokenAddress", r.ChainId, r.FromTokenAddress)
+	address.CheckToken(v, "toTokenAddress", r.ChainId, r.ToTokenAddress)
+	v.Range("priceImpactProtectionPercentage", r.PriceImpactProtectionPercentage, 0, 1)
+	v.Range("feePercent", r.FeePercent, 0, 3)
+	return v.Err()
+}
+
+func (r *GetQuotesRequest) normalize() *GetQuotesRequest {
+	n := *r
+	n.FromTokenAddress = address.NormalizeToken(r.ChainId, r.FromTokenAddress)
+	n.ToTokenAddress = address.NormalizeToken(r.ChainId, r.ToTokenAddress)
+	return &n
 }
 
 type DexProtocol struct {
-	Percent string `json:"percent"`
-	DexName string `json:"dexName"`
+	Percent types.Decimal `json:"percent"`
+	DexName string        `json:"dexName"`
 }
 
 type TokenInfo struct {
-	Decimal              string `json:"decimal"`
-	IsHoneyPot           bool   `json:"isHoneyPot"`
-	TaxRate              string `json:"taxRate"`
-	TokenContractAddress string `json:"tokenContractAddress"`
-	TokenSymbol          string `json:"tokenSymbol"`
-	TokenUnitPrice       string `json:"tokenUnitPrice"`
+	Decimal              types.Int     `json:"decimal"`
+	IsHoneyPot           bool          `json:"isHoneyPot"`
+	TaxRate              types.Decimal `json:"taxRate"`
+	TokenContractAddress string        `json:"tokenContractAddress"`
+	TokenSymbol          string        `json:"tokenSymbol"`
+	TokenUnitPrice       types.Decimal `json:"tokenUnitPrice"`
 }
 
 type SubRouter struct {
@@ -72,35 +97,42 @@ type DexRouter struct {
 }
 
 type QuoteCompare struct {
-	AmountOut string `json:"amountOut"`
-	DexLogo   string `json:"dexLogo"`
-	DexName   string `json:"dexName"`
-	TradeFee  string `json:"tradeFee"`
+	AmountOut types.Decimal `json:"amountOut"`
+	DexLogo   string        `json:"dexLogo"`
+	DexName   string        `json:"dexName"`
+	TradeFee  types.Decimal `json:"tradeFee"`
 }
 
 type QuotesResult struct {
-	ChainId          string         `json:"chainId"`
+	ChainId          chains.ChainID `json:"chainId"`
 	DexRouterList    []DexRouter    `json:"dexRouterList"`
-	EstimateGasFee   string         `json:"estimateGasFee"`
+	EstimateGasFee   types.Uint256  `json:"estimateGasFee"`
 	FromToken        TokenInfo      `json:"fromToken"`
-	FromTokenAmount  string         `json:"fromTokenAmount"`
-	PriceImpactPct   string         `json:"priceImpactPct"`
+	FromTokenAmount  types.Uint256  `json:"fromTokenAmount"`
+	PriceImpactPct   types.Decimal  `json:"priceImpactPct"`
 	QuoteCompareList []QuoteCompare `json:"quoteCompareList"`
 	ToToken          TokenInfo      `json:"toToken"`
-	ToTokenAmount    string         `json:"toTokenAmount"`
-	TradeFee         string         `json:"tradeFee"`
+	ToTokenAmount    types.Uint256  `json:"toTokenAmount"`
+	TradeFee         types.Decimal  `json:"tradeFee"`
+
+	// Extra holds the response fields that are not declared above yet.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *QuotesResult) UnmarshalJSON(data []byte) (err error) {
+	type alias QuotesResult
+	r.Extra, err = client.DecodeExtra(data, (*alias)(r))
+	return
 }
 
 // Get Quotes
 func (d *DexAPI) GetQuotes(ctx context.Context, req *GetQuotesRequest) (result *QuotesResult, err error) {
-	params := map[string]string{
-		"chainId":                         req.ChainId,
-		"amount":                          req.Amount,
-		"fromTokenAddress":                req.FromTokenAddress,
-		"toTokenAddress":                  req.ToTokenAddress,
-		"dexIds":                          strings.Join(req.DexIds, ","),
-		"priceImpactProtectionPercentage": req.PriceImpactProtectionPercentage,
-		"feePercent":                      req.FeePercent,
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := client.EncodeQuery(req.normalize())
+	if err != nil {
+		return nil, err
 	}
 	var results []*QuotesResult
 	if err = d.tr.Get(ctx, "/api/v5/dex/aggregator/quote", params, &results); err != nil {
@@ -109,5 +141,12 @@ func (d *DexAPI) GetQuotes(ctx context.Context, req *GetQuotesRequest) (result *
 	if len(results) == 0 {
 		return nil, errcode.ErrResultsNotFound
 	}
-	return results[0], nil
+	result = results[0]
+	if err = d.checkToken(ctx, req.ChainId, req.FromTokenAddress, &result.FromToken); err != nil {
+		return nil, err
+	}
+	if err = d.checkToken(ctx, req.ChainId, req.ToTokenAddress, &result.ToToken); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
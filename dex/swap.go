@@ -21,27 +21,31 @@ package dex
 
 import (
 	"context"
-	"strings"
+	"encoding/json"
 
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type Tx struct {
-	Data                 string   `json:"data"`
-	From                 string   `json:"from"`
-	Gas                  string   `json:"gas"`
-	GasPrice             string   `json:"gasPrice"`
-	MaxPriorityFeePerGas string   `json:"maxPriorityFeePerGas"`
-	MinReceiveAmount     string   `json:"minReceiveAmount"`
-	SignatureData        []string `json:"signatureData"`
-	To                   string   `json:"to"`
-	Value                string   `json:"value"`
+	Data                 string        `json:"data"`
+	From                 string        `json:"from"`
+	Gas                  types.Uint256 `json:"gas"`
+	GasPrice             types.Uint256 `json:"gasPrice"`
+	MaxPriorityFeePerGas types.Uint256 `json:"maxPriorityFeePerGas"`
+	MinReceiveAmount     types.Uint256 `json:"minReceiveAmount"`
+	SignatureData        []string      `json:"signatureData"`
+	To                   string        `json:"to"`
+	Value                types.Uint256 `json:"value"`
 }
 
 // GetSwapTxRequest
 type GetSwapTxRequest struct {
 	// Chain Id (e.g., 1 for Ethereum. See Chain IDs), Required
-	ChainId string `json:"chainId"`
+	ChainId chains.ChainID `json:"chainId"`
 	// The input amount of a token to be sold, Required
 	Amount string `json:"amount"`
 	// The contract address of a token you want to send (e.g.,0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee), Required
@@ -60,56 +64,103 @@ type GetSwapTxRequest struct {
 	// Note:
 	// 1. For EVM chains: Transactions involving wrapped pairs, such as ETH and WETH, are not supported here.
 	// 2. For Solana chain: The commission address must have some SOL deposited in advance for activation.
-	ReferrerAddress string `json:"referrerAddress"`
+	ReferrerAddress string `json:"referrerAddress,omitempty"`
 	// Recipient address of a purchased token if not set,
 	//userWalletAddress will receive a purchased token (e.g.,0x3f6a3f57569358a512ccc0e513f171516b0fd42a)
-	SwapReceiverAddress string `json:"swapReceiverAddress"`
+	SwapReceiverAddress string `json:"swapReceiverAddress,omitempty"`
 	// The percentage of fromTokenAmount will be sent to the referrer's address,
 	// the rest will be set as the input amount to be sold.
 	// Min percentage: 0. Max percentage: 3. Maximum 2 decimal points.
 	// Longer sections will be automatically omitted. (E.g. 1.326% is the actual input, but the final calculation will only adopt 1.32%.)
-	FeePercent string `json:"feePercent"`
+	FeePercent string `json:"feePercent,omitempty"`
 	// The gas (in wei) for the swap transaction. If the value is too low to achieve the quote, an error will be returned
-	Gaslimit string `json:"gaslimit"`
+	Gaslimit string `json:"gaslimit,omitempty"`
 	// The target gas price level for the swap transaction,set to average or fast or slow
-	GasLevel string `json:"gasLevel"`
+	GasLevel string `json:"gasLevel,omitempty"`
 	// DexId of the liquidity pool for limited quotes, multiple combinations separated by , (e.g., 1,50,180, see liquidity list for more)
-	DexIds []string `json:"dexIds"`
+	DexIds []string `json:"dexIds,omitempty"`
 	// The percentage (between 0 - 1.0) of the price impact allowed.
-	PriceImpactProtectionPercentage string `json:"priceImpactProtectionPercentage"`
+	PriceImpactProtectionPercentage string `json:"priceImpactProtectionPercentage,omitempty"`
 	// You can customize the parameters to be sent on the blockchain in callData by encoding the
 	// data into a 128-character 64-bytes hexadecimal string.
 	// For example, the string
 	// "0x111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111111"
 	// needs to keep the "0x" at its start.
-	CallDataMemo string `json:"callDataMemo"`
+	CallDataMemo string `json:"callDataMemo,omitempty"`
 	// The toToken address that receives the commission.
-	ToTokenReferrerAddress string `json:"toTokenReferrerAddress"`
+	ToTokenReferrerAddress string `json:"toTokenReferrerAddress,omitempty"`
 	// Used for transactions on the Solana network and similar to gasPrice on Ethereum.
 	// This price determines the priority level of the transaction.
 	// The higher the price, the more likely that the transaction can be processed faster.
-	ComputeUnitPrice string `json:"computeUnitPrice"`
+	ComputeUnitPrice string `json:"computeUnitPrice,omitempty"`
 	// Used for transactions on the Solana network and analogous to gasLimit on Ethereum,
 	// which ensures that the transaction won't take too much computing resource.
-	ComputeUnitLimit string `json:"computeUnitLimit"`
+	ComputeUnitLimit string `json:"computeUnitLimit,omitempty"`
 	// The wallet address to receive the commission fee from the fromToken.
 	// This new field no longer requires a token account parameter for SPL Token;
 	// specifying the Sol wallet address is sufficient.
-	FromTokenReferrerWalletAddress string `json:"fromTokenReferrerWalletAddress"`
+	FromTokenReferrerWalletAddress string `json:"fromTokenReferrerWalletAddress,omitempty"`
 	// The wallet address to receive the commission fee from the toToken.
 	// This new field no longer requires a token account parameter for SPL-Token.
-	ToTokenReferrerWalletAddress string `json:"toTokenReferrerWalletAddress"`
+	ToTokenReferrerWalletAddress string `json:"toTokenReferrerWalletAddress,omitempty"`
 	// Default is false. When set to true, the original slippage (if set) will be covered by the autoSlippage and
 	// the API will calculate and return auto slippage recommendations based on current market data.
-	AutoSlippage bool `json:"autoSlippage"`
+	AutoSlippage bool `json:"autoSlippage,omitempty"`
 	// When autoSlippage is set to true, this value is the maximum auto slippage returned by the API.
 	// We recommend that users adopt this value to ensure risk control.
-	MaxAutoSlippage string `json:"maxAutoSlippage"`
+	MaxAutoSlippage string `json:"maxAutoSlippage,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetSwapTxRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainId", r.ChainId.String())
+	v.Amount("amount", r.Amount)
+	v.Required("fromTokenAddress", r.FromTokenAddress)
+	v.Required("toTokenAddress", r.ToTokenAddress)
+	v.Required("slippage", r.Slippage)
+	v.Range("slippage", r.Slippage, 0, 1)
+	v.Required("userWalletAddress", r.UserWalletAddress)
+	address.CheckToken(v, "fromTokenAddress", r.ChainId, r.FromTokenAddress)
+	address.CheckToken(v, "toTokenAddress", r.ChainId, r.ToTokenAddress)
+	address.Check(v, "userWalletAddress", r.ChainId, r.UserWalletAddress)
+	address.Check(v, "referrerAddress", r.ChainId, r.ReferrerAddress)
+	address.Check(v, "swapReceiverAddress", r.ChainId, r.SwapReceiverAddress)
+	address.Check(v, "toTokenReferrerAddress", r.ChainId, r.ToTokenReferrerAddress)
+	address.Check(v, "fromTokenReferrerWalletAddress", r.ChainId, r.FromTokenReferrerWalletAddress)
+	address.Check(v, "toTokenReferrerWalletAddress", r.ChainId, r.ToTokenReferrerWalletAddress)
+	v.Range("feePercent", r.FeePercent, 0, 3)
+	v.OneOf("gasLevel", r.GasLevel, "slow", "average", "fast")
+	v.Range("priceImpactProtectionPercentage", r.PriceImpactProtectionPercentage, 0, 1)
+	v.Range("maxAutoSlippage", r.MaxAutoSlippage, 0, 1)
+	return v.Err()
+}
+
+func (r *GetSwapTxRequest) normalize() *GetSwapTxRequest {
+	n := *r
+	n.FromTokenAddress = address.NormalizeToken(r.ChainId, r.FromTokenAddress)
+	n.ToTokenAddress = address.NormalizeToken(r.ChainId, r.ToTokenAddress)
+	n.UserWalletAddress = address.Canonical(r.ChainId, r.UserWalletAddress)
+	n.ReferrerAddress = address.Canonical(r.ChainId, r.ReferrerAddress)
+	n.SwapReceiverAddress = address.Canonical(r.ChainId, r.SwapReceiverAddress)
+	n.ToTokenReferrerAddress = address.Canonical(r.ChainId, r.ToTokenReferrerAddress)
+	n.FromTokenReferrerWalletAddress = address.Canonical(r.ChainId, r.FromTokenReferrerWalletAddress)
+	n.ToTokenReferrerWalletAddress = address.Canonical(r.ChainId, r.ToTokenReferrerWalletAddress)
+	return &n
 }
 
 type GetSwapTxResult struct {
 	RouterResult *QuotesResult `json:"routerResult"`
 	Tx           *Tx           `json:"tx"`
+
+	// Extra holds the response fields that are not declared above yet.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *GetSwapTxResult) UnmarshalJSON(data []byte) (err error) {
+	type alias GetSwapTxResult
+	r.Extra, err = client.DecodeExtra(data, (*alias)(r))
+	return
 }
 
 // Swap generates the data to call the OKX DEX router to execute a swap.
@@ -118,58 +169,15 @@ type GetSwapTxResult struct {
 // 1. For EVM chains: Transactions involving wrapped pairs, such as ETH and WETH, are not supported here.
 // 2. For Solana chain: The commission address must have some SOL deposited in advance for activation.
 func (d *DexAPI) GetSwapTx(ctx context.Context, swap *GetSwapTxRequest) (result *GetSwapTxResult, err error) {
-	params := map[string]string{
-		"chainId":           swap.ChainId,
-		"amount":            swap.Amount,
-		"fromTokenAddress":  swap.FromTokenAddress,
-		"toTokenAddress":    swap.ToTokenAddress,
-		"slippage":          swap.Slippage,
-		"userWalletAddress": swap.UserWalletAddress,
-	}
-	if swap.ReferrerAddress != "" {
-		params["referrerAddress"] = swap.ReferrerAddress
-	}
-	if swap.SwapReceiverAddress != "" {
-		params["swapReceiverAddress"] = swap.SwapReceiverAddress
-	}
-	if swap.FeePercent != "" {
-		params["feePercent"] = swap.FeePercent
-	}
-	if swap.Gaslimit != "" {
-		params["gaslimit"] = swap.Gaslimit
-	}
-	if swap.GasLevel != "" {
-		params["gasLevel"] = swap.GasLevel
-	}
-	if len(swap.DexIds) > 0 {
-		params["dexIds"] = strings.Join(swap.DexIds, ",")
-	}
-	if swap.PriceImpactProtectionPercentage != "" {
-		params["priceImpactProtectionPercentage"] = swap.PriceImpactProtectionPercentage
-	}
-	if swap.CallDataMemo != "" {
-		params["callDataMemo"] = swap.CallDataMemo
-	}
-	if swap.ToTokenReferrerAddress != "" {
-		params["toTokenReferrerAddress"] = swap.ToTokenReferrerAddress
-	}
-	if swap.ComputeUnitPrice != "" {
-		params["computeUnitPrice"] = swap.ComputeUnitPrice
-	}
-	if swap.ComputeUnitLimit != "" {
-		params["computeUnitLimit"] = swap.ComputeUnitLimit
-	}
-	if swap.FromTokenReferrerWalletAddress != "" {
-		params["fromTokenReferrerWalletAddress"] = swap.FromTokenReferrerWalletAddress
-	}
-	if swap.ToTokenReferrerWalletAddress != "" {
-		params["toTokenReferrerWalletAddress"] = swap.ToTokenReferrerWalletAddress
+	if err = swap.Validate(); err != nil {
+		return nil, err
 	}
-	if swap.AutoSlippage {
-		params["autoSlippage"] = "true"
+	if err = d.checkTokens(ctx, swap.ChainId, swap.FromTokenAddress, swap.ToTokenAddress); err != nil {
+		return nil, err
 	}
-	if swap.MaxAutoSlippage != "" {
-		params["maxAutoSlippage"] = swap.MaxAutoSlippage
+	params, err := client.EncodeQuery(swap.normalize())
+	if err != nil {
+		return nil, err
 	}
 
 	var results []*GetSwapTxResult
@@ -180,25 +188,68 @@ func (d *DexAPI) GetSwapTx(ctx context.Context, swap *GetSwapTxRequest) (result
 	if len(results) == 0 {
 		return nil, errcode.ErrResultsNotFound
 	}
-
-	return results[0], nil
+	result = results[0]
+	if r := result.RouterResult; r != nil {
+		if err = d.checkToken(ctx, swap.ChainId, swap.FromTokenAddress, &r.FromToken); err != nil {
+			return nil, err
+		}
+		if err = d.checkToken(ctx, swap.ChainId, swap.ToTokenAddress, &r.ToToken); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 type GetSolSwapInstructionRequest struct {
-	ChainId                         string
-	Amount                          string
-	FromTokenAddress                string
-	ToTokenAddress                  string
-	Slippage                        string
-	UserWalletAddress               string
-	SwapReceiverAddress             string
-	FeePercent                      string
-	FromTokenReferrerWalletAddress  string
-	ToTokenReferrerWalletAddress    string
-	DexIds                          []string
-	PriceImpactProtectionPercentage string
-	ComputeUnitPrice                string
-	ComputeUnitLimit                string
+	// Chain Id (e.g., 501 for Solana), Required
+	ChainId chains.ChainID `json:"chainId"`
+	// The input amount of a token to be sold, Required
+	Amount string `json:"amount"`
+	// The contract address of a token you want to send, Required
+	FromTokenAddress string `json:"fromTokenAddress"`
+	// The contract address of a token you want to receive, Required
+	ToTokenAddress string `json:"toTokenAddress"`
+	// The slippage you are willing to accept. min:0 max:1, Required
+	Slippage string `json:"slippage"`
+	// User's wallet address, Required
+	UserWalletAddress string `json:"userWalletAddress"`
+	// Recipient address of a purchased token, if not set, userWalletAddress will receive a purchased token
+	SwapReceiverAddress string `json:"swapReceiverAddress,omitempty"`
+	// The percentage of fromTokenAmount will be sent to the referrer's address
+	FeePercent string `json:"feePercent,omitempty"`
+	// The wallet address to receive the commission fee from the fromToken
+	FromTokenReferrerWalletAddress string `json:"fromTokenReferrerWalletAddress,omitempty"`
+	// The wallet address to receive the commission fee from the toToken
+	ToTokenReferrerWalletAddress string `json:"toTokenReferrerWalletAddress,omitempty"`
+	// DexId of the liquidity pool for limited quotes
+	DexIds []string `json:"dexIds,omitempty"`
+	// The percentage (between 0 - 1.0) of the price impact allowed.
+	PriceImpactProtectionPercentage string `json:"priceImpactProtectionPercentage,omitempty"`
+	// Compute unit price of the transaction, similar to gasPrice on Ethereum
+	ComputeUnitPrice string `json:"computeUnitPrice,omitempty"`
+	// Compute unit limit of the transaction, similar to gasLimit on Ethereum
+	ComputeUnitLimit string `json:"computeUnitLimit,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetSolSwapInstructionRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainId", r.ChainId.String())
+	v.Amount("amount", r.Amount)
+	v.Required("fromTokenAddress", r.FromTokenAddress)
+	v.Required("toTokenAddress", r.ToTokenAddress)
+	v.Required("slippage", r.Slippage)
+	v.Range("slippage", r.Slippage, 0, 1)
+	v.Required("userWalletAddress", r.UserWalletAddress)
+	address.CheckToken(v, "fromTokenAddress", r.ChainId, r.FromTokenAddress)
+	address.CheckToken(v, "toTokenAddress", r.ChainId, r.ToTokenAddress)
+	address.Check(v, "userWalletAddress", r.ChainId, r.UserWalletAddress)
+	address.Check(v, "swapReceiverAddress", r.ChainId, r.SwapReceiverAddress)
+	address.Check(v, "fromTokenReferrerWalletAddress", r.ChainId, r.FromTokenReferrerWalletAddress)
+	address.Check(v, "toTokenReferrerWalletAddress", r.ChainId, r.ToTokenReferrerWalletAddress)
+	v.Range("feePercent", r.FeePercent, 0, 10)
+	v.Range("priceImpactProtectionPercentage", r.PriceImpactProtectionPercentage, 0, 1)
+	return v.Err()
 }
 
 type GetSolSwapInstructionResult struct {
@@ -219,37 +270,15 @@ type AccountInfo struct {
 }
 
 func (d *DexAPI) GetSolSwapInstruction(ctx context.Context, req *GetSolSwapInstructionRequest) (result *GetSolSwapInstructionResult, err error) {
-	params := map[string]string{
-		"chainId":           req.ChainId,
-		"amount":            req.Amount,
-		"fromTokenAddress":  req.FromTokenAddress,
-		"toTokenAddress":    req.ToTokenAddress,
-		"slippage":          req.Slippage,
-		"userWalletAddress": req.UserWalletAddress,
-	}
-	if req.SwapReceiverAddress != "" {
-		params["swapReceiverAddress"] = req.SwapReceiverAddress
-	}
-	if req.FeePercent != "" {
-		params["feePercent"] = req.FeePercent
-	}
-	if req.FromTokenReferrerWalletAddress != "" {
-		params["fromTokenReferrerWalletAddress"] = req.FromTokenReferrerWalletAddress
-	}
-	if req.ToTokenReferrerWalletAddress != "" {
-		params["toTokenReferrerWalletAddress"] = req.ToTokenReferrerWalletAddress
-	}
-	if len(req.DexIds) > 0 {
-		params["dexIds"] = strings.Join(req.DexIds, ",")
-	}
-	if req.PriceImpactProtectionPercentage != "" {
-		params["priceImpactProtectionPercentage"] = req.PriceImpactProtectionPercentage
+	if err = req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.ComputeUnitPrice != "" {
-		params["computeUnitPrice"] = req.ComputeUnitPrice
+	if err = d.checkTokens(ctx, req.ChainId, req.FromTokenAddress, req.ToTokenAddress); err != nil {
+		return nil, err
 	}
-	if req.ComputeUnitLimit != "" {
-		params["computeUnitLimit"] = req.ComputeUnitLimit
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 
 	if err = d.tr.Get(ctx, "/api/v5/dex/aggregator/swap-instruction", params, &result); err != nil {
@@ -21,49 +21,62 @@ package dex
 
 import (
 	"context"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type GetTransactionStatusRequest struct {
 	// Chain Id (e.g., 1 for Ethereum. See Chain IDs), Required
-	ChainId string `json:"chainId"`
+	ChainId chains.ChainID `json:"chainId"`
 	// Transaction hash, Required
 	TxHash string `json:"txHash"`
 	// Set true to check if the transaction is under the current API Key. Set false or omit to query any OKX DEX API transaction.
-	IsFromMyProject bool `json:"isFromMyProject"`
+	IsFromMyProject bool `json:"isFromMyProject,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTransactionStatusRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainId", r.ChainId.String())
+	v.Required("txHash", r.TxHash)
+	return v.Err()
 }
 
 type TokenDetail struct {
-	Symbol       string `json:"symbol"`
-	Amount       string `json:"amount"`
-	TokenAddress string `json:"tokenAddress"`
+	Symbol       string        `json:"symbol"`
+	Amount       types.Decimal `json:"amount"`
+	TokenAddress string        `json:"tokenAddress"`
 }
 
 type TransactionStatusResult struct {
-	ChainId          string       `json:"chainId"`
-	Hash             string       `json:"hash"`
-	Height           string       `json:"height"`
-	TxTime           string       `json:"txTime"`
-	Status           string       `json:"status"`
-	TxType           string       `json:"txType"`
-	FromAddress      string       `json:"fromAddress"`
-	FromTokenDetails *TokenDetail `json:"fromTokenDetails"`
-	ToTokenDetails   *TokenDetail `json:"toTokenDetails"`
-	ReferalAmount    string       `json:"referalAmount"`
-	ErrorMsg         string       `json:"errorMsg"`
-	GasLimit         string       `json:"gasLimit"`
-	GasUsed          string       `json:"gasUsed"`
-	GasPrice         string       `json:"gasPrice"`
-	TxFee            string       `json:"txFee"`
+	ChainId          chains.ChainID `json:"chainId"`
+	Hash             string         `json:"hash"`
+	Height           types.Int      `json:"height"`
+	TxTime           types.Millis   `json:"txTime"`
+	Status           string         `json:"status"`
+	TxType           string         `json:"txType"`
+	FromAddress      string         `json:"fromAddress"`
+	FromTokenDetails *TokenDetail   `json:"fromTokenDetails"`
+	ToTokenDetails   *TokenDetail   `json:"toTokenDetails"`
+	ReferalAmount    types.Decimal  `json:"referalAmount"`
+	ErrorMsg         string         `json:"errorMsg"`
+	GasLimit         types.Uint256  `json:"gasLimit"`
+	GasUsed          types.Uint256  `json:"gasUsed"`
+	GasPrice         types.Uint256  `json:"gasPrice"`
+	TxFee            types.Decimal  `json:"txFee"`
 }
 
 // GetTransactionStatus Query the final transaction status of a single-chain swap using txhash.
 func (d *DexAPI) GetTransactionStatus(ctx context.Context, req *GetTransactionStatusRequest) (*TransactionStatusResult, error) {
-	params := map[string]string{
-		"chainId": req.ChainId,
-		"txHash":  req.TxHash,
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.IsFromMyProject {
-		params["isFromMyProject"] = "true"
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 
 	var result *TransactionStatusResult
@@ -0,0 +1,205 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
+)
+
+var ErrSwapNotIndexed = errors.New("dex: swap transaction not indexed")
+
+// SwapStatus is the status of a swap transaction.
+type SwapStatus string
+
+const (
+	// SwapNotIndexed is the status of a transaction the API does not know yet.
+	SwapNotIndexed SwapStatus = "not_indexed"
+	SwapPending    SwapStatus = "pending"
+	SwapSuccess    SwapStatus = "success"
+	SwapFail       SwapStatus = "fail"
+)
+
+// IsFinal reports whether s is a terminal status.
+func (s SwapStatus) IsFinal() bool {
+	return s == SwapSuccess || s == SwapFail
+}
+
+// SwapTxType is the kind of a transaction sent to the router.
+type SwapTxType string
+
+const (
+	SwapTxApprove SwapTxType = "Approve"
+	SwapTxWrap    SwapTxType = "Wrap"
+	SwapTxUnwrap  SwapTxType = "Unwrap"
+	SwapTxSwap    SwapTxType = "Swap"
+)
+
+// SwapStatus returns the typed status of the transaction. Unknown statuses are pending.
+func (r *TransactionStatusResult) SwapStatus() SwapStatus {
+	if r == nil {
+		return SwapNotIndexed
+	}
+	switch SwapStatus(r.Status) {
+	case SwapSuccess:
+		return SwapSuccess
+	case SwapFail:
+		return SwapFail
+	}
+	return SwapPending
+}
+
+// Type returns the typed kind of the transaction.
+func (r *TransactionStatusResult) Type() SwapTxType {
+	return SwapTxType(r.TxType)
+}
+
+// SwapOutcome is the final state of a swap transaction.
+type SwapOutcome struct {
+	Status SwapStatus
+	Type   SwapTxType
+	Result *TransactionStatusResult
+	// GasUsed, GasPrice and GasFee are the gas used, the gas price and their product in the
+	// minimal units of the native token. Fee is the reported fee in native token units.
+	GasUsed  types.Uint256
+	GasPrice types.Uint256
+	GasFee   types.Uint256
+	Fee      types.Decimal
+	// Polls is the number of status requests made.
+	Polls int
+}
+
+type WaitOptions struct {
+	pollInterval time.Duration
+	maxInterval  time.Duration
+	indexTimeout time.Duration
+}
+
+type WaitOption interface {
+	apply(o *WaitOptions)
+}
+
+type waitOptionFunc func(o *WaitOptions)
+
+func (f waitOptionFunc) apply(o *WaitOptions) {
+	f(o)
+}
+
+// WithWaitBackoff sets the first and the largest interval between polls, 2s and 20s by default.
+// An interval that is not positive keeps its default.
+func WithWaitBackoff(initial, maxInterval time.Duration) WaitOption {
+	return waitOptionFunc(func(o *WaitOptions) {
+		if initial > 0 {
+			o.pollInterval = initial
+		}
+		if maxInterval > 0 {
+			o.maxInterval = maxInterval
+		}
+	})
+}
+
+// WithIndexTimeout sets how long a transaction may stay unknown to the API before WaitForSwap
+// gives up with ErrSwapNotIndexed, 3m by default. Zero waits until the context is done.
+func WithIndexTimeout(d time.Duration) WaitOption {
+	return waitOptionFunc(func(o *WaitOptions) {
+		o.indexTimeout = d
+	})
+}
+
+// WaitForSwap polls the status of the swap transaction txHash until it succeeds or fails, and
+// returns its outcome. A failed swap is an outcome with SwapFail, not an error. The overall
+// wait is bounded by ctx. Failed polls are retried, except for validation errors; when the wait
+// gives up, its error wraps the last poll error, if any.
+func (d *DexAPI) WaitForSwap(ctx context.Context, chainId chains.ChainID, txHash string, opts ...WaitOption) (*SwapOutcome, error) {
+	o := WaitOptions{pollInterval: 2 * time.Second, maxInterval: 20 * time.Second, indexTimeout: 3 * time.Minute}
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+	if o.maxInterval < o.pollInterval {
+		o.maxInterval = o.pollInterval
+	}
+
+	start := time.Now()
+	interval := o.pollInterval
+	outcome := &SwapOutcome{Status: SwapNotIndexed}
+	var lastErr error
+	giveUp := func(err error) (*SwapOutcome, error) {
+		if lastErr != nil {
+			return outcome, fmt.Errorf("%w: %v", err, lastErr)
+		}
+		return outcome, err
+	}
+	for {
+		result, err := d.GetTransactionStatus(ctx, &GetTransactionStatusRequest{ChainId: chainId, TxHash: txHash})
+		outcome.Polls++
+		switch {
+		case err == nil:
+			outcome.Status = result.SwapStatus()
+		case errors.Is(err, errcode.ErrResultsNotFound):
+			outcome.Status = SwapNotIndexed
+		case errcode.IsValidationError(err):
+			return outcome, err
+		case ctx.Err() != nil:
+			return giveUp(ctx.Err())
+		default:
+			// the status is unchanged, the poll is retried.
+			lastErr = err
+		}
+
+		if outcome.Status.IsFinal() {
+			outcome.fill(result)
+			return outcome, nil
+		}
+		if outcome.Status == SwapNotIndexed && o.indexTimeout > 0 && time.Since(start) > o.indexTimeout {
+			return giveUp(ErrSwapNotIndexed)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return giveUp(ctx.Err())
+		case <-timer.C:
+		}
+		if interval *= 2; interval > o.maxInterval {
+			interval = o.maxInterval
+		}
+	}
+}
+
+func (s *SwapOutcome) fill(r *TransactionStatusResult) {
+	s.Result = r
+	s.Type = r.Type()
+	s.GasUsed = r.GasUsed
+	s.GasPrice = r.GasPrice
+	s.Fee = r.TxFee
+	if r.GasUsed.IsSet() && r.GasPrice.IsSet() {
+		if fee := new(big.Int).Mul(r.GasUsed.Big(), r.GasPrice.Big()); fee.BitLen() <= 256 {
+			s.GasFee = types.NewUint256(fee)
+		}
+	}
+}
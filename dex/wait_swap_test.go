@@ -0,0 +1,119 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/internal/transporttest"
+)
+
+const testTxHash = "0xda47c2f450a4f9d538d86d600d55149afd39d6672fdd1f30c68ad5be21cadad8"
+
+func TestWaitForSwap(t *testing.T) {
+	tr := transporttest.New(nil)
+	polls := 0
+	tr.Handle("/api/v5/dex/aggregator/history", func(*transporttest.Request) (string, error) {
+		polls++
+		switch polls {
+		case 1:
+			return `null`, nil
+		case 2:
+			return `{"status":"pending","txType":"Swap"}`, nil
+		}
+		return `{"status":"success","txType":"Swap","gasUsed":"150000","gasPrice":"20000000000","txFee":"0.003"}`, nil
+	})
+
+	outcome, err := NewDexAPI(tr).WaitForSwap(context.Background(), chains.Ethereum, testTxHash,
+		WithWaitBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Status != SwapSuccess || outcome.Type != SwapTxSwap || outcome.Polls != 3 {
+		t.Fatalf("outcome = %+v", outcome)
+	}
+	if outcome.GasFee.String() != "3000000000000000" || outcome.Fee.Text() != "0.003" {
+		t.Errorf("gas fee %s, fee %s", outcome.GasFee, outcome.Fee.Text())
+	}
+}
+
+func TestWaitForSwapNotIndexed(t *testing.T) {
+	tr := transporttest.New(map[string]string{"/api/v5/dex/aggregator/history": `null`})
+	outcome, err := NewDexAPI(tr).WaitForSwap(context.Background(), chains.Ethereum, testTxHash,
+		WithWaitBackoff(time.Millisecond, 2*time.Millisecond), WithIndexTimeout(10*time.Millisecond))
+	if !errors.Is(err, ErrSwapNotIndexed) || outcome.Status != SwapNotIndexed || outcome.Polls < 2 {
+		t.Fatalf("WaitForSwap = %+v, %v", outcome, err)
+	}
+
+	// a failed swap is an outcome, not an error.
+	tr.Set("/api/v5/dex/aggregator/history", `{"status":"fail","txType":"Swap"}`)
+	outcome, err = NewDexAPI(tr).WaitForSwap(context.Background(), chains.Ethereum, testTxHash)
+	if err != nil || outcome.Status != SwapFail || outcome.GasFee.IsSet() {
+		t.Fatalf("WaitForSwap = %+v, %v", outcome, err)
+	}
+}
+
+func TestWaitForSwapRetry(t *testing.T) {
+	tr := transporttest.New(nil)
+	polls := 0
+	tr.Handle("/api/v5/dex/aggregator/history", func(*transporttest.Request) (string, error) {
+		polls++
+		if polls < 3 {
+			return "", errcode.New(50011, "Too Many Requests")
+		}
+		return `{"status":"success","txType":"Swap"}`, nil
+	})
+	outcome, err := NewDexAPI(tr).WaitForSwap(context.Background(), chains.Ethereum, testTxHash,
+		WithWaitBackoff(time.Millisecond, time.Millisecond))
+	if err != nil || outcome.Status != SwapSuccess || outcome.Polls != 3 {
+		t.Fatalf("WaitForSwap = %+v, %v", outcome, err)
+	}
+
+	// the wait gives up with the last poll error.
+	tr.Handle("/api/v5/dex/aggregator/history", func(*transporttest.Request) (string, error) {
+		return "", errcode.New(50011, "Too Many Requests")
+	})
+	_, err = NewDexAPI(tr).WaitForSwap(context.Background(), chains.Ethereum, testTxHash,
+		WithWaitBackoff(time.Millisecond, time.Millisecond), WithIndexTimeout(5*time.Millisecond))
+	if !errors.Is(err, ErrSwapNotIndexed) || !strings.Contains(err.Error(), "Too Many Requests") {
+		t.Fatalf("WaitForSwap = %v", err)
+	}
+
+	// validation errors are not retried.
+	outcome, err = NewDexAPI(tr).WaitForSwap(context.Background(), chains.Ethereum, "")
+	if !errcode.IsValidationError(err) || outcome.Polls != 1 {
+		t.Fatalf("WaitForSwap without hash = %+v, %v", outcome, err)
+	}
+}
+
+func TestWaitBackoffDefaults(t *testing.T) {
+	// a zero interval would never grow, and poll in a tight loop.
+	o := WaitOptions{pollInterval: 2 * time.Second, maxInterval: 20 * time.Second}
+	WithWaitBackoff(0, -1).apply(&o)
+	if o.pollInterval != 2*time.Second || o.maxInterval != 20*time.Second {
+		t.Errorf("backoff = %s, %s, want the defaults", o.pollInterval, o.maxInterval)
+	}
+}
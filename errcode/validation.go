@@ -0,0 +1,148 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes why a single request field is invalid.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+// ValidationError is returned before a request is sent when one or more of its fields are invalid.
+type ValidationError struct {
+	Fields []*FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		reasons = append(reasons, f.Error())
+	}
+	return "invalid request: " + strings.Join(reasons, "; ")
+}
+
+// Add records that field is invalid for the given reason.
+func (e *ValidationError) Add(field, reason string) {
+	e.Fields = append(e.Fields, &FieldError{Field: field, Reason: reason})
+}
+
+// Addf is like Add but formats the reason.
+func (e *ValidationError) Addf(field, format string, args ...any) {
+	e.Add(field, fmt.Sprintf(format, args...))
+}
+
+// Required records an error when value is empty.
+func (e *ValidationError) Required(field, value string) {
+	if value == "" {
+		e.Add(field, "is required")
+	}
+}
+
+// Amount records an error when value is not a positive integer in minimal divisible units.
+func (e *ValidationError) Amount(field, value string) {
+	if n := e.amount(field, value); n != nil && n.Sign() == 0 {
+		e.Add(field, "must be greater than 0")
+	}
+}
+
+// AmountOrZero is like Amount but accepts 0, e.g. to revoke an allowance.
+func (e *ValidationError) AmountOrZero(field, value string) {
+	e.amount(field, value)
+}
+
+func (e *ValidationError) amount(field, value string) *big.Int {
+	if value == "" {
+		e.Add(field, "is required")
+		return nil
+	}
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		e.Add(field, "must be an integer in minimal divisible units")
+		return nil
+	}
+	if n.Sign() < 0 {
+		e.Add(field, "must not be negative")
+		return nil
+	}
+	return n
+}
+
+// Range records an error when value is set but is not a number between min and max.
+func (e *ValidationError) Range(field, value string, min, max float64) {
+	if value == "" {
+		return
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		e.Add(field, "must be a number")
+		return
+	}
+	if f < min || f > max {
+		e.Addf(field, "must be between %s and %s", strconv.FormatFloat(min, 'f', -1, 64), strconv.FormatFloat(max, 'f', -1, 64))
+	}
+}
+
+// OneOf records an error when value is set but is not one of the allowed values.
+func (e *ValidationError) OneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, v := range allowed {
+		if v == value {
+			return
+		}
+	}
+	e.Addf(field, "must be one of %s", strings.Join(allowed, ", "))
+}
+
+// Err returns e if any field is invalid, and nil otherwise.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// AsValidationError returns the *ValidationError in err's chain, or nil if there is none.
+func AsValidationError(err error) *ValidationError {
+	var e *ValidationError
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+// IsValidationError reports whether err was caused by an invalid request.
+func IsValidationError(err error) bool {
+	return AsValidationError(err) != nil
+}
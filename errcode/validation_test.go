@@ -0,0 +1,59 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package errcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	v := new(ValidationError)
+	if v.Err() != nil {
+		t.Fatal("expected no error for an empty ValidationError")
+	}
+
+	v.Required("chainId", "")
+	v.Amount("amount", "1.5")
+	v.Range("slippage", "2", 0, 1)
+	v.Range("feePercent", "", 0, 3)
+	v.OneOf("gasLevel", "fast", "slow", "average", "fast")
+	v.AmountOrZero("approveAmount", "0")
+	v.Range("slippage", "NaN", 0, 1)
+	v.Range("slippage", "Inf", 0, 1)
+
+	err := fmt.Errorf("wrap: %w", v.Err())
+	e := AsValidationError(err)
+	if e == nil {
+		t.Fatal("expected a *ValidationError")
+	}
+	want := []string{"chainId", "amount", "slippage", "slippage", "slippage"}
+	if len(e.Fields) != len(want) {
+		t.Fatalf("got %d field errors, want %d: %v", len(e.Fields), len(want), e)
+	}
+	for i, field := range want {
+		if e.Fields[i].Field != field {
+			t.Errorf("Fields[%d] = %q, want %q", i, e.Fields[i].Field, field)
+		}
+	}
+	if got := e.Error(); got != "invalid request: chainId is required; amount must be an integer in minimal divisible units; slippage must be between 0 and 1; slippage must be a number; slippage must be a number" {
+		t.Errorf("unexpected message: %s", got)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+type BuildOptions struct {
+	nonce  *uint64
+	legacy bool
+	fees   *FeeEngine
+}
+
+type BuildOption interface {
+	apply(o *BuildOptions)
+}
+
+type buildOptionFunc func(o *BuildOptions)
+
+func (f buildOptionFunc) apply(o *BuildOptions) {
+	f(o)
+}
+
+// WithNonce uses nonce instead of the nonce of the sign info.
+func WithNonce(nonce uint64) BuildOption {
+	return buildOptionFunc(func(o *BuildOptions) {
+		o.nonce = &nonce
+	})
+}
+
+// WithLegacy builds a legacy transaction even if the chain supports EIP-1559.
+func WithLegacy() BuildOption {
+	return buildOptionFunc(func(o *BuildOptions) {
+		o.legacy = true
+	})
+}
+
+// WithFeeEngine prices the transaction and scales its gas limit with engine, from the gas
+// prices of the sign info, instead of using the fees returned by the DEX API. The policy of
+// the engine, not WithLegacy, decides the transaction type.
+func WithFeeEngine(engine *FeeEngine) BuildOption {
+	return buildOptionFunc(func(o *BuildOptions) {
+		o.fees = engine
+	})
+}
+
+// BuildTransaction merges a swap or approve transaction returned by the DEX API with the sign
+// info of the chain into an unsigned transaction. info may be nil if the nonce is given with
+// WithNonce and tx carries the gas limit and price.
+//
+// Fields set on tx take precedence over info. An EIP-1559 transaction is built when tx has a
+// priority fee or the chain supports EIP-1559; its max fee is twice the base fee plus the
+// priority fee, or the gas price when the base fee is unknown.
+func BuildTransaction(chainId chains.ChainID, tx *dex.Tx, info *wallet.SignInfoEvm, opts ...BuildOption) (*Transaction, error) {
+	var o BuildOptions
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	id, err := chainId.Int64()
+	if err != nil || (chainId.Family() != chains.FamilyUnknown && !chainId.IsEVM()) {
+		return nil, fmt.Errorf("evm: chain %q is not an EVM chain", chainId)
+	}
+	out := &Transaction{ChainID: big.NewInt(id), Value: tx.Value.Big()}
+
+	if tx.To != "" {
+		to, err := ParseAddress(tx.To)
+		if err != nil {
+			return nil, err
+		}
+		out.To = &to
+	}
+	if out.Data, err = decodeHex(tx.Data); err != nil {
+		return nil, fmt.Errorf("evm: invalid data: %w", err)
+	}
+
+	switch {
+	case o.nonce != nil:
+		out.Nonce = *o.nonce
+	case info != nil && info.Nonce >= 0:
+		out.Nonce = uint64(info.Nonce)
+	default:
+		return nil, fmt.Errorf("%w: no nonce", ErrIncompleteTransaction)
+	}
+
+	gas := tx.Gas
+	if gas.IsZero() && info != nil {
+		gas = info.GasLimit
+	}
+	var ok bool
+	if out.Gas, ok = gas.Uint64(); !ok || out.Gas == 0 {
+		return nil, fmt.Errorf("%w: no gas limit", ErrIncompleteTransaction)
+	}
+
+	var price *wallet.GasPrice
+	if info != nil {
+		price = info.GasPrice
+	}
+	if o.fees != nil {
+		fees, err := o.fees.Fees(chainId, price)
+		if err != nil {
+			return nil, err
+		}
+		fees.Apply(out)
+		out.Gas = o.fees.GasLimit(out.Gas)
+		return out, nil
+	}
+	gasPrice := firstSet(tx.GasPrice, normalPrice(price))
+
+	if !o.legacy && (tx.MaxPriorityFeePerGas.IsSet() || supportsEip1559(price)) {
+		out.Type = DynamicFeeTxType
+		var base types.Uint256
+		priority := tx.MaxPriorityFeePerGas
+		if supportsEip1559(price) {
+			p := price.Eip1559Protocol
+			base = p.BaseFee
+			priority = firstSet(priority, p.ProposePriorityFee, p.SafePriorityFee)
+		}
+		out.MaxPriorityFeePerGas = priority.Big()
+
+		if base.IsSet() {
+			out.MaxFeePerGas = new(big.Int).Lsh(base.Big(), 1)
+			out.MaxFeePerGas.Add(out.MaxFeePerGas, out.MaxPriorityFeePerGas)
+		} else if gasPrice.IsSet() {
+			out.MaxFeePerGas = gasPrice.Big()
+		} else {
+			return nil, fmt.Errorf("%w: no base fee or gas price", ErrIncompleteTransaction)
+		}
+		if out.MaxFeePerGas.Cmp(out.MaxPriorityFeePerGas) < 0 {
+			out.MaxFeePerGas.Set(out.MaxPriorityFeePerGas)
+		}
+		return out, nil
+	}
+
+	if !gasPrice.IsSet() {
+		return nil, fmt.Errorf("%w: no gas price", ErrIncompleteTransaction)
+	}
+	out.Type = LegacyTxType
+	out.GasPrice = gasPrice.Big()
+	return out, nil
+}
+
+func supportsEip1559(price *wallet.GasPrice) bool {
+	return price != nil && price.SupportedEip1559 && price.Eip1559Protocol != nil
+}
+
+func normalPrice(price *wallet.GasPrice) types.Uint256 {
+	if price == nil {
+		return types.Uint256{}
+	}
+	return price.Normal
+}
+
+func firstSet(values ...types.Uint256) types.Uint256 {
+	for _, v := range values {
+		if v.IsSet() {
+			return v
+		}
+	}
+	return types.Uint256{}
+}
+
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	return hex.DecodeString(s)
+}
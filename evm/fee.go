@@ -0,0 +1,263 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+var ErrFeeCapExceeded = errors.New("evm: fee cap exceeded")
+
+// GasLevel is a named fee level, with the values of dex.GetSwapTxRequest.GasLevel.
+type GasLevel string
+
+const (
+	// GasSlow pays the minimum gas price or the safe priority fee.
+	GasSlow GasLevel = "slow"
+	// GasAverage pays the normal gas price or the proposed priority fee.
+	GasAverage GasLevel = "average"
+	// GasFast pays the maximum gas price or the fast priority fee.
+	GasFast GasLevel = "fast"
+)
+
+// FeePolicy configures a FeeEngine. The zero value pays average fees without caps.
+type FeePolicy struct {
+	// Level is the fee level, GasAverage by default.
+	Level GasLevel
+	// MaxFeePerGas caps the max fee per gas, or the gas price of legacy transactions, per chain in wei.
+	MaxFeePerGas map[chains.ChainID]types.Uint256
+	// BaseFeeMultiplier is the headroom over the base fee in the max fee per gas, 2 by default.
+	// The max fee bounds what may be paid if the base fee rises, the transaction pays the actual base fee.
+	BaseFeeMultiplier float64
+	// GasLimitMultiplier scales the estimated gas limit, 1 by default.
+	GasLimitMultiplier float64
+	// BumpPercent is the minimum increase of the fees of a replacement transaction, 10 by default,
+	// the minimum accepted by most nodes.
+	BumpPercent int
+	// Legacy sends legacy transactions even on chains that support EIP-1559.
+	Legacy bool
+}
+
+// Fees are the fee fields of a transaction.
+type Fees struct {
+	Level GasLevel
+	Type  TxType
+	// GasPrice is set for legacy transactions.
+	GasPrice *big.Int
+	// MaxFeePerGas and MaxPriorityFeePerGas are set for EIP-1559 transactions.
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// FeesOf returns the fees of tx, such as a pending transaction to replace.
+func FeesOf(tx *Transaction) Fees {
+	return Fees{
+		Type:                 tx.Type,
+		GasPrice:             copyBig(tx.GasPrice),
+		MaxFeePerGas:         copyBig(tx.MaxFeePerGas),
+		MaxPriorityFeePerGas: copyBig(tx.MaxPriorityFeePerGas),
+	}
+}
+
+// Apply sets the type and fees of tx.
+func (f Fees) Apply(tx *Transaction) {
+	tx.Type = f.Type
+	tx.GasPrice = copyBig(f.GasPrice)
+	tx.MaxFeePerGas = copyBig(f.MaxFeePerGas)
+	tx.MaxPriorityFeePerGas = copyBig(f.MaxPriorityFeePerGas)
+}
+
+// FeeEngine prices transactions from the gas prices of wallet.GetSignInfo according to a policy.
+type FeeEngine struct {
+	policy FeePolicy
+}
+
+// NewFeeEngine returns a FeeEngine for policy.
+func NewFeeEngine(policy FeePolicy) *FeeEngine {
+	if policy.Level == "" {
+		policy.Level = GasAverage
+	}
+	if policy.BaseFeeMultiplier <= 0 {
+		policy.BaseFeeMultiplier = 2
+	}
+	if policy.GasLimitMultiplier <= 0 {
+		policy.GasLimitMultiplier = 1
+	}
+	if policy.BumpPercent < 10 {
+		policy.BumpPercent = 10
+	}
+	return &FeeEngine{policy: policy}
+}
+
+// Fees returns the fees of a transaction on chainId given its gas prices. The fees are lowered
+// to the cap of the chain, and it fails with ErrFeeCapExceeded if the base fee alone is above it.
+func (e *FeeEngine) Fees(chainId chains.ChainID, price *wallet.GasPrice) (Fees, error) {
+	if price == nil {
+		return Fees{}, fmt.Errorf("%w: no gas price", ErrIncompleteTransaction)
+	}
+	p := e.policy
+	fees := Fees{Level: p.Level}
+	maxFee, capped := p.MaxFeePerGas[chainId]
+
+	if !p.Legacy && supportsEip1559(price) {
+		proto := price.Eip1559Protocol
+		var tip types.Uint256
+		switch p.Level {
+		case GasSlow:
+			tip = firstSet(proto.SafePriorityFee, proto.ProposePriorityFee)
+		case GasFast:
+			tip = firstSet(proto.FastPriorityFee, proto.ProposePriorityFee)
+		default:
+			tip = firstSet(proto.ProposePriorityFee, proto.SafePriorityFee)
+		}
+		fees.Type = DynamicFeeTxType
+		fees.MaxPriorityFeePerGas = tip.Big()
+		fees.MaxFeePerGas = new(big.Int).Add(mulFloat(proto.BaseFee.Big(), p.BaseFeeMultiplier), fees.MaxPriorityFeePerGas)
+		if capped {
+			limit := maxFee.Big()
+			if proto.BaseFee.Big().Cmp(limit) > 0 {
+				return Fees{}, fmt.Errorf("%w: base fee %s above cap %s on chain %s", ErrFeeCapExceeded, proto.BaseFee, maxFee, chainId)
+			}
+			if fees.MaxFeePerGas.Cmp(limit) > 0 {
+				fees.MaxFeePerGas = limit
+			}
+			if fees.MaxPriorityFeePerGas.Cmp(limit) > 0 {
+				fees.MaxPriorityFeePerGas = new(big.Int).Set(limit)
+			}
+		}
+		return fees, nil
+	}
+
+	var gasPrice types.Uint256
+	switch p.Level {
+	case GasSlow:
+		gasPrice = firstSet(price.Min, price.Normal)
+	case GasFast:
+		gasPrice = firstSet(price.Max, price.Normal)
+	default:
+		gasPrice = price.Normal
+	}
+	if !gasPrice.IsSet() {
+		return Fees{}, fmt.Errorf("%w: no gas price", ErrIncompleteTransaction)
+	}
+	fees.Type = LegacyTxType
+	fees.GasPrice = gasPrice.Big()
+	if capped && fees.GasPrice.Cmp(maxFee.Big()) > 0 {
+		fees.GasPrice = maxFee.Big()
+	}
+	return fees, nil
+}
+
+// GasLimit returns the estimated gas limit scaled by the policy's multiplier, rounded up.
+func (e *FeeEngine) GasLimit(estimate uint64) uint64 {
+	if e.policy.GasLimitMultiplier == 1 {
+		return estimate
+	}
+	return uint64(math.Ceil(float64(estimate) * e.policy.GasLimitMultiplier))
+}
+
+// ApplySwap sets the gas level and, if estimate is positive, the gas limit of a swap request.
+func (e *FeeEngine) ApplySwap(req *dex.GetSwapTxRequest, estimate uint64) {
+	req.GasLevel = string(e.policy.Level)
+	if estimate > 0 {
+		req.Gaslimit = strconv.FormatUint(e.GasLimit(estimate), 10)
+	}
+}
+
+// Bump returns the fees of a transaction replacing one sent with prev: the current fees, raised
+// to at least BumpPercent above prev. It fails with ErrFeeCapExceeded if the cap of the chain
+// does not leave room for the bump.
+func (e *FeeEngine) Bump(chainId chains.ChainID, prev Fees, price *wallet.GasPrice) (Fees, error) {
+	fees, err := e.Fees(chainId, price)
+	if err != nil {
+		return Fees{}, err
+	}
+	// A replacement keeps the type of the replaced transaction.
+	if prev.Type != fees.Type {
+		if prev.Type == LegacyTxType {
+			fees = Fees{Level: fees.Level, Type: LegacyTxType, GasPrice: copyBig(fees.MaxFeePerGas)}
+		} else {
+			fees = Fees{Level: fees.Level, Type: DynamicFeeTxType, MaxFeePerGas: fees.GasPrice, MaxPriorityFeePerGas: copyBig(fees.GasPrice)}
+		}
+	}
+
+	bump := func(current, previous *big.Int) *big.Int {
+		floor := bumped(previous, e.policy.BumpPercent)
+		if current == nil || current.Cmp(floor) < 0 {
+			return floor
+		}
+		return current
+	}
+	if fees.Type == LegacyTxType {
+		fees.GasPrice = bump(fees.GasPrice, prev.GasPrice)
+	} else {
+		fees.MaxPriorityFeePerGas = bump(fees.MaxPriorityFeePerGas, prev.MaxPriorityFeePerGas)
+		fees.MaxFeePerGas = bump(fees.MaxFeePerGas, prev.MaxFeePerGas)
+		if fees.MaxFeePerGas.Cmp(fees.MaxPriorityFeePerGas) < 0 {
+			fees.MaxFeePerGas = new(big.Int).Set(fees.MaxPriorityFeePerGas)
+		}
+	}
+
+	if maxFee, ok := e.policy.MaxFeePerGas[chainId]; ok {
+		top := fees.GasPrice
+		if fees.Type == DynamicFeeTxType {
+			top = fees.MaxFeePerGas
+		}
+		if top.Cmp(maxFee.Big()) > 0 {
+			return Fees{}, fmt.Errorf("%w: replacement needs %s, cap is %s on chain %s", ErrFeeCapExceeded, top, maxFee, chainId)
+		}
+	}
+	return fees, nil
+}
+
+// bumped returns n raised by percent, rounded up.
+func bumped(n *big.Int, percent int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	b := new(big.Int).Mul(n, big.NewInt(int64(100+percent)))
+	b.Add(b, big.NewInt(99))
+	return b.Div(b, big.NewInt(100))
+}
+
+// mulFloat returns n * f, rounded up.
+func mulFloat(n *big.Int, f float64) *big.Int {
+	r, acc := new(big.Float).Mul(new(big.Float).SetInt(n), big.NewFloat(f)).Int(nil)
+	if acc == big.Below {
+		r.Add(r, big.NewInt(1))
+	}
+	return r
+}
+
+func copyBig(n *big.Int) *big.Int {
+	if n == nil {
+		return nil
+	}
+	return new(big.Int).Set(n)
+}
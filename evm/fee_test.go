@@ -0,0 +1,86 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+func TestFeeEngine(t *testing.T) {
+	price := &wallet.GasPrice{
+		Normal:           types.Uint256FromUint64(120),
+		SupportedEip1559: true,
+		Eip1559Protocol: &wallet.Eip1559Protocol{
+			BaseFee:            types.Uint256FromUint64(100),
+			SafePriorityFee:    types.Uint256FromUint64(1),
+			ProposePriorityFee: types.Uint256FromUint64(2),
+			FastPriorityFee:    types.Uint256FromUint64(5),
+		},
+	}
+	e := NewFeeEngine(FeePolicy{
+		Level:              GasFast,
+		MaxFeePerGas:       map[chains.ChainID]types.Uint256{"10": types.Uint256FromUint64(150)},
+		GasLimitMultiplier: 1.25,
+	})
+
+	fees, err := e.Fees("1", price)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.Type != DynamicFeeTxType || fees.MaxPriorityFeePerGas.Int64() != 5 || fees.MaxFeePerGas.Int64() != 205 {
+		t.Fatalf("fees = %+v", fees)
+	}
+	if fees, _ = e.Fees("10", price); fees.MaxFeePerGas.Int64() != 150 {
+		t.Fatalf("capped max fee = %s", fees.MaxFeePerGas)
+	}
+
+	tx, err := BuildTransaction("1", &dex.Tx{To: "0x3535353535353535353535353535353535353535", Gas: types.Uint256FromUint64(100000)},
+		&wallet.SignInfoEvm{Nonce: 1, GasPrice: price}, WithFeeEngine(e))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Gas != 125000 || tx.MaxFeePerGas.Int64() != 205 {
+		t.Fatalf("tx = %+v", tx)
+	}
+
+	var req dex.GetSwapTxRequest
+	e.ApplySwap(&req, 100000)
+	if req.GasLevel != "fast" || req.Gaslimit != "125000" {
+		t.Fatalf("request = %s / %s", req.GasLevel, req.Gaslimit)
+	}
+
+	// The current fees are below the 10% bump of the pending transaction.
+	bumped, err := e.Bump("1", FeesOf(tx), price)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bumped.MaxPriorityFeePerGas.Int64() != 6 || bumped.MaxFeePerGas.Int64() != 226 {
+		t.Fatalf("bumped = %+v", bumped)
+	}
+	if _, err := e.Bump("10", FeesOf(tx), price); !errors.Is(err, ErrFeeCapExceeded) {
+		t.Fatalf("bump above cap error = %v", err)
+	}
+}
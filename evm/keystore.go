@@ -0,0 +1,149 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/imzhongqi/okxos/internal/kdf"
+	"github.com/imzhongqi/okxos/internal/keccak"
+)
+
+var (
+	ErrInvalidPassword     = errors.New("evm: invalid keystore password")
+	ErrUnsupportedKeystore = errors.New("evm: unsupported keystore")
+)
+
+// keystore is a version 3 Web3 Secret Storage file.
+type keystore struct {
+	Address string `json:"address"`
+	Version int    `json:"version"`
+	Crypto  struct {
+		Cipher       string `json:"cipher"`
+		CipherText   string `json:"ciphertext"`
+		CipherParams struct {
+			IV string `json:"iv"`
+		} `json:"cipherparams"`
+		KDF       string `json:"kdf"`
+		KDFParams struct {
+			// scrypt
+			N int `json:"n"`
+			R int `json:"r"`
+			P int `json:"p"`
+			// pbkdf2
+			C   int    `json:"c"`
+			PRF string `json:"prf"`
+
+			DKLen int    `json:"dklen"`
+			Salt  string `json:"salt"`
+		} `json:"kdfparams"`
+		MAC string `json:"mac"`
+	} `json:"crypto"`
+}
+
+// DecryptKeystore decrypts a version 3 keystore file, as written by geth and most wallets,
+// with scrypt or pbkdf2 key derivation.
+func DecryptKeystore(data []byte, password string) (*PrivateKey, error) {
+	var ks keystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedKeystore, err)
+	}
+	c := &ks.Crypto
+	if ks.Version != 3 || c.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("%w: version %d, cipher %q", ErrUnsupportedKeystore, ks.Version, c.Cipher)
+	}
+
+	salt, err := hex.DecodeString(c.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid salt", ErrUnsupportedKeystore)
+	}
+	if c.KDFParams.DKLen < 32 {
+		return nil, fmt.Errorf("%w: dklen %d", ErrUnsupportedKeystore, c.KDFParams.DKLen)
+	}
+
+	var key []byte
+	switch c.KDF {
+	case "scrypt":
+		p := c.KDFParams
+		if key, err = kdf.Scrypt([]byte(password), salt, p.N, p.R, p.P, p.DKLen); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedKeystore, err)
+		}
+	case "pbkdf2":
+		if c.KDFParams.PRF != "hmac-sha256" || c.KDFParams.C <= 0 {
+			return nil, fmt.Errorf("%w: prf %q", ErrUnsupportedKeystore, c.KDFParams.PRF)
+		}
+		key = kdf.PBKDF2([]byte(password), salt, c.KDFParams.C, c.KDFParams.DKLen, sha256.New)
+	default:
+		return nil, fmt.Errorf("%w: kdf %q", ErrUnsupportedKeystore, c.KDF)
+	}
+
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ciphertext", ErrUnsupportedKeystore)
+	}
+	mac, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid mac", ErrUnsupportedKeystore)
+	}
+	want := keccak.Sum256(key[16:32], cipherText)
+	if subtle.ConstantTimeCompare(mac, want[:]) != 1 {
+		return nil, ErrInvalidPassword
+	}
+
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("%w: invalid iv", ErrUnsupportedKeystore)
+	}
+	block, err := aes.NewCipher(key[:16])
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(cipherText))
+	cipher.NewCTR(block, iv).XORKeyStream(plain, cipherText)
+
+	pk, err := NewPrivateKey(plain)
+	if err != nil {
+		return nil, err
+	}
+	if ks.Address != "" {
+		if addr, err := ParseAddress("0x" + strings.TrimPrefix(ks.Address, "0x")); err == nil && addr != pk.Address() {
+			return nil, fmt.Errorf("%w: key does not match address %s", ErrUnsupportedKeystore, ks.Address)
+		}
+	}
+	return pk, nil
+}
+
+// LoadKeystore reads and decrypts the keystore file at path.
+func LoadKeystore(path, password string) (*PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKeystore(data, password)
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+var ErrLeaseDone = errors.New("evm: nonce lease already committed or released")
+
+// NonceSource reports the nonces of an address. *wallet.WalletAPI implements it.
+type NonceSource interface {
+	GetNonce(ctx context.Context, req *wallet.GetNonceRequest) (*wallet.GetNonceResult, error)
+}
+
+// NonceAccount is the persisted lease state of an address.
+type NonceAccount struct {
+	ChainID chains.ChainID `json:"chainId"`
+	Address string         `json:"address"`
+	// Next is the nonce leased when no released nonce is available.
+	Next uint64 `json:"next"`
+	// Leased are the nonces handed out and not yet committed or released.
+	Leased []uint64 `json:"leased,omitempty"`
+	// Released are the nonces below Next that are free again; they are leased first.
+	Released []uint64 `json:"released,omitempty"`
+	// Committed maps the nonces of broadcast transactions that are not confirmed yet to the
+	// time of the broadcast.
+	Committed map[uint64]time.Time `json:"committed,omitempty"`
+}
+
+// NonceStore persists the state of a NonceManager, so a restart does not lease nonces that are
+// already in flight.
+type NonceStore interface {
+	LoadNonces(ctx context.Context) ([]NonceAccount, error)
+	SaveNonces(ctx context.Context, accounts []NonceAccount) error
+}
+
+// FileNonceStore stores the nonce state as JSON in a file, replaced atomically on save.
+type FileNonceStore string
+
+func (f FileNonceStore) LoadNonces(ctx context.Context) ([]NonceAccount, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var accounts []NonceAccount
+	err = json.Unmarshal(data, &accounts)
+	return accounts, err
+}
+
+func (f FileNonceStore) SaveNonces(ctx context.Context, accounts []NonceAccount) error {
+	data, err := json.Marshal(accounts)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+type NonceOptions struct {
+	store      NonceStore
+	gapTimeout time.Duration
+}
+
+type NonceOption interface {
+	apply(o *NonceOptions)
+}
+
+type nonceOptionFunc func(o *NonceOptions)
+
+func (f nonceOptionFunc) apply(o *NonceOptions) {
+	f(o)
+}
+
+// WithNonceStore persists the leases in store.
+func WithNonceStore(store NonceStore) NonceOption {
+	return nonceOptionFunc(func(o *NonceOptions) {
+		o.store = store
+	})
+}
+
+// WithGapTimeout sets how long a committed transaction may be missing from the pending nonce
+// of the node before Reconcile reports it as a gap, 1m by default.
+func WithGapTimeout(d time.Duration) NonceOption {
+	return nonceOptionFunc(func(o *NonceOptions) {
+		o.gapTimeout = d
+	})
+}
+
+type nonceKey struct {
+	chainId chains.ChainID
+	address string
+}
+
+type nonceAccount struct {
+	NonceAccount
+	synced bool
+}
+
+// NonceManager leases nonces per chain and address, so concurrent transactions of an address
+// do not collide. A lease is committed once its transaction is broadcast, or released if the
+// broadcast failed so the nonce is reused. It is safe for concurrent use.
+type NonceManager struct {
+	source NonceSource
+	opts   NonceOptions
+
+	mu       sync.Mutex
+	accounts map[nonceKey]*nonceAccount
+}
+
+// NewNonceManager returns a NonceManager that reconciles with source.
+func NewNonceManager(source NonceSource, opts ...NonceOption) *NonceManager {
+	o := NonceOptions{gapTimeout: time.Minute}
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+	return &NonceManager{source: source, opts: o, accounts: make(map[nonceKey]*nonceAccount)}
+}
+
+// Load restores the state saved in the store. The restored accounts are reconciled with the
+// source on their next lease. Leases of the previous process may or may not have been broadcast,
+// so they are restored as committed: Reconcile releases them if they never reach the node.
+func (m *NonceManager) Load(ctx context.Context) error {
+	if m.opts.store == nil {
+		return nil
+	}
+	saved, err := m.opts.store.LoadNonces(ctx)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, a := range saved {
+		if len(a.Leased) > 0 && a.Committed == nil {
+			a.Committed = make(map[uint64]time.Time, len(a.Leased))
+		}
+		for _, nonce := range a.Leased {
+			a.Committed[nonce] = now
+		}
+		a.Leased = nil
+		m.accounts[keyOf(a.ChainID, a.Address)] = &nonceAccount{NonceAccount: a}
+	}
+	return nil
+}
+
+// NonceLease is a nonce handed out by a NonceManager.
+type NonceLease struct {
+	m     *NonceManager
+	key   nonceKey
+	Nonce uint64
+	done  bool
+}
+
+// Lease returns the lowest free nonce of address on chainId. The first lease of an address
+// reconciles with the source.
+func (m *NonceManager) Lease(ctx context.Context, chainId chains.ChainID, address string) (*NonceLease, error) {
+	key := keyOf(chainId, address)
+	m.mu.Lock()
+	a, ok := m.accounts[key]
+	synced := ok && a.synced
+	m.mu.Unlock()
+	if !synced {
+		if _, err := m.Reconcile(ctx, chainId, address); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a = m.accounts[key]
+	var nonce uint64
+	reused := len(a.Released) > 0
+	if reused {
+		nonce, a.Released = a.Released[0], a.Released[1:]
+	} else {
+		nonce = a.Next
+		a.Next++
+	}
+	a.Leased = insertNonce(a.Leased, nonce)
+	if err := m.save(ctx); err != nil {
+		// Undo, the lease was not persisted.
+		a.Leased = removeNonce(a.Leased, nonce)
+		if reused {
+			a.Released = insertNonce(a.Released, nonce)
+		} else {
+			a.Next--
+		}
+		return nil, err
+	}
+	return &NonceLease{m: m, key: key, Nonce: nonce}, nil
+}
+
+// Commit records that the transaction of the lease was broadcast.
+func (l *NonceLease) Commit(ctx context.Context) error {
+	return l.finish(ctx, true)
+}
+
+// Release returns the nonce of the lease, whose transaction did not reach the network, so it
+// is leased again.
+func (l *NonceLease) Release(ctx context.Context) error {
+	return l.finish(ctx, false)
+}
+
+func (l *NonceLease) finish(ctx context.Context, committed bool) error {
+	m := l.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l.done {
+		return ErrLeaseDone
+	}
+	l.done = true
+
+	a := m.accounts[l.key]
+	a.Leased = removeNonce(a.Leased, l.Nonce)
+	if committed {
+		if a.Committed == nil {
+			a.Committed = make(map[uint64]time.Time)
+		}
+		a.Committed[l.Nonce] = time.Now()
+	} else if l.Nonce < a.Next {
+		a.Released = insertNonce(a.Released, l.Nonce)
+	}
+	return m.save(ctx)
+}
+
+// Reconcile aligns the state of address with the nonces reported by the source and returns
+// the gap, if any: the pending nonce of the node, when it was committed longer than the gap
+// timeout ago, as its transaction was dropped. The gap is released, so the next lease fills it.
+// Committed nonces above the gap are kept, since their transactions usually wait in the mempool
+// for the gap to be filled, and are reported once they become the pending nonce themselves.
+func (m *NonceManager) Reconcile(ctx context.Context, chainId chains.ChainID, address string) ([]uint64, error) {
+	n, err := m.source.GetNonce(ctx, &wallet.GetNonceRequest{ChainIndex: chainId, Address: address})
+	if err != nil {
+		return nil, err
+	}
+	confirmed, pending := uint64(n.Nonce.Int64()), uint64(n.PendingNonce.Int64())
+	if pending < confirmed {
+		pending = confirmed
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := keyOf(chainId, address)
+	a, ok := m.accounts[key]
+	if !ok {
+		a = &nonceAccount{NonceAccount: NonceAccount{ChainID: chainId, Address: key.address}}
+		m.accounts[key] = a
+	}
+	a.synced = true
+
+	// Nonces below the confirmed one are used, by us or by another sender.
+	a.Released = dropBelow(a.Released, confirmed)
+	for nonce := range a.Committed {
+		if nonce < confirmed {
+			delete(a.Committed, nonce)
+		}
+	}
+	if a.Next < pending {
+		// Transactions were sent from elsewhere.
+		a.Next = pending
+		a.Released = dropBelow(a.Released, pending)
+	}
+
+	var gaps []uint64
+	if sent, ok := a.Committed[pending]; ok && sent.Before(time.Now().Add(-m.opts.gapTimeout)) {
+		delete(a.Committed, pending)
+		a.Released = insertNonce(a.Released, pending)
+		gaps = append(gaps, pending)
+	}
+	return gaps, m.save(ctx)
+}
+
+// Accounts returns a snapshot of the state of all addresses.
+func (m *NonceManager) Accounts() []NonceAccount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot()
+}
+
+func (m *NonceManager) snapshot() []NonceAccount {
+	out := make([]NonceAccount, 0, len(m.accounts))
+	for _, a := range m.accounts {
+		cpy := a.NonceAccount
+		cpy.Leased = append([]uint64(nil), a.Leased...)
+		cpy.Released = append([]uint64(nil), a.Released...)
+		if len(a.Committed) > 0 {
+			cpy.Committed = make(map[uint64]time.Time, len(a.Committed))
+			for nonce, t := range a.Committed {
+				cpy.Committed[nonce] = t
+			}
+		}
+		out = append(out, cpy)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ChainID != out[j].ChainID {
+			return out[i].ChainID < out[j].ChainID
+		}
+		return out[i].Address < out[j].Address
+	})
+	return out
+}
+
+func (m *NonceManager) save(ctx context.Context) error {
+	if m.opts.store == nil {
+		return nil
+	}
+	return m.opts.store.SaveNonces(ctx, m.snapshot())
+}
+
+func keyOf(chainId chains.ChainID, address string) nonceKey {
+	return nonceKey{chainId: chainId, address: strings.ToLower(address)}
+}
+
+func insertNonce(s []uint64, n uint64) []uint64 {
+	i := sort.Search(len(s), func(i int) bool { return s[i] >= n })
+	if i < len(s) && s[i] == n {
+		return s
+	}
+	s = append(s, 0)
+	copy(s[i+1:], s[i:])
+	s[i] = n
+	return s
+}
+
+func removeNonce(s []uint64, n uint64) []uint64 {
+	i := sort.Search(len(s), func(i int) bool { return s[i] >= n })
+	if i < len(s) && s[i] == n {
+		return append(s[:i], s[i+1:]...)
+	}
+	return s
+}
+
+func dropBelow(s []uint64, n uint64) []uint64 {
+	i := sort.Search(len(s), func(i int) bool { return s[i] >= n })
+	return s[i:]
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+type fakeNonces struct {
+	mu             sync.Mutex
+	nonce, pending int64
+}
+
+func (f *fakeNonces) GetNonce(ctx context.Context, req *wallet.GetNonceRequest) (*wallet.GetNonceResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &wallet.GetNonceResult{Nonce: types.Int(f.nonce), PendingNonce: types.Int(f.pending)}, nil
+}
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	const addr = "0x3535353535353535353535353535353535353535"
+	source := &fakeNonces{nonce: 5, pending: 7}
+	store := FileNonceStore(filepath.Join(t.TempDir(), "nonces.json"))
+	m := NewNonceManager(source, WithNonceStore(store), WithGapTimeout(0))
+
+	var wg sync.WaitGroup
+	leases := make([]*NonceLease, 4)
+	for i := range leases {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l, err := m.Lease(ctx, "1", addr)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			leases[i] = l
+		}(i)
+	}
+	wg.Wait()
+	seen := make(map[uint64]bool)
+	for _, l := range leases {
+		if l.Nonce < 7 || l.Nonce > 10 || seen[l.Nonce] {
+			t.Fatalf("lease %d", l.Nonce)
+		}
+		seen[l.Nonce] = true
+	}
+
+	// A failed broadcast gives the nonce back.
+	var failed *NonceLease
+	for _, l := range leases {
+		if l.Nonce == 8 {
+			failed = l
+		} else if err := l.Commit(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := failed.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := failed.Release(ctx); err != ErrLeaseDone {
+		t.Fatalf("second release error = %v", err)
+	}
+	reused, _ := m.Lease(ctx, "1", addr)
+	if reused.Nonce != 8 {
+		t.Fatalf("reused lease = %d", reused.Nonce)
+	}
+	if err := reused.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 7 is pending and 8 was dropped by the node, while 9 and 10 wait behind it.
+	source.pending = 8
+	gaps, err := m.Reconcile(ctx, "1", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 1 || gaps[0] != 8 {
+		t.Fatalf("gaps = %v", gaps)
+	}
+
+	// A restarted manager fills the gap without replacing the queued 9 and 10.
+	restarted := NewNonceManager(source, WithNonceStore(store))
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []uint64{8, 11} {
+		if l, _ := restarted.Lease(ctx, "1", addr); l.Nonce != want {
+			t.Fatalf("lease after restart = %d, want %d", l.Nonce, want)
+		}
+	}
+}
+
+func TestSwapNonceLeaser(t *testing.T) {
+	ctx := context.Background()
+	const addr = "0x3535353535353535353535353535353535353535"
+	source := &fakeNonces{nonce: 5, pending: 5}
+	l := SwapNonceLeaser(NewNonceManager(source))
+
+	first, err := l.Lease(ctx, "1", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	again, err := l.Lease(ctx, "1", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Nonce() != 5 || again.Nonce() != 5 {
+		t.Fatalf("leases %d, %d, want 5, 5", first.Nonce(), again.Nonce())
+	}
+	if err := again.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// another sender used nonces up to 8.
+	source.nonce, source.pending = 9, 9
+	if err := l.Reconcile(ctx, "1", addr); err != nil {
+		t.Fatal(err)
+	}
+	next, err := l.Lease(ctx, "1", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Nonce() != 9 {
+		t.Errorf("lease after reconcile %d, want 9", next.Nonce())
+	}
+}
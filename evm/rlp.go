@@ -0,0 +1,64 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import "math/big"
+
+// rlpString encodes b as an RLP string.
+func rlpString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+// rlpUint encodes n as an RLP integer, big-endian without leading zeros.
+func rlpUint(n uint64) []byte {
+	return rlpString(new(big.Int).SetUint64(n).Bytes())
+}
+
+// rlpBig encodes n as an RLP integer. A nil n encodes as zero.
+func rlpBig(n *big.Int) []byte {
+	if n == nil {
+		return rlpString(nil)
+	}
+	return rlpString(n.Bytes())
+}
+
+// rlpList encodes the already encoded items as an RLP list.
+func rlpList(items ...[]byte) []byte {
+	var size int
+	for _, item := range items {
+		size += len(item)
+	}
+	b := rlpHeader(0xc0, size)
+	for _, item := range items {
+		b = append(b, item...)
+	}
+	return b
+}
+
+func rlpHeader(offset byte, size int) []byte {
+	if size < 56 {
+		return []byte{offset + byte(size)}
+	}
+	n := new(big.Int).SetInt64(int64(size)).Bytes()
+	return append([]byte{offset + 55 + byte(len(n))}, n...)
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/internal/keccak"
+	"github.com/imzhongqi/okxos/internal/secp256k1"
+	"github.com/imzhongqi/okxos/swap"
+)
+
+// Signer signs hashes with the key of a single address. PrivateKey is the local implementation;
+// keys held by an HSM or a KMS can be used by implementing this interface.
+type Signer interface {
+	// Address returns the address of the key.
+	Address() Address
+	// SignHash returns the 65 byte [R || S || V] signature of hash, where V is the recovery id 0 or 1.
+	SignHash(ctx context.Context, hash [32]byte) ([]byte, error)
+}
+
+// PrivateKey is a secp256k1 private key held in memory.
+//
+// Signing is not constant time: the duration of a signature depends on the key and the nonce,
+// so an attacker who can time many signatures, e.g. on a shared host or through a signing
+// endpoint, may be able to recover the key. Keep it off such paths, or use an HSM or a KMS
+// through Signer.
+type PrivateKey struct {
+	d    *big.Int
+	addr Address
+}
+
+// NewPrivateKey returns the private key of the 32 byte big-endian scalar b.
+func NewPrivateKey(b []byte) (*PrivateKey, error) {
+	if len(b) != 32 {
+		return nil, secp256k1.ErrInvalidPrivateKey
+	}
+	d := new(big.Int).SetBytes(b)
+	x, y, err := secp256k1.PublicKey(d)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{d: d, addr: pubkeyToAddress(x, y)}, nil
+}
+
+// PrivateKeyFromHex parses a hex private key, with or without the 0x prefix.
+func PrivateKeyFromHex(s string) (*PrivateKey, error) {
+	b, err := decodeHex(strings.TrimSpace(s))
+	if err != nil {
+		return nil, secp256k1.ErrInvalidPrivateKey
+	}
+	return NewPrivateKey(b)
+}
+
+// Address returns the address of the key.
+func (k *PrivateKey) Address() Address {
+	return k.addr
+}
+
+// Bytes returns the 32 byte big-endian scalar of the key.
+func (k *PrivateKey) Bytes() []byte {
+	return k.d.FillBytes(make([]byte, 32))
+}
+
+// SignHash signs hash deterministically, as in RFC 6979.
+func (k *PrivateKey) SignHash(ctx context.Context, hash [32]byte) ([]byte, error) {
+	r, s, recid, err := secp256k1.Sign(k.d, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	if recid > 1 {
+		return nil, fmt.Errorf("%w: recovery id %d", ErrInvalidSignature, recid)
+	}
+	sig := make([]byte, 65)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	sig[64] = recid
+	return sig, nil
+}
+
+// KeyProvider loads a private key on demand, for example from a secret manager.
+type KeyProvider interface {
+	PrivateKey(ctx context.Context) (*PrivateKey, error)
+}
+
+// KeyProviderFunc adapts a function to a KeyProvider.
+type KeyProviderFunc func(ctx context.Context) (*PrivateKey, error)
+
+func (f KeyProviderFunc) PrivateKey(ctx context.Context) (*PrivateKey, error) {
+	return f(ctx)
+}
+
+type providerSigner struct {
+	provider KeyProvider
+	addr     Address
+}
+
+// NewProviderSigner returns a Signer that loads the key from provider for every signature
+// instead of keeping it in memory. The key is loaded once to learn its address, and later
+// signatures fail if the provider returns the key of another address.
+func NewProviderSigner(ctx context.Context, provider KeyProvider) (Signer, error) {
+	key, err := provider.PrivateKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &providerSigner{provider: provider, addr: key.Address()}, nil
+}
+
+func (p *providerSigner) Address() Address {
+	return p.addr
+}
+
+func (p *providerSigner) SignHash(ctx context.Context, hash [32]byte) ([]byte, error) {
+	key, err := p.provider.PrivateKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if key.Address() != p.addr {
+		return nil, fmt.Errorf("evm: key provider returned the key of %s, want %s", key.Address(), p.addr)
+	}
+	return key.SignHash(ctx, hash)
+}
+
+// SignTransaction signs tx with s and returns the signed copy, whose SignedTx is passed to
+// wallet.TransactionBroadcast.
+func SignTransaction(ctx context.Context, s Signer, tx *Transaction) (*Transaction, error) {
+	sig, err := s.SignHash(ctx, tx.SigningHash())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(sig)
+}
+
+// HashMessage returns the EIP-191 hash of a personal message.
+func HashMessage(msg []byte) [32]byte {
+	return keccak.Sum256([]byte("\x19Ethereum Signed Message:\n"+strconv.Itoa(len(msg))), msg)
+}
+
+// SignMessage signs the EIP-191 personal message msg, as personal_sign does. V is 27 or 28.
+func SignMessage(ctx context.Context, s Signer, msg []byte) ([]byte, error) {
+	return signEthereum(ctx, s, HashMessage(msg))
+}
+
+// SignTypedData signs EIP-712 typed data, as eth_signTypedData_v4 does. V is 27 or 28.
+func SignTypedData(ctx context.Context, s Signer, data *TypedData) ([]byte, error) {
+	hash, err := data.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return signEthereum(ctx, s, hash)
+}
+
+func signEthereum(ctx context.Context, s Signer, hash [32]byte) ([]byte, error) {
+	sig, err := s.SignHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidSignature, len(sig))
+	}
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
+
+// RecoverAddress returns the address whose key produced the 65 byte signature sig of hash.
+// V may be 0/1 or 27/28.
+func RecoverAddress(hash [32]byte, sig []byte) (Address, error) {
+	if len(sig) != 65 {
+		return Address{}, fmt.Errorf("%w: length %d", ErrInvalidSignature, len(sig))
+	}
+	recid := sig[64]
+	if recid >= 27 {
+		recid -= 27
+	}
+	x, y, err := secp256k1.Recover(hash[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]), recid)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return pubkeyToAddress(x, y), nil
+}
+
+func pubkeyToAddress(x, y *big.Int) Address {
+	var pub [64]byte
+	x.FillBytes(pub[:32])
+	y.FillBytes(pub[32:])
+	h := keccak.Sum256(pub[:])
+
+	var a Address
+	copy(a[:], h[12:])
+	return a
+}
+
+type swapNonceLeaser struct {
+	m *NonceManager
+}
+
+type swapNonceLease struct {
+	l *NonceLease
+}
+
+// SwapNonceLeaser adapts m to the swap.NonceLeaser used by swap.Executor.
+func SwapNonceLeaser(m *NonceManager) swap.NonceLeaser {
+	return &swapNonceLeaser{m: m}
+}
+
+func (w *swapNonceLeaser) Lease(ctx context.Context, chainId chains.ChainID, address string) (swap.NonceLease, error) {
+	l, err := w.m.Lease(ctx, chainId, address)
+	if err != nil {
+		return nil, err
+	}
+	return &swapNonceLease{l: l}, nil
+}
+
+func (w *swapNonceLeaser) Reconcile(ctx context.Context, chainId chains.ChainID, address string) error {
+	_, err := w.m.Reconcile(ctx, chainId, address)
+	return err
+}
+
+func (w *swapNonceLease) Nonce() uint64 {
+	return w.l.Nonce
+}
+
+func (w *swapNonceLease) Commit(ctx context.Context) error {
+	return w.l.Commit(ctx)
+}
+
+func (w *swapNonceLease) Release(ctx context.Context) error {
+	return w.l.Release(ctx)
+}
+
+type swapSigner struct {
+	s Signer
+}
+
+// SwapSigner adapts s to the swap.Signer used by swap.Executor. A transaction with a priority
+// fee is signed as an EIP-1559 transaction whose max fee is its gas price.
+func SwapSigner(s Signer) swap.Signer {
+	return &swapSigner{s: s}
+}
+
+func (w *swapSigner) Address() string {
+	return w.s.Address().Hex()
+}
+
+func (w *swapSigner) SignTransaction(ctx context.Context, tx *swap.Transaction) (string, string, error) {
+	unsigned, err := BuildTransaction(tx.ChainID, &dex.Tx{
+		To:                   tx.To,
+		Data:                 tx.Data,
+		Value:                tx.Value,
+		Gas:                  tx.Gas,
+		GasPrice:             tx.GasPrice,
+		MaxPriorityFeePerGas: tx.MaxPriorityFeePerGas,
+	}, nil, WithNonce(tx.Nonce))
+	if err != nil {
+		return "", "", err
+	}
+	signed, err := SignTransaction(ctx, w.s, unsigned)
+	if err != nil {
+		return "", "", err
+	}
+	raw, err := signed.SignedTx()
+	if err != nil {
+		return "", "", err
+	}
+	hash, err := signed.Hash()
+	return raw, hash, err
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/imzhongqi/okxos/internal/keccak"
+	"github.com/imzhongqi/okxos/swap"
+	"github.com/imzhongqi/okxos/types"
+)
+
+func TestPrivateKeySignTransaction(t *testing.T) {
+	// The key and signature of the EIP-155 example.
+	key, err := PrivateKeyFromHex("0x4646464646464646464646464646464646464646464646464646464646464646")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := key.Address().Hex(); got != "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F" {
+		t.Fatalf("address = %s", got)
+	}
+
+	signer := SwapSigner(key)
+	raw, hash, err := signer.SignTransaction(context.Background(), &swap.Transaction{
+		ChainID:  "1",
+		Nonce:    9,
+		To:       "0x3535353535353535353535353535353535353535",
+		Value:    types.MustParseUint256("1000000000000000000"),
+		Gas:      types.Uint256FromUint64(21000),
+		GasPrice: types.MustParseUint256("20000000000"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025" +
+		"a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276" +
+		"a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+	if raw != want {
+		t.Fatalf("signed tx = %s", raw)
+	}
+	if hash != "0x33469b22e9f636356c4160a87eb19df52b7412e8eac32a4a55ffe88ea8350788" {
+		t.Fatalf("hash = %s", hash)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	if h := HashMessage([]byte("hello world")); hex.EncodeToString(h[:]) != "d9eba16ed0ecae432b71fe008c98cc872bb4cc214d3220a36f365326cf807d68" {
+		t.Fatalf("HashMessage = %x", h)
+	}
+
+	key, _ := PrivateKeyFromHex("4646464646464646464646464646464646464646464646464646464646464646")
+	signer, err := NewProviderSigner(context.Background(), KeyProviderFunc(func(ctx context.Context) (*PrivateKey, error) {
+		return key, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignMessage(context.Background(), signer, []byte("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		t.Fatalf("v = %d", sig[64])
+	}
+	if addr, err := RecoverAddress(HashMessage([]byte("hello world")), sig); err != nil || addr != key.Address() {
+		t.Fatalf("RecoverAddress = %s, %v", addr, err)
+	}
+}
+
+// The example of EIP-712.
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestSignTypedData(t *testing.T) {
+	var td TypedData
+	if err := json.Unmarshal([]byte(mailTypedData), &td); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := td.EncodeType("Mail"); got != "Mail(Person from,Person to,string contents)Person(string name,address wallet)" {
+		t.Fatalf("EncodeType = %s", got)
+	}
+	hash, err := td.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(hash[:]) != "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Fatalf("Hash = %x", hash)
+	}
+
+	cow := keccak.Sum256([]byte("cow"))
+	key, err := NewPrivateKey(cow[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignTypedData(context.Background(), key, &td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+	if hex.EncodeToString(sig) != want {
+		t.Fatalf("signature = %x", sig)
+	}
+}
+
+func TestDecryptKeystore(t *testing.T) {
+	// The pbkdf2 test vector of the Web3 Secret Storage definition.
+	data := `{
+		"crypto": {
+			"cipher": "aes-128-ctr",
+			"cipherparams": {"iv": "6087dab2f9fdbbfaddc31a909735c1e6"},
+			"ciphertext": "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46",
+			"kdf": "pbkdf2",
+			"kdfparams": {
+				"c": 262144,
+				"dklen": 32,
+				"prf": "hmac-sha256",
+				"salt": "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"
+			},
+			"mac": "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"
+		},
+		"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+		"version": 3
+	}`
+	key, err := DecryptKeystore([]byte(data), "testpassword")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(key.Bytes()); got != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+		t.Fatalf("key = %s", got)
+	}
+	if _, err := DecryptKeystore([]byte(data), "wrong"); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("wrong password error = %v", err)
+	}
+}
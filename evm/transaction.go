@@ -0,0 +1,204 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package evm builds, encodes and signs EVM transactions for broadcasting through
+// wallet.TransactionBroadcast.
+package evm
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/internal/keccak"
+)
+
+var (
+	ErrIncompleteTransaction = errors.New("evm: incomplete transaction")
+	ErrUnsignedTransaction   = errors.New("evm: transaction is not signed")
+	ErrInvalidSignature      = errors.New("evm: invalid signature")
+)
+
+// Address is a 20 byte EVM address.
+type Address [20]byte
+
+// ParseAddress parses a 0x-prefixed hex address.
+func ParseAddress(s string) (Address, error) {
+	var a Address
+	if err := address.Validate("1", s); err != nil {
+		return a, err
+	}
+	_, err := hex.Decode(a[:], []byte(strings.ToLower(s[2:])))
+	return a, err
+}
+
+// Hex returns the EIP-55 checksummed form of a.
+func (a Address) Hex() string {
+	s, _ := address.ChecksumAddress("0x" + hex.EncodeToString(a[:]))
+	return s
+}
+
+func (a Address) String() string {
+	return a.Hex()
+}
+
+// TxType is the EIP-2718 type of a transaction.
+type TxType uint8
+
+const (
+	// LegacyTxType is a pre-EIP-2718 transaction with EIP-155 replay protection.
+	LegacyTxType TxType = 0x00
+	// DynamicFeeTxType is an EIP-1559 transaction.
+	DynamicFeeTxType TxType = 0x02
+)
+
+// Transaction is an EVM transaction. GasPrice is used by legacy transactions,
+// MaxPriorityFeePerGas and MaxFeePerGas by EIP-1559 transactions.
+type Transaction struct {
+	Type                 TxType
+	ChainID              *big.Int
+	Nonce                uint64
+	GasPrice             *big.Int
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
+	Gas                  uint64
+	// To is nil for contract creation.
+	To    *Address
+	Value *big.Int
+	Data  []byte
+
+	// V, R and S are set by WithSignature. V is the EIP-155 value for legacy transactions
+	// and the y parity for typed transactions.
+	V, R, S *big.Int
+}
+
+// Signed reports whether the transaction carries a signature.
+func (tx *Transaction) Signed() bool {
+	return tx.R != nil && tx.S != nil && tx.V != nil
+}
+
+// SigningHash returns the hash that is signed by the sender.
+func (tx *Transaction) SigningHash() [32]byte {
+	if tx.Type == DynamicFeeTxType {
+		return keccak.Sum256([]byte{byte(DynamicFeeTxType)}, rlpList(tx.dynamicFeeFields()...))
+	}
+	fields := append(tx.legacyFields(), rlpBig(tx.ChainID), rlpUint(0), rlpUint(0))
+	return keccak.Sum256(rlpList(fields...))
+}
+
+// WithSignature returns a copy of tx carrying sig, a 65 byte [R || S || V] signature
+// whose V is the recovery id, either 0/1 or 27/28.
+func (tx *Transaction) WithSignature(sig []byte) (*Transaction, error) {
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidSignature, len(sig))
+	}
+	recid := sig[64]
+	if recid >= 27 {
+		recid -= 27
+	}
+	if recid > 1 {
+		return nil, fmt.Errorf("%w: recovery id %d", ErrInvalidSignature, sig[64])
+	}
+
+	cpy := *tx
+	cpy.R = new(big.Int).SetBytes(sig[:32])
+	cpy.S = new(big.Int).SetBytes(sig[32:64])
+	if tx.Type == DynamicFeeTxType {
+		cpy.V = big.NewInt(int64(recid))
+	} else {
+		// EIP-155: v = recid + chainId * 2 + 35.
+		cpy.V = new(big.Int).Lsh(bigOrZero(tx.ChainID), 1)
+		cpy.V.Add(cpy.V, big.NewInt(int64(recid)+35))
+	}
+	return &cpy, nil
+}
+
+// MarshalBinary returns the signed transaction in its network encoding.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	if !tx.Signed() {
+		return nil, ErrUnsignedTransaction
+	}
+	sig := [][]byte{rlpBig(tx.V), rlpBig(tx.R), rlpBig(tx.S)}
+	if tx.Type == DynamicFeeTxType {
+		return append([]byte{byte(DynamicFeeTxType)}, rlpList(append(tx.dynamicFeeFields(), sig...)...)...), nil
+	}
+	return rlpList(append(tx.legacyFields(), sig...)...), nil
+}
+
+// SignedTx returns the 0x-prefixed hex of the signed transaction, as accepted by
+// wallet.TransactionBroadcastRequest.SignedTx.
+func (tx *Transaction) SignedTx() (string, error) {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(b), nil
+}
+
+// Hash returns the 0x-prefixed hash of the signed transaction.
+func (tx *Transaction) Hash() (string, error) {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	h := keccak.Sum256(b)
+	return "0x" + hex.EncodeToString(h[:]), nil
+}
+
+func (tx *Transaction) legacyFields() [][]byte {
+	return [][]byte{
+		rlpUint(tx.Nonce),
+		rlpBig(tx.GasPrice),
+		rlpUint(tx.Gas),
+		tx.rlpTo(),
+		rlpBig(tx.Value),
+		rlpString(tx.Data),
+	}
+}
+
+func (tx *Transaction) dynamicFeeFields() [][]byte {
+	return [][]byte{
+		rlpBig(tx.ChainID),
+		rlpUint(tx.Nonce),
+		rlpBig(tx.MaxPriorityFeePerGas),
+		rlpBig(tx.MaxFeePerGas),
+		rlpUint(tx.Gas),
+		tx.rlpTo(),
+		rlpBig(tx.Value),
+		rlpString(tx.Data),
+		rlpList(), // access list
+	}
+}
+
+func (tx *Transaction) rlpTo() []byte {
+	if tx.To == nil {
+		return rlpString(nil)
+	}
+	return rlpString(tx.To[:])
+}
+
+func bigOrZero(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
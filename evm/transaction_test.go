@@ -0,0 +1,117 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+// The example of EIP-155.
+func TestLegacyTransaction(t *testing.T) {
+	tx, err := BuildTransaction("1", &dex.Tx{
+		To:       "0x3535353535353535353535353535353535353535",
+		Value:    types.MustParseUint256("1000000000000000000"),
+		Gas:      types.Uint256FromUint64(21000),
+		GasPrice: types.MustParseUint256("20000000000"),
+	}, nil, WithNonce(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type != LegacyTxType {
+		t.Fatalf("type = %d", tx.Type)
+	}
+
+	hash := tx.SigningHash()
+	if got := hex.EncodeToString(hash[:]); got != "daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53" {
+		t.Fatalf("signing hash = %s", got)
+	}
+
+	if _, err := tx.SignedTx(); !errors.Is(err, ErrUnsignedTransaction) {
+		t.Fatalf("unsigned SignedTx error = %v", err)
+	}
+
+	sig, _ := hex.DecodeString("28ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276" +
+		"67cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83" + "00")
+	signed, err := tx.WithSignature(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := signed.SignedTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025" +
+		"a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276" +
+		"a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+	if raw != want {
+		t.Fatalf("signed tx = %s", raw)
+	}
+}
+
+func TestDynamicFeeTransaction(t *testing.T) {
+	info := &wallet.SignInfoEvm{
+		GasLimit: types.Uint256FromUint64(21000),
+		Nonce:    3,
+		GasPrice: &wallet.GasPrice{
+			Normal:           types.Uint256FromUint64(30),
+			SupportedEip1559: true,
+			Eip1559Protocol: &wallet.Eip1559Protocol{
+				BaseFee:            types.Uint256FromUint64(10),
+				ProposePriorityFee: types.Uint256FromUint64(2),
+			},
+		},
+	}
+	tx, err := BuildTransaction("1", &dex.Tx{To: "0x3535353535353535353535353535353535353535", Data: "0x"}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type != DynamicFeeTxType || tx.Nonce != 3 || tx.Gas != 21000 {
+		t.Fatalf("tx = %+v", tx)
+	}
+	if tx.MaxPriorityFeePerGas.Int64() != 2 || tx.MaxFeePerGas.Int64() != 22 {
+		t.Fatalf("fees = %s / %s", tx.MaxPriorityFeePerGas, tx.MaxFeePerGas)
+	}
+
+	sig := make([]byte, 65)
+	sig[31], sig[63], sig[64] = 1, 2, 28
+	signed, err := tx.WithSignature(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := signed.SignedTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// chainId 1, nonce 3, tip 2, max fee 22, gas 21000, to, value 0, no data, no access list, y parity 1, r 1, s 2.
+	want := "0x02e2010302168252089435353535353535353535353535353535353535358080c0010102"
+	if raw != want {
+		t.Fatalf("signed tx = %s", raw)
+	}
+
+	if _, err := BuildTransaction("1", &dex.Tx{To: tx.To.Hex()}, nil); !errors.Is(err, ErrIncompleteTransaction) {
+		t.Fatalf("missing nonce error = %v", err)
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/imzhongqi/okxos/internal/keccak"
+)
+
+// TypedDataField is a member of an EIP-712 struct type.
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is EIP-712 typed data in the JSON form of eth_signTypedData_v4.
+// Integers may be JSON numbers or decimal or 0x-prefixed hex strings, and bytes are hex strings.
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]any              `json:"domain"`
+	Message     map[string]any              `json:"message"`
+}
+
+const domainType = "EIP712Domain"
+
+// domainFields are the fields of EIP712Domain in the order of the specification.
+var domainFields = []TypedDataField{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+	{Name: "salt", Type: "bytes32"},
+}
+
+// Hash returns the hash that is signed: keccak256(0x19 0x01 || domainSeparator || hashStruct(message)).
+func (td *TypedData) Hash() ([32]byte, error) {
+	domain, err := td.DomainSeparator()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return keccak.Sum256([]byte{0x19, 0x01}, domain[:], message[:]), nil
+}
+
+// DomainSeparator returns the hash of the domain. When the types do not declare
+// EIP712Domain, it is made of the domain fields that are present.
+func (td *TypedData) DomainSeparator() ([32]byte, error) {
+	if _, ok := td.Types[domainType]; !ok {
+		var fields []TypedDataField
+		for _, f := range domainFields {
+			if _, ok := td.Domain[f.Name]; ok {
+				fields = append(fields, f)
+			}
+		}
+		types := make(map[string][]TypedDataField, len(td.Types)+1)
+		for name, fields := range td.Types {
+			types[name] = fields
+		}
+		types[domainType] = fields
+		cpy := *td
+		cpy.Types = types
+		return cpy.HashStruct(domainType, td.Domain)
+	}
+	return td.HashStruct(domainType, td.Domain)
+}
+
+// HashStruct returns keccak256(typeHash || encodeData(data)) of data of struct type typ.
+func (td *TypedData) HashStruct(typ string, data map[string]any) ([32]byte, error) {
+	encoded, err := td.encodeData(typ, data, 0)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return keccak.Sum256(encoded), nil
+}
+
+// EncodeType returns the type encoding of typ, such as "Mail(Person from,Person to,string contents)Person(string name,address wallet)".
+func (td *TypedData) EncodeType(typ string) (string, error) {
+	deps := make(map[string]bool)
+	if err := td.dependencies(typ, deps); err != nil {
+		return "", err
+	}
+	delete(deps, typ)
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range append([]string{typ}, names...) {
+		b.WriteString(name)
+		b.WriteByte('(')
+		for i, f := range td.Types[name] {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(f.Type)
+			b.WriteByte(' ')
+			b.WriteString(f.Name)
+		}
+		b.WriteByte(')')
+	}
+	return b.String(), nil
+}
+
+func (td *TypedData) dependencies(typ string, deps map[string]bool) error {
+	if deps[typ] {
+		return nil
+	}
+	fields, ok := td.Types[typ]
+	if !ok {
+		return fmt.Errorf("evm: unknown typed data type %q", typ)
+	}
+	deps[typ] = true
+	for _, f := range fields {
+		if base := baseType(f.Type); td.isStruct(base) {
+			if err := td.dependencies(base, deps); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (td *TypedData) isStruct(typ string) bool {
+	_, ok := td.Types[typ]
+	return ok
+}
+
+func (td *TypedData) encodeData(typ string, data map[string]any, depth int) ([]byte, error) {
+	if depth > 32 {
+		return nil, fmt.Errorf("evm: typed data nested too deeply")
+	}
+	encodedType, err := td.EncodeType(typ)
+	if err != nil {
+		return nil, err
+	}
+	typeHash := keccak.Sum256([]byte(encodedType))
+
+	out := append([]byte(nil), typeHash[:]...)
+	for _, f := range td.Types[typ] {
+		value, ok := data[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("evm: typed data %s is missing %s", typ, f.Name)
+		}
+		word, err := td.encodeValue(f.Type, value, depth)
+		if err != nil {
+			return nil, fmt.Errorf("evm: typed data %s.%s: %w", typ, f.Name, err)
+		}
+		out = append(out, word[:]...)
+	}
+	return out, nil
+}
+
+func (td *TypedData) encodeValue(typ string, value any, depth int) ([32]byte, error) {
+	var word [32]byte
+
+	if i := strings.LastIndexByte(typ, '['); i > 0 && strings.HasSuffix(typ, "]") {
+		items, ok := value.([]any)
+		if !ok {
+			return word, fmt.Errorf("%s wants an array, got %T", typ, value)
+		}
+		if n := typ[i+1 : len(typ)-1]; n != "" && n != strconv.Itoa(len(items)) {
+			return word, fmt.Errorf("%s wants %s items, got %d", typ, n, len(items))
+		}
+		var encoded []byte
+		for _, item := range items {
+			w, err := td.encodeValue(typ[:i], item, depth+1)
+			if err != nil {
+				return word, err
+			}
+			encoded = append(encoded, w[:]...)
+		}
+		return keccak.Sum256(encoded), nil
+	}
+
+	if td.isStruct(typ) {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return word, fmt.Errorf("%s wants an object, got %T", typ, value)
+		}
+		encoded, err := td.encodeData(typ, m, depth+1)
+		if err != nil {
+			return word, err
+		}
+		return keccak.Sum256(encoded), nil
+	}
+
+	switch {
+	case typ == "string":
+		s, ok := value.(string)
+		if !ok {
+			return word, fmt.Errorf("string wants a string, got %T", value)
+		}
+		return keccak.Sum256([]byte(s)), nil
+	case typ == "bytes":
+		b, err := bytesValue(value)
+		if err != nil {
+			return word, err
+		}
+		return keccak.Sum256(b), nil
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return word, fmt.Errorf("bool wants a bool, got %T", value)
+		}
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+	case typ == "address":
+		s, ok := value.(string)
+		if !ok {
+			return word, fmt.Errorf("address wants a string, got %T", value)
+		}
+		addr, err := ParseAddress(s)
+		if err != nil {
+			return word, err
+		}
+		copy(word[12:], addr[:])
+		return word, nil
+	case strings.HasPrefix(typ, "bytes"):
+		n, err := strconv.Atoi(typ[len("bytes"):])
+		if err != nil || n < 1 || n > 32 {
+			return word, fmt.Errorf("unknown type %s", typ)
+		}
+		b, err := bytesValue(value)
+		if err != nil {
+			return word, err
+		}
+		if len(b) > n {
+			return word, fmt.Errorf("%s got %d bytes", typ, len(b))
+		}
+		copy(word[:], b)
+		return word, nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		signed := strings.HasPrefix(typ, "int")
+		bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return word, fmt.Errorf("unknown type %s", typ)
+		}
+		n, err := intValue(value)
+		if err != nil {
+			return word, err
+		}
+		if (!signed && (n.Sign() < 0 || n.BitLen() > bits)) || (signed && n.BitLen() >= bits && !isMinInt(n, bits)) {
+			return word, fmt.Errorf("%s out of range for %s", n, typ)
+		}
+		if n.Sign() < 0 {
+			// two's complement
+			n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		n.FillBytes(word[:])
+		return word, nil
+	}
+	return word, fmt.Errorf("unknown type %s", typ)
+}
+
+// baseType strips the array suffixes of typ.
+func baseType(typ string) string {
+	if i := strings.IndexByte(typ, '['); i > 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+func isMinInt(n *big.Int, bits int) bool {
+	return n.Cmp(new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)))) == 0
+}
+
+func bytesValue(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if !strings.HasPrefix(v, "0x") && !strings.HasPrefix(v, "0X") {
+			return nil, fmt.Errorf("bytes must be 0x-prefixed hex, got %q", v)
+		}
+		return decodeHex(v)
+	}
+	return nil, fmt.Errorf("bytes wants a hex string, got %T", value)
+}
+
+func intValue(value any) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return new(big.Int).Set(v), nil
+	case json.Number:
+		return parseInt(string(v))
+	case string:
+		return parseInt(v)
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+			return nil, fmt.Errorf("%v is not an exact integer, pass it as a string", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	}
+	return nil, fmt.Errorf("integer wants a number or a string, got %T", value)
+}
+
+func parseInt(s string) (*big.Int, error) {
+	n, ok := new(big.Int), false
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		n, ok = n.SetString(s[2:], 16)
+	case strings.HasPrefix(s, "-0x"), strings.HasPrefix(s, "-0X"):
+		if n, ok = n.SetString(s[3:], 16); ok {
+			n.Neg(n)
+		}
+	default:
+		n, ok = n.SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package base58 implements the Bitcoin base58 alphabet used by Bitcoin, Tron and Solana.
+package base58
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	ErrInvalidCharacter = errors.New("base58: invalid character")
+	ErrInvalidChecksum  = errors.New("base58: invalid checksum")
+
+	decodeMap [256]int8
+	bigRadix  = big.NewInt(58)
+)
+
+func init() {
+	for i := range decodeMap {
+		decodeMap[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		decodeMap[alphabet[i]] = int8(i)
+	}
+}
+
+// Encode encodes b in base58.
+func Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*138/100+1)
+	for n.Sign() > 0 {
+		n.DivMod(n, bigRadix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// Decode decodes the base58 string s.
+func Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	for i := zeros; i < len(s); i++ {
+		d := decodeMap[s[i]]
+		if d < 0 {
+			return nil, ErrInvalidCharacter
+		}
+		n.Mul(n, bigRadix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// CheckEncode encodes b followed by the first four bytes of its double SHA-256.
+func CheckEncode(b []byte) string {
+	sum := checksum(b)
+	return Encode(append(append(make([]byte, 0, len(b)+4), b...), sum[:]...))
+}
+
+// CheckDecode decodes s and verifies its four byte checksum, which is removed from the result.
+func CheckDecode(s string) ([]byte, error) {
+	b, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 4 {
+		return nil, ErrInvalidChecksum
+	}
+	payload, sum := b[:len(b)-4], b[len(b)-4:]
+	if want := checksum(payload); string(want[:]) != string(sum) {
+		return nil, ErrInvalidChecksum
+	}
+	return payload, nil
+}
+
+func checksum(b []byte) [4]byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	var sum [4]byte
+	copy(sum[:], second[:4])
+	return sum
+}
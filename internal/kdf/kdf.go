@@ -0,0 +1,141 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package kdf implements the PBKDF2 (RFC 8018) and scrypt (RFC 7914) key derivation
+// functions used by Ethereum keystore files.
+package kdf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/bits"
+)
+
+var ErrInvalidParams = errors.New("kdf: invalid parameters")
+
+// PBKDF2 derives a key of keyLen bytes from password and salt with iter iterations of
+// HMAC using h.
+func PBKDF2(password, salt []byte, iter, keyLen int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	size := prf.Size()
+	blocks := (keyLen + size - 1) / size
+
+	key := make([]byte, 0, blocks*size)
+	u := make([]byte, size)
+	t := make([]byte, size)
+	var counter [4]byte
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// Scrypt derives a key of keyLen bytes from password and salt with HMAC-SHA256. n is the CPU/memory cost and
+// must be a power of two greater than one, r the block size and p the parallelization.
+func Scrypt(password, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n&(n-1) != 0 || r <= 0 || p <= 0 || uint64(r)*uint64(p) >= 1<<30 ||
+		r > (1<<31-1)/128/p || r > (1<<31-1)/256 || n > (1<<31-1)/128/r {
+		return nil, ErrInvalidParams
+	}
+
+	b := PBKDF2(password, salt, 1, p*128*r, sha256.New)
+	x := make([]uint32, 32*r)
+	v := make([]uint32, 32*r*n)
+	y := make([]uint32, 32*r)
+	for i := 0; i < p; i++ {
+		romix(b[i*128*r:(i+1)*128*r], r, n, x, y, v)
+	}
+	return PBKDF2(password, b, 1, keyLen, sha256.New), nil
+}
+
+func romix(b []byte, r, n int, x, y, v []uint32) {
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	for i := 0; i < n; i++ {
+		copy(v[i*32*r:], x)
+		blockMix(x, y, r)
+	}
+	for i := 0; i < n; i++ {
+		j := int(x[(2*r-1)*16] & uint32(n-1))
+		for k := range x {
+			x[k] ^= v[j*32*r+k]
+		}
+		blockMix(x, y, r)
+	}
+	for i := range x {
+		binary.LittleEndian.PutUint32(b[i*4:], x[i])
+	}
+}
+
+// blockMix is scrypt's BlockMix with Salsa20/8. y is scratch space of the size of b.
+func blockMix(b, y []uint32, r int) {
+	var t [16]uint32
+	copy(t[:], b[(2*r-1)*16:])
+	for i := 0; i < 2*r; i++ {
+		for j := range t {
+			t[j] ^= b[i*16+j]
+		}
+		salsa208(&t)
+		// Even blocks go to the first half of the output, odd blocks to the second.
+		copy(y[(i/2+(i%2)*r)*16:], t[:])
+	}
+	copy(b, y)
+}
+
+func salsa208(b *[16]uint32) {
+	x := *b
+	for i := 0; i < 8; i += 2 {
+		quarter(&x, 0, 4, 8, 12)
+		quarter(&x, 5, 9, 13, 1)
+		quarter(&x, 10, 14, 2, 6)
+		quarter(&x, 15, 3, 7, 11)
+		quarter(&x, 0, 1, 2, 3)
+		quarter(&x, 5, 6, 7, 4)
+		quarter(&x, 10, 11, 8, 9)
+		quarter(&x, 15, 12, 13, 14)
+	}
+	for i := range b {
+		b[i] += x[i]
+	}
+}
+
+func quarter(x *[16]uint32, a, b, c, d int) {
+	x[b] ^= bits.RotateLeft32(x[a]+x[d], 7)
+	x[c] ^= bits.RotateLeft32(x[b]+x[a], 9)
+	x[d] ^= bits.RotateLeft32(x[c]+x[b], 13)
+	x[a] ^= bits.RotateLeft32(x[d]+x[c], 18)
+}
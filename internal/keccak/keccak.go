@@ -0,0 +1,150 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package keccak implements the legacy Keccak-256 hash used by Ethereum, which differs from
+// SHA3-256 only in its padding.
+package keccak
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const rate = 136 // (1600 - 2*256) / 8
+
+var roundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+var rotations = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+func keccakF1600(a *[25]uint64) {
+	var b [25]uint64
+	var c, d [5]uint64
+	for round := 0; round < 24; round++ {
+		// θ
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d[x] = c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+		}
+		for i := 0; i < 25; i++ {
+			a[i] ^= d[i%5]
+		}
+		// ρ and π
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(a[x+5*y], rotations[x+5*y])
+			}
+		}
+		// χ
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				a[y+x] = b[y+x] ^ (^b[y+(x+1)%5] & b[y+(x+2)%5])
+			}
+		}
+		// ι
+		a[0] ^= roundConstants[round]
+	}
+}
+
+type state struct {
+	a   [25]uint64
+	buf []byte
+}
+
+// New returns a new Keccak-256 hash.
+func New() hash.Hash {
+	return &state{buf: make([]byte, 0, rate)}
+}
+
+// Sum256 returns the Keccak-256 digest of the concatenation of data.
+func Sum256(data ...[]byte) [32]byte {
+	h := New()
+	for _, b := range data {
+		h.Write(b)
+	}
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+func (s *state) absorb(block []byte) {
+	for i := 0; i < rate/8; i++ {
+		s.a[i] ^= binary.LittleEndian.Uint64(block[i*8:])
+	}
+	keccakF1600(&s.a)
+}
+
+func (s *state) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(s.buf) > 0 {
+		k := copy(s.buf[len(s.buf):rate], p)
+		s.buf = s.buf[:len(s.buf)+k]
+		p = p[k:]
+		if len(s.buf) < rate {
+			return n, nil
+		}
+		s.absorb(s.buf)
+		s.buf = s.buf[:0]
+	}
+	for len(p) >= rate {
+		s.absorb(p[:rate])
+		p = p[rate:]
+	}
+	s.buf = append(s.buf, p...)
+	return n, nil
+}
+
+func (s *state) Sum(b []byte) []byte {
+	dup := *s
+	block := make([]byte, rate)
+	copy(block, s.buf)
+	block[len(s.buf)] ^= 0x01
+	block[rate-1] ^= 0x80
+	dup.absorb(block)
+
+	var out [32]byte
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(out[i*8:], dup.a[i])
+	}
+	return append(b, out[:]...)
+}
+
+func (s *state) Reset() {
+	s.a = [25]uint64{}
+	s.buf = s.buf[:0]
+}
+
+func (s *state) Size() int { return 32 }
+
+func (s *state) BlockSize() int { return rate }
@@ -0,0 +1,56 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package keccak
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSum256(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+		{"transfer(address,uint256)", "a9059cbb2ab09eb219583f4a59a5d0623ade346d962bcd4e46b11da047c9049b"},
+		{strings.Repeat("a", 200), ""},
+	}
+	for _, tt := range tests {
+		got := Sum256([]byte(tt.in))
+		if tt.want != "" && hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("Sum256(%q) = %x, want %s", tt.in, got, tt.want)
+		}
+
+		// writing in small pieces must not change the digest.
+		h := New()
+		for i := 0; i < len(tt.in); i += 7 {
+			end := i + 7
+			if end > len(tt.in) {
+				end = len(tt.in)
+			}
+			h.Write([]byte(tt.in[i:end]))
+		}
+		if sum := h.Sum(nil); hex.EncodeToString(sum) != hex.EncodeToString(got[:]) {
+			t.Errorf("incremental digest of %q = %x, want %x", tt.in, sum, got)
+		}
+	}
+}
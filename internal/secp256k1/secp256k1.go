@@ -0,0 +1,228 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package secp256k1 implements the ECDSA operations on the secp256k1 curve needed to sign
+// Ethereum transactions: key derivation, deterministic RFC 6979 signing with low S values and
+// public key recovery. It favours simplicity over speed and is not constant time.
+package secp256k1
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrInvalidPrivateKey = errors.New("secp256k1: invalid private key")
+	ErrInvalidSignature  = errors.New("secp256k1: invalid signature")
+)
+
+var (
+	// P is the order of the underlying field.
+	P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	// N is the order of the base point.
+	N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+
+	gx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	gy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+
+	halfN   = new(big.Int).Rsh(N, 1)
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(P, big.NewInt(1)), 2)
+)
+
+// point is an affine point; nil is the point at infinity.
+type point struct {
+	x, y *big.Int
+}
+
+func add(a, b *point) *point {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	var lambda *big.Int
+	if a.x.Cmp(b.x) == 0 {
+		if a.y.Cmp(b.y) != 0 || a.y.Sign() == 0 {
+			return nil
+		}
+		// lambda = 3x² / 2y
+		num := new(big.Int).Mul(a.x, a.x)
+		num.Mul(num, big.NewInt(3))
+		den := new(big.Int).Lsh(a.y, 1)
+		lambda = num.Mul(num, new(big.Int).ModInverse(den.Mod(den, P), P))
+	} else {
+		// lambda = (y2 - y1) / (x2 - x1)
+		num := new(big.Int).Sub(b.y, a.y)
+		den := new(big.Int).Sub(b.x, a.x)
+		lambda = num.Mul(num, new(big.Int).ModInverse(den.Mod(den, P), P))
+	}
+	lambda.Mod(lambda, P)
+
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, a.x).Sub(x, b.x).Mod(x, P)
+	y := new(big.Int).Sub(a.x, x)
+	y.Mul(y, lambda).Sub(y, a.y).Mod(y, P)
+	return &point{x, y}
+}
+
+func mul(p *point, k *big.Int) *point {
+	var r *point
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = add(r, r)
+		if k.Bit(i) == 1 {
+			r = add(r, p)
+		}
+	}
+	return r
+}
+
+// ValidPrivateKey reports whether d is in [1, N-1].
+func ValidPrivateKey(d *big.Int) bool {
+	return d.Sign() > 0 && d.Cmp(N) < 0
+}
+
+// PublicKey returns the public key of the private key d.
+func PublicKey(d *big.Int) (x, y *big.Int, err error) {
+	if !ValidPrivateKey(d) {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+	q := mul(&point{gx, gy}, d)
+	return q.x, q.y, nil
+}
+
+// Sign signs the 32 byte hash with d, using a nonce derived as in RFC 6979. S is normalized to
+// the lower half of the order, and recid is the id with which Recover returns the public key.
+func Sign(d *big.Int, hash []byte) (r, s *big.Int, recid byte, err error) {
+	if !ValidPrivateKey(d) {
+		return nil, nil, 0, ErrInvalidPrivateKey
+	}
+	z := hashToInt(hash)
+	nonce := newNonce(d, z)
+	for {
+		k := nonce()
+		p := mul(&point{gx, gy}, k)
+		r = new(big.Int).Mod(p.x, N)
+		if r.Sign() == 0 {
+			continue
+		}
+		s = new(big.Int).Mul(r, d)
+		s.Add(s, z).Mul(s, new(big.Int).ModInverse(k, N)).Mod(s, N)
+		if s.Sign() == 0 {
+			continue
+		}
+
+		recid = byte(p.y.Bit(0))
+		if p.x.Cmp(N) >= 0 {
+			recid |= 2
+		}
+		if s.Cmp(halfN) > 0 {
+			s.Sub(N, s)
+			recid ^= 1
+		}
+		return r, s, recid, nil
+	}
+}
+
+// Recover returns the public key that produced the signature (r, s) of hash.
+func Recover(hash []byte, r, s *big.Int, recid byte) (x, y *big.Int, err error) {
+	if recid > 3 || r.Sign() <= 0 || r.Cmp(N) >= 0 || s.Sign() <= 0 || s.Cmp(N) >= 0 {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	rx := new(big.Int).Set(r)
+	if recid&2 != 0 {
+		rx.Add(rx, N)
+		if rx.Cmp(P) >= 0 {
+			return nil, nil, ErrInvalidSignature
+		}
+	}
+	// y² = x³ + 7
+	y2 := new(big.Int).Exp(rx, big.NewInt(3), P)
+	y2.Add(y2, big.NewInt(7)).Mod(y2, P)
+	ry := new(big.Int).Exp(y2, sqrtExp, P)
+	if new(big.Int).Exp(ry, big.NewInt(2), P).Cmp(y2) != 0 {
+		return nil, nil, ErrInvalidSignature
+	}
+	if ry.Bit(0) != uint(recid&1) {
+		ry.Sub(P, ry)
+	}
+
+	// Q = r⁻¹ (sR - zG)
+	rInv := new(big.Int).ModInverse(r, N)
+	u1 := new(big.Int).Neg(hashToInt(hash))
+	u1.Mul(u1, rInv).Mod(u1, N)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, N)
+	q := add(mul(&point{gx, gy}, u1), mul(&point{rx, ry}, u2))
+	if q == nil {
+		return nil, nil, ErrInvalidSignature
+	}
+	return q.x, q.y, nil
+}
+
+func hashToInt(hash []byte) *big.Int {
+	if len(hash) > 32 {
+		hash = hash[:32]
+	}
+	z := new(big.Int).SetBytes(hash)
+	return z.Mod(z, N)
+}
+
+// newNonce returns the generator of RFC 6979 section 3.2 for HMAC-SHA256.
+func newNonce(d, z *big.Int) func() *big.Int {
+	x := d.FillBytes(make([]byte, 32))
+	h := z.FillBytes(make([]byte, 32))
+
+	v := make([]byte, 32)
+	for i := range v {
+		v[i] = 0x01
+	}
+	k := make([]byte, 32)
+	mac := func(key []byte, data ...[]byte) []byte {
+		m := hmac.New(sha256.New, key)
+		for _, b := range data {
+			m.Write(b)
+		}
+		return m.Sum(nil)
+	}
+
+	k = mac(k, v, []byte{0x00}, x, h)
+	v = mac(k, v)
+	k = mac(k, v, []byte{0x01}, x, h)
+	v = mac(k, v)
+
+	first := true
+	return func() *big.Int {
+		for {
+			if !first {
+				k = mac(k, v, []byte{0x00})
+				v = mac(k, v)
+			}
+			first = false
+			v = mac(k, v)
+			n := new(big.Int).SetBytes(v)
+			if n.Sign() > 0 && n.Cmp(N) < 0 {
+				return n
+			}
+		}
+	}
+}
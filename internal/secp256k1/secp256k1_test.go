@@ -0,0 +1,176 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func hexInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("bad hex " + s)
+	}
+	return n
+}
+
+func TestSign(t *testing.T) {
+	// RFC 6979 nonces with HMAC-SHA256 on secp256k1, as used by the common test vectors.
+	tests := []struct {
+		d, msg, k, r, s string
+	}{
+		{
+			"1", "Satoshi Nakamoto",
+			"8f8a276c19f4149656b280621e358cce24f5f52542772691ee69063b74f15d15",
+			"934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8",
+			"2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5",
+		},
+		{
+			"1", "All those moments will be lost in time, like tears in rain. Time to die...",
+			"38aa22d72376b4dbc472e06c3ba403ee0a394da63fc58d88686c611aba98d6b3",
+			"8600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b",
+			"547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc21",
+		},
+		{
+			"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140", "Satoshi Nakamoto",
+			"33a19b60e25fb6f4435af53a3d42d493644827367e6453928554f43e49aa6f90",
+			"fd567d121db66e382991534ada77a6bd3106f0a1098c231e47993447cd6af2d0",
+			"6b39cd0eb1bc8603e159ef5c20a5c8ad685a45b06ce9bebed3f153d10d93bed5",
+		},
+		{
+			"f8b8af8ce3c7cca5e300d33939540c10d45ce001b8f252bfbc57ba0342904181", "Alan Turing",
+			"525a82b70e67874398067543fd84c83d30c175fdc45fdeee082fe13b1d7cfdf1",
+			"7063ae83e7f62bbb171798131b4a0564b956930092b33b07b395615d9ec7e15c",
+			"58dfcc1e00a35e1572f366ffe34ba0fc47db1e7189759b9fb233c5b05ab388ea",
+		},
+	}
+	for _, tt := range tests {
+		d := hexInt(tt.d)
+		hash := sha256.Sum256([]byte(tt.msg))
+		if k := newNonce(d, hashToInt(hash[:]))(); k.Cmp(hexInt(tt.k)) != 0 {
+			t.Errorf("%q: nonce %x, want %s", tt.msg, k, tt.k)
+		}
+		r, s, recid, err := Sign(d, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Cmp(hexInt(tt.r)) != 0 || s.Cmp(hexInt(tt.s)) != 0 {
+			t.Errorf("%q: signature (%x, %x), want (%s, %s)", tt.msg, r, s, tt.r, tt.s)
+		}
+
+		px, py, _ := PublicKey(d)
+		x, y, err := Recover(hash[:], r, s, recid)
+		if err != nil || x.Cmp(px) != 0 || y.Cmp(py) != 0 {
+			t.Errorf("%q: Recover = %v, key mismatch", tt.msg, err)
+		}
+	}
+}
+
+func TestSignLowS(t *testing.T) {
+	d := hexInt("c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4")
+	px, py, _ := PublicKey(d)
+	for i := 0; i < 16; i++ {
+		hash := sha256.Sum256([]byte{byte(i)})
+		r, s, recid, err := Sign(d, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Cmp(halfN) > 0 {
+			t.Fatalf("hash %d: s %x above N/2", i, s)
+		}
+		x, y, err := Recover(hash[:], r, s, recid)
+		if err != nil || x.Cmp(px) != 0 || y.Cmp(py) != 0 {
+			t.Fatalf("hash %d: Recover with recid %d = %v, key mismatch", i, recid, err)
+		}
+		// the other parity gives another key.
+		if x, _, err := Recover(hash[:], r, s, recid^1); err == nil && x.Cmp(px) == 0 {
+			t.Fatalf("hash %d: recid %d recovers the signer", i, recid^1)
+		}
+	}
+}
+
+// TestRecoverOverflow covers recid 2 and 3, whose R has an x coordinate of r + N. Such signatures
+// are too rare to produce by signing, so the recovered key is checked by verifying the signature.
+func TestRecoverOverflow(t *testing.T) {
+	hash := sha256.Sum256([]byte("overflow"))
+	z := hashToInt(hash[:])
+	s := big.NewInt(12345)
+	for r := int64(1); r < 100; r++ {
+		rx := new(big.Int).Add(big.NewInt(r), N)
+		y2 := new(big.Int).Exp(rx, big.NewInt(3), P)
+		y2.Add(y2, big.NewInt(7)).Mod(y2, P)
+		if new(big.Int).Exp(new(big.Int).Exp(y2, sqrtExp, P), big.NewInt(2), P).Cmp(y2) != 0 {
+			continue // r + N is not the x coordinate of a point.
+		}
+
+		for _, recid := range []byte{2, 3} {
+			x, y, err := Recover(hash[:], big.NewInt(r), s, recid)
+			if err != nil {
+				t.Fatalf("r %d, recid %d: %v", r, recid, err)
+			}
+			// u1 G + u2 Q must be R, with u1 = z/s and u2 = r/s.
+			sInv := new(big.Int).ModInverse(s, N)
+			u1 := new(big.Int).Mul(z, sInv)
+			u2 := new(big.Int).Mul(big.NewInt(r), sInv)
+			p := add(mul(&point{gx, gy}, u1.Mod(u1, N)), mul(&point{x, y}, u2.Mod(u2, N)))
+			if p == nil || p.x.Cmp(rx) != 0 || p.y.Bit(0) != uint(recid&1) {
+				t.Fatalf("r %d, recid %d: recovered key does not verify", r, recid)
+			}
+		}
+		return
+	}
+	t.Fatal("no r with r + N on the curve")
+}
+
+func TestInvalid(t *testing.T) {
+	hash := sha256.Sum256([]byte("invalid"))
+	for _, d := range []*big.Int{big.NewInt(0), big.NewInt(-1), N, new(big.Int).Add(N, big.NewInt(1))} {
+		if ValidPrivateKey(d) {
+			t.Errorf("ValidPrivateKey(%x) = true", d)
+		}
+		if _, _, err := PublicKey(d); !errors.Is(err, ErrInvalidPrivateKey) {
+			t.Errorf("PublicKey(%x) = %v", d, err)
+		}
+		if _, _, _, err := Sign(d, hash[:]); !errors.Is(err, ErrInvalidPrivateKey) {
+			t.Errorf("Sign(%x) = %v", d, err)
+		}
+	}
+	if x, y, err := PublicKey(big.NewInt(1)); err != nil || x.Cmp(gx) != 0 || y.Cmp(gy) != 0 {
+		t.Errorf("PublicKey(1) is not the base point")
+	}
+
+	one := big.NewInt(1)
+	for _, sig := range []struct {
+		r, s  *big.Int
+		recid byte
+	}{
+		{one, one, 4},
+		{big.NewInt(0), one, 0},
+		{one, N, 0},
+		{new(big.Int).Sub(P, N), one, 2}, // r + N is not below P.
+	} {
+		if _, _, err := Recover(hash[:], sig.r, sig.s, sig.recid); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Recover(%x, %x, %d) = %v", sig.r, sig.s, sig.recid, err)
+		}
+	}
+}
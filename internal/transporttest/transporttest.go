@@ -0,0 +1,119 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package transporttest provides a client.Transport serving canned responses, for tests of the
+// API packages and of the helpers built on them.
+package transporttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Request is a request received by a Transport. Params is set for GET requests and Body for POST
+// requests.
+type Request struct {
+	Path   string
+	Params map[string]string
+	Body   any
+}
+
+// HandlerFunc returns the JSON response data of a request, or an error to fail it with.
+type HandlerFunc func(req *Request) (string, error)
+
+// Transport serves canned JSON response data by request path and records the requests. It is
+// safe for concurrent use.
+type Transport struct {
+	mu       sync.Mutex
+	handlers map[string]HandlerFunc
+	requests []*Request
+}
+
+// New creates a Transport serving data, which maps request paths to JSON response data.
+func New(data map[string]string) *Transport {
+	t := &Transport{handlers: make(map[string]HandlerFunc)}
+	for path, d := range data {
+		t.Set(path, d)
+	}
+	return t
+}
+
+// Set serves data for path.
+func (t *Transport) Set(path, data string) {
+	t.Handle(path, func(*Request) (string, error) {
+		return data, nil
+	})
+}
+
+// Handle serves the requests to path with h.
+func (t *Transport) Handle(path string, h HandlerFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[path] = h
+}
+
+// Delete stops serving path, so requests to it fail.
+func (t *Transport) Delete(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.handlers, path)
+}
+
+// Calls returns the number of requests made to path.
+func (t *Transport) Calls(path string) int {
+	return len(t.Requests(path))
+}
+
+// Requests returns the requests made to path, in order.
+func (t *Transport) Requests(path string) []*Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var reqs []*Request
+	for _, r := range t.requests {
+		if r.Path == path {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+func (t *Transport) Get(ctx context.Context, path string, params map[string]string, result any) error {
+	return t.serve(&Request{Path: path, Params: params}, result)
+}
+
+func (t *Transport) Post(ctx context.Context, path string, body any, result any) error {
+	return t.serve(&Request{Path: path, Body: body}, result)
+}
+
+func (t *Transport) serve(req *Request, result any) error {
+	t.mu.Lock()
+	t.requests = append(t.requests, req)
+	h, ok := t.handlers[req.Path]
+	t.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unexpected request to %s", req.Path)
+	}
+	data, err := h(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), result)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package risk scores tokens from the risk signals of the DEX and wallet APIs: honeypot and tax
+// flags of quotes, risk flags of wallet balances, market data of the project and interceptions
+// by the DEX API. A Scorer can be passed to dex.WithGuard to refuse risky tokens before swap
+// calldata is returned.
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/types"
+)
+
+var ErrRiskyToken = errors.New("risk: risky token")
+
+// Reason codes.
+const (
+	ReasonHoneyPot     = "honeypot"
+	ReasonIntercepted  = "intercepted"
+	ReasonHighTax      = "high_tax"
+	ReasonWalletRisk   = "wallet_risk"
+	ReasonLowMarketCap = "low_market_cap"
+	ReasonLowVolume    = "low_volume"
+	ReasonNoProject    = "no_project_info"
+)
+
+// Signals are the risk signals known about a token.
+type Signals struct {
+	ChainId      chains.ChainID `json:"chainId"`
+	TokenAddress string         `json:"tokenAddress"`
+	// HoneyPot and TaxRate come from dex.TokenInfo.
+	HoneyPot bool          `json:"honeyPot"`
+	TaxRate  types.Decimal `json:"taxRate"`
+	// WalletRisk comes from wallet.TokenBalance.IsRiskToken.
+	WalletRisk bool `json:"walletRisk"`
+	// Intercepted is set when the DEX API refused a transaction of the token.
+	Intercepted bool `json:"intercepted"`
+	// ProjectChecked reports whether wallet.ProjectInformation was looked up and HasProject
+	// whether it was found; MarketCap and Volume24h come from it, in USD.
+	ProjectChecked bool          `json:"projectChecked"`
+	HasProject     bool          `json:"hasProject"`
+	MarketCap      types.Decimal `json:"marketCap"`
+	Volume24h      types.Decimal `json:"volume24h"`
+}
+
+// Weights are the score added by each reason. A zero weight disables the reason.
+type Weights struct {
+	HoneyPot, Intercepted, HighTax, WalletRisk, LowMarketCap, LowVolume, NoProject int
+}
+
+// DefaultWeights block honeypots, intercepted tokens, high taxes and tokens flagged by the
+// wallet API on their own; the market signals only block together.
+var DefaultWeights = Weights{
+	HoneyPot:     100,
+	Intercepted:  100,
+	HighTax:      60,
+	WalletRisk:   50,
+	LowMarketCap: 25,
+	LowVolume:    15,
+	NoProject:    20,
+}
+
+// Thresholds configure the scoring. Unset values take their defaults; set a decimal to zero to
+// disable its check.
+type Thresholds struct {
+	// MaxTaxRate is the highest acceptable tax rate, 0.1 (10%) by default.
+	MaxTaxRate types.Decimal
+	// MinMarketCap and MinVolume24h are the lowest acceptable market cap and daily volume in USD,
+	// 100000 and 10000 by default.
+	MinMarketCap types.Decimal
+	MinVolume24h types.Decimal
+	// BlockScore is the score from which a token is refused, 50 by default.
+	BlockScore int
+	// Weights default to DefaultWeights.
+	Weights *Weights
+}
+
+func (t Thresholds) withDefaults() Thresholds {
+	if !t.MaxTaxRate.IsSet() {
+		t.MaxTaxRate = types.MustParseDecimal("0.1")
+	}
+	if !t.MinMarketCap.IsSet() {
+		t.MinMarketCap = types.DecimalFromInt64(100_000)
+	}
+	if !t.MinVolume24h.IsSet() {
+		t.MinVolume24h = types.DecimalFromInt64(10_000)
+	}
+	if t.BlockScore <= 0 {
+		t.BlockScore = 50
+	}
+	if t.Weights == nil {
+		w := DefaultWeights
+		t.Weights = &w
+	}
+	return t
+}
+
+// Reason is a finding that contributed to a score.
+type Reason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Weight  int    `json:"weight"`
+}
+
+// Assessment is the risk score of a token.
+type Assessment struct {
+	ChainId      chains.ChainID `json:"chainId"`
+	TokenAddress string         `json:"tokenAddress"`
+	Score        int            `json:"score"`
+	Blocked      bool           `json:"blocked"`
+	Reasons      []Reason       `json:"reasons"`
+	Signals      Signals        `json:"signals"`
+}
+
+// Err returns a *Error if the token is blocked.
+func (a *Assessment) Err() error {
+	if !a.Blocked {
+		return nil
+	}
+	return &Error{Assessment: a}
+}
+
+// Error is returned for blocked tokens. It matches ErrRiskyToken.
+type Error struct {
+	Assessment *Assessment
+}
+
+func (e *Error) Error() string {
+	codes := make([]string, 0, len(e.Assessment.Reasons))
+	for _, r := range e.Assessment.Reasons {
+		codes = append(codes, r.Code)
+	}
+	return fmt.Sprintf("risk: token %s on chain %s refused with score %d (%s)",
+		e.Assessment.TokenAddress, e.Assessment.ChainId, e.Assessment.Score, strings.Join(codes, ", "))
+}
+
+func (e *Error) Is(target error) bool {
+	return target == ErrRiskyToken
+}
+
+// Score scores signals against t.
+func (t Thresholds) Score(s Signals) *Assessment {
+	t = t.withDefaults()
+	w := t.Weights
+	a := &Assessment{ChainId: s.ChainId, TokenAddress: s.TokenAddress, Signals: s}
+	add := func(code string, weight int, format string, args ...any) {
+		if weight == 0 {
+			return
+		}
+		a.Reasons = append(a.Reasons, Reason{Code: code, Message: fmt.Sprintf(format, args...), Weight: weight})
+		a.Score += weight
+	}
+
+	if s.HoneyPot {
+		add(ReasonHoneyPot, w.HoneyPot, "flagged as a honeypot")
+	}
+	if s.Intercepted {
+		add(ReasonIntercepted, w.Intercepted, "transactions intercepted by the DEX API")
+	}
+	if !t.MaxTaxRate.IsZero() && s.TaxRate.Cmp(t.MaxTaxRate) > 0 {
+		add(ReasonHighTax, w.HighTax, "tax rate %s above %s", s.TaxRate.Text(), t.MaxTaxRate.Text())
+	}
+	if s.WalletRisk {
+		add(ReasonWalletRisk, w.WalletRisk, "flagged as a risk token by the wallet API")
+	}
+	if s.ProjectChecked && !s.HasProject {
+		add(ReasonNoProject, w.NoProject, "no project information")
+	} else if s.HasProject {
+		if !t.MinMarketCap.IsZero() && s.MarketCap.Cmp(t.MinMarketCap) < 0 {
+			add(ReasonLowMarketCap, w.LowMarketCap, "market cap %s below %s", s.MarketCap.Text(), t.MinMarketCap.Text())
+		}
+		if !t.MinVolume24h.IsZero() && s.Volume24h.Cmp(t.MinVolume24h) < 0 {
+			add(ReasonLowVolume, w.LowVolume, "24h volume %s below %s", s.Volume24h.Text(), t.MinVolume24h.Text())
+		}
+	}
+	a.Blocked = a.Score >= t.BlockScore
+	return a
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+type fakeProjects map[string]*wallet.ProjectInformation
+
+func (f fakeProjects) ProjectInformation(ctx context.Context, req *wallet.ProjectInformationRequest) (*wallet.ProjectInformation, error) {
+	if info, ok := f[req.TokenAddress]; ok {
+		return info, nil
+	}
+	return nil, errcode.ErrResultsNotFound
+}
+
+const (
+	good = "0x1111111111111111111111111111111111111111"
+	bad  = "0x2222222222222222222222222222222222222222"
+)
+
+func TestScore(t *testing.T) {
+	var th Thresholds
+	a := th.Score(Signals{ProjectChecked: true, HasProject: true,
+		MarketCap: types.DecimalFromInt64(1_000_000), Volume24h: types.DecimalFromInt64(50_000)})
+	if a.Score != 0 || a.Blocked {
+		t.Fatalf("clean token = %+v", a)
+	}
+
+	a = th.Score(Signals{TaxRate: types.MustParseDecimal("0.15")})
+	if !a.Blocked || len(a.Reasons) != 1 || a.Reasons[0].Code != ReasonHighTax {
+		t.Fatalf("taxed token = %+v", a)
+	}
+	if !errors.Is(a.Err(), ErrRiskyToken) {
+		t.Fatalf("err = %v", a.Err())
+	}
+
+	th.MaxTaxRate = types.DecimalFromInt64(0)
+	if a = th.Score(Signals{TaxRate: types.MustParseDecimal("0.15")}); a.Blocked {
+		t.Fatalf("disabled tax check = %+v", a)
+	}
+
+	// missing project info alone stays below the block score.
+	a = th.Score(Signals{ProjectChecked: true})
+	if a.Score != 20 || a.Blocked {
+		t.Fatalf("unknown project = %+v", a)
+	}
+}
+
+func TestScorerGuard(t *testing.T) {
+	projects := fakeProjects{good: {MarketCap: types.DecimalFromInt64(5_000_000), Volume24h: types.DecimalFromInt64(200_000)}}
+	s := NewScorer(projects, Thresholds{})
+	ctx := context.Background()
+
+	if err := s.CheckToken(ctx, chains.Ethereum, good, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CheckToken(ctx, chains.Ethereum, "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.CheckToken(ctx, chains.Ethereum, bad, &dex.TokenInfo{IsHoneyPot: true})
+	var riskErr *Error
+	if !errors.As(err, &riskErr) || riskErr.Assessment.Score != 120 {
+		t.Fatalf("honeypot err = %v", err)
+	}
+
+	if !s.Observe(errcode.New(82120, "intercepted"), chains.Ethereum, "0x1111111111111111111111111111111111111111") {
+		t.Fatal("interception not observed")
+	}
+	if err := s.CheckToken(ctx, chains.Ethereum, good, nil); !errors.Is(err, ErrRiskyToken) {
+		t.Fatalf("intercepted err = %v", err)
+	}
+
+	s.ObserveBalances([]*wallet.TokenBalance{{ChainIndex: chains.Ethereum, TokenAddress: "0x3333333333333333333333333333333333333333", IsRiskToken: true}})
+	a, err := s.Assess(ctx, chains.Ethereum, "0x3333333333333333333333333333333333333333")
+	if err != nil || a.Score != 70 || !a.Blocked {
+		t.Fatalf("wallet risk = %+v, %v", a, err)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package risk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+// ProjectSource looks up the project information of tokens. *wallet.WalletAPI implements it.
+type ProjectSource interface {
+	ProjectInformation(ctx context.Context, req *wallet.ProjectInformationRequest) (*wallet.ProjectInformation, error)
+}
+
+type Options struct {
+	cacheTTL time.Duration
+	now      func() time.Time
+}
+
+type Option interface {
+	apply(o *Options)
+}
+
+type optionFunc func(o *Options)
+
+func (f optionFunc) apply(o *Options) {
+	f(o)
+}
+
+// WithCacheTTL sets how long project information is cached, 10 minutes by default.
+func WithCacheTTL(ttl time.Duration) Option {
+	return optionFunc(func(o *Options) {
+		o.cacheTTL = ttl
+	})
+}
+
+type tokenKey struct {
+	chainId chains.ChainID
+	address string
+}
+
+func newTokenKey(chainId chains.ChainID, tokenAddress string) tokenKey {
+	if strings.HasPrefix(tokenAddress, "0x") || strings.HasPrefix(tokenAddress, "0X") {
+		tokenAddress = strings.ToLower(tokenAddress)
+	}
+	return tokenKey{chainId: chainId, address: tokenAddress}
+}
+
+type entry struct {
+	signals   Signals
+	checkedAt time.Time
+}
+
+// Scorer collects the risk signals of tokens and scores them. Signals observed from quotes,
+// balances and errors are kept for the life of the Scorer; project information is fetched on
+// demand and cached.
+//
+// A Scorer implements dex.Guard, so it can be passed to dex.WithGuard.
+type Scorer struct {
+	projects   ProjectSource
+	thresholds Thresholds
+	opts       Options
+
+	mu     sync.Mutex
+	tokens map[tokenKey]*entry
+}
+
+// NewScorer creates a Scorer. projects may be nil, in which case the market checks are skipped.
+func NewScorer(projects ProjectSource, thresholds Thresholds, opts ...Option) *Scorer {
+	o := Options{
+		cacheTTL: 10 * time.Minute,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+	return &Scorer{
+		projects:   projects,
+		thresholds: thresholds,
+		opts:       o,
+		tokens:     make(map[tokenKey]*entry),
+	}
+}
+
+func (s *Scorer) entry(chainId chains.ChainID, tokenAddress string) *entry {
+	key := newTokenKey(chainId, tokenAddress)
+	e, ok := s.tokens[key]
+	if !ok {
+		e = &entry{signals: Signals{ChainId: chainId, TokenAddress: tokenAddress}}
+		s.tokens[key] = e
+	}
+	return e
+}
+
+// ObserveTokenInfo records the honeypot flag and tax rate of a token from a quote.
+func (s *Scorer) ObserveTokenInfo(chainId chains.ChainID, info *dex.TokenInfo) {
+	if info == nil || info.TokenContractAddress == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entry(chainId, info.TokenContractAddress)
+	e.signals.HoneyPot = e.signals.HoneyPot || info.IsHoneyPot
+	if info.TaxRate.IsSet() {
+		e.signals.TaxRate = info.TaxRate
+	}
+}
+
+// ObserveBalances records the risk flags of wallet balances.
+func (s *Scorer) ObserveBalances(balances []*wallet.TokenBalance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range balances {
+		if b == nil || !b.IsRiskToken {
+			continue
+		}
+		s.entry(b.ChainIndex, b.TokenAddress).signals.WalletRisk = true
+	}
+}
+
+// Observe marks tokens as intercepted if err reports that the DEX API intercepted their
+// transaction, and reports whether it did. Pass the tokens of the refused swap.
+func (s *Scorer) Observe(err error, chainId chains.ChainID, tokenAddresses ...string) bool {
+	if !dex.IsTransactionIntercepted(err) {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range tokenAddresses {
+		s.entry(chainId, token).signals.Intercepted = true
+	}
+	return true
+}
+
+// Assess scores the token at tokenAddress on chainId. Native tokens are never risky. It fails if
+// the project information cannot be fetched for reasons other than the token being unknown.
+func (s *Scorer) Assess(ctx context.Context, chainId chains.ChainID, tokenAddress string) (*Assessment, error) {
+	if c, ok := chains.Lookup(chainId); ok && c.IsNativeToken(tokenAddress) {
+		signals := Signals{ChainId: chainId, TokenAddress: tokenAddress}
+		return &Assessment{ChainId: chainId, TokenAddress: tokenAddress, Signals: signals}, nil
+	}
+
+	s.mu.Lock()
+	e := s.entry(chainId, tokenAddress)
+	stale := s.projects != nil && (!e.signals.ProjectChecked || s.opts.now().Sub(e.checkedAt) >= s.opts.cacheTTL)
+	s.mu.Unlock()
+
+	if stale {
+		info, err := s.projects.ProjectInformation(ctx, &wallet.ProjectInformationRequest{
+			ChainIndex:   chainId,
+			TokenAddress: tokenAddress,
+		})
+		if err != nil && !errors.Is(err, errcode.ErrResultsNotFound) {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		e.signals.ProjectChecked = true
+		e.signals.HasProject = info != nil
+		e.signals.MarketCap, e.signals.Volume24h = types.Decimal{}, types.Decimal{}
+		if info != nil {
+			e.signals.MarketCap, e.signals.Volume24h = info.MarketCap, info.Volume24h
+		}
+		e.checkedAt = s.opts.now()
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	signals := e.signals
+	s.mu.Unlock()
+	return s.thresholds.Score(signals), nil
+}
+
+// CheckToken records info, if given, and refuses the token with an *Error if it is blocked.
+func (s *Scorer) CheckToken(ctx context.Context, chainId chains.ChainID, tokenAddress string, info *dex.TokenInfo) error {
+	if info != nil {
+		if info.TokenContractAddress == "" {
+			copied := *info
+			copied.TokenContractAddress = tokenAddress
+			info = &copied
+		}
+		s.ObserveTokenInfo(chainId, info)
+	}
+	a, err := s.Assess(ctx, chainId, tokenAddress)
+	if err != nil {
+		return err
+	}
+	return a.Err()
+}
+
+var _ dex.Guard = (*Scorer)(nil)
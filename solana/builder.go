@@ -0,0 +1,176 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/internal/base58"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+// MaxTransactionSize is the largest transaction the network accepts, in bytes.
+const MaxTransactionSize = 1232
+
+var ErrTransactionTooLarge = errors.New("solana: transaction too large")
+
+// LookupTableResolver fetches the contents of address lookup tables, usually with the
+// getAddressLookupTable RPC of a Solana node.
+type LookupTableResolver interface {
+	ResolveLookupTables(ctx context.Context, keys []PublicKey) ([]LookupTable, error)
+}
+
+// LookupTableResolverFunc adapts a function to a LookupTableResolver.
+type LookupTableResolverFunc func(ctx context.Context, keys []PublicKey) ([]LookupTable, error)
+
+func (f LookupTableResolverFunc) ResolveLookupTables(ctx context.Context, keys []PublicKey) ([]LookupTable, error) {
+	return f(ctx, keys)
+}
+
+// StaticLookupTables resolves lookup tables from known contents. Unknown tables are skipped.
+type StaticLookupTables map[PublicKey][]PublicKey
+
+func (s StaticLookupTables) ResolveLookupTables(ctx context.Context, keys []PublicKey) ([]LookupTable, error) {
+	tables := make([]LookupTable, 0, len(keys))
+	for _, key := range keys {
+		if addrs, ok := s[key]; ok {
+			tables = append(tables, LookupTable{Key: key, Addresses: addrs})
+		}
+	}
+	return tables, nil
+}
+
+// BuildRequest is the input of Builder.Build.
+type BuildRequest struct {
+	// Payer is the wallet that pays the fees and signs the swap, Required
+	Payer string
+	// Swap is the result of DexAPI.GetSolSwapInstruction, Required
+	Swap *dex.GetSolSwapInstructionResult
+	// SignInfo is the result of WalletAPI.GetSignInfo for the payer; its RecentBlockHash is used, Required
+	SignInfo *wallet.SignInfoSolana
+	// ComputeUnitPrice in micro-lamports replaces the price set by the swap instructions
+	ComputeUnitPrice string
+	// ComputeUnitLimit replaces the limit set by the swap instructions
+	ComputeUnitLimit string
+}
+
+// Builder assembles versioned transactions from swap instructions.
+type Builder struct {
+	resolver LookupTableResolver
+}
+
+// NewBuilder returns a Builder that loads the lookup tables of the swaps with resolver.
+// Without a resolver, all accounts are static, which only fits small swaps.
+func NewBuilder(resolver LookupTableResolver) *Builder {
+	return &Builder{resolver: resolver}
+}
+
+// Build returns the unsigned transaction of req. Sign it with Transaction.Sign and broadcast
+// Transaction.SignedTx.
+func (b *Builder) Build(ctx context.Context, req *BuildRequest) (*Transaction, error) {
+	if req.Swap == nil || req.SignInfo == nil {
+		return nil, errors.New("solana: swap and sign info are required")
+	}
+	payer, err := ParsePublicKey(req.Payer)
+	if err != nil {
+		return nil, err
+	}
+	var blockhash [32]byte
+	h, err := base58.Decode(req.SignInfo.RecentBlockHash)
+	if err != nil || len(h) != len(blockhash) {
+		return nil, fmt.Errorf("solana: invalid recent blockhash %q", req.SignInfo.RecentBlockHash)
+	}
+	copy(blockhash[:], h)
+
+	instructions, err := SwapInstructions(req.Swap)
+	if err != nil {
+		return nil, err
+	}
+	if instructions, err = withComputeBudget(instructions, req.ComputeUnitLimit, req.ComputeUnitPrice); err != nil {
+		return nil, err
+	}
+
+	var tables []LookupTable
+	if b.resolver != nil && len(req.Swap.AddressLookupTableAccount) > 0 {
+		keys := make([]PublicKey, 0, len(req.Swap.AddressLookupTableAccount))
+		for _, s := range req.Swap.AddressLookupTableAccount {
+			key, err := ParsePublicKey(s)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+		if tables, err = b.resolver.ResolveLookupTables(ctx, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := CompileMessage(payer, instructions, blockhash, tables)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := m.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if size := len(appendCompactU16(nil, int(m.Header.NumRequiredSignatures))) + 64*int(m.Header.NumRequiredSignatures) + len(msg); size > MaxTransactionSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTransactionTooLarge, size)
+	}
+	return NewTransaction(m), nil
+}
+
+// withComputeBudget replaces the compute budget instructions of instructions by the given limit
+// and price, when set.
+func withComputeBudget(instructions []Instruction, limit, price string) ([]Instruction, error) {
+	var budget []Instruction
+	replaced := make(map[byte]bool)
+	if limit != "" {
+		n, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("solana: invalid compute unit limit %q", limit)
+		}
+		budget = append(budget, SetComputeUnitLimit(uint32(n)))
+		replaced[2] = true
+	}
+	if price != "" {
+		n, err := strconv.ParseUint(price, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("solana: invalid compute unit price %q", price)
+		}
+		budget = append(budget, SetComputeUnitPrice(n))
+		replaced[3] = true
+	}
+	if len(budget) == 0 {
+		return instructions, nil
+	}
+
+	out := budget
+	for _, ins := range instructions {
+		if ins.ProgramID == ComputeBudgetProgram && len(ins.Data) > 0 && replaced[ins.Data[0]] {
+			continue
+		}
+		out = append(out, ins)
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package solana
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/internal/base58"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+func key(b byte) PublicKey {
+	var k PublicKey
+	k[0], k[31] = b, b
+	return k
+}
+
+func TestBuild(t *testing.T) {
+	payer, err := NewKeypair(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, pool, mint, table := key(1), key(2), key(3), key(4)
+
+	swap := &dex.GetSolSwapInstructionResult{
+		AddressLookupTableAccount: []string{table.String()},
+		InstructionLists: []dex.InstructionInfo{
+			{ProgramId: ComputeBudgetProgram.String(), Data: base64.StdEncoding.EncodeToString(SetComputeUnitPrice(1).Data)},
+			{ProgramId: ComputeBudgetProgram.String(), Data: base64.StdEncoding.EncodeToString(SetComputeUnitLimit(1).Data)},
+			{
+				ProgramId: program.String(),
+				Data:      base64.StdEncoding.EncodeToString([]byte{9, 9}),
+				Accounts: []dex.AccountInfo{
+					{Pubkey: payer.PublicKey().String(), IsSigner: true, IsWritable: true},
+					{Pubkey: pool.String(), IsWritable: true},
+					{Pubkey: mint.String()},
+				},
+			},
+		},
+	}
+	resolver := StaticLookupTables{table: {mint, pool}}
+	blockhash := base58.Encode(bytes.Repeat([]byte{5}, 32))
+
+	tx, err := NewBuilder(resolver).Build(context.Background(), &BuildRequest{
+		Payer:            payer.PublicKey().String(),
+		Swap:             swap,
+		SignInfo:         &wallet.SignInfoSolana{RecentBlockHash: blockhash},
+		ComputeUnitPrice: "5000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := tx.Message
+	// The payer, the programs; the pool and the mint are loaded from the table.
+	if len(m.StaticKeys) != 3 || m.StaticKeys[0] != payer.PublicKey() {
+		t.Fatalf("static keys = %v", m.StaticKeys)
+	}
+	if m.Header != (MessageHeader{1, 0, 2}) {
+		t.Fatalf("header = %+v", m.Header)
+	}
+	if len(m.Lookups) != 1 || !bytes.Equal(m.Lookups[0].WritableIndexes, []byte{1}) || !bytes.Equal(m.Lookups[0].ReadonlyIndexes, []byte{0}) {
+		t.Fatalf("lookups = %+v", m.Lookups)
+	}
+	// The price is replaced, the limit of the swap is kept.
+	if len(m.Instructions) != 3 || !bytes.Equal(m.Instructions[0].Data, SetComputeUnitPrice(5000).Data) ||
+		!bytes.Equal(m.Instructions[1].Data, SetComputeUnitLimit(1).Data) {
+		t.Fatalf("instructions = %+v", m.Instructions)
+	}
+	// The swap accounts: payer, the writable pool (index 3) and the readonly mint (index 4).
+	if !bytes.Equal(m.Instructions[2].Accounts, []byte{0, 3, 4}) {
+		t.Fatalf("swap accounts = %v", m.Instructions[2].Accounts)
+	}
+
+	if _, err := tx.SignedTx(); err == nil {
+		t.Fatal("unsigned transaction encoded")
+	}
+	if err := tx.Sign(context.Background(), payer); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := tx.SignedTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base58.Decode(signed)
+	msg, _ := m.MarshalBinary()
+	if raw[0] != 1 || msg[0] != 0x80 || !bytes.Equal(raw[65:], msg) {
+		t.Fatalf("raw = %x", raw)
+	}
+	pub := payer.PublicKey()
+	if !ed25519.Verify(pub[:], msg, raw[1:65]) || tx.ID() != base58.Encode(raw[1:65]) {
+		t.Fatal("invalid signature")
+	}
+}
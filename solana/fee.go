@@ -0,0 +1,285 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package solana
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+// MaxComputeUnitLimit is the largest compute unit limit of a transaction.
+const MaxComputeUnitLimit = 1_400_000
+
+var ErrNoPriorityFee = errors.New("solana: sign info has no priority fee")
+
+// FeeLevel selects the compute unit price among those suggested by the sign info.
+type FeeLevel string
+
+const (
+	// FeeEconomy pays the minimum unit price.
+	FeeEconomy FeeLevel = "economy"
+	// FeeNormal pays the normal unit price.
+	FeeNormal FeeLevel = "normal"
+	// FeeUrgent pays the maximum unit price.
+	FeeUrgent FeeLevel = "urgent"
+)
+
+// FeePolicy configures a FeeStrategy. The zero value pays the normal price with the default limits.
+type FeePolicy struct {
+	// Level is the price level, FeeNormal by default.
+	Level FeeLevel
+	// MaxPriorityFee caps the priority fee of a transaction in lamports, unit price times limit.
+	// Zero is no cap.
+	MaxPriorityFee uint64
+	// BaseComputeUnits and UnitsPerInstruction estimate the compute unit limit from the number
+	// of instructions, 150000 and 40000 by default. The limit never exceeds MaxComputeUnitLimit.
+	// Unless either is set, FillBuild keeps the limit the swap instructions were simulated with.
+	BaseComputeUnits    uint32
+	UnitsPerInstruction uint32
+	// LandingWindow is the number of recent transactions whose outcome is remembered, 20 by default.
+	LandingWindow int
+	// TargetLandingRate is the share of transactions expected to land, 0.9 by default. Below it the
+	// price is raised towards the maximum price in proportion to the shortfall.
+	TargetLandingRate float64
+}
+
+// DefaultInstructions is the instruction count assumed when it is not known yet, before the swap
+// instructions are fetched.
+const DefaultInstructions = 6
+
+// Fee is the compute budget of a transaction.
+type Fee struct {
+	// ComputeUnitPrice in micro-lamports
+	ComputeUnitPrice uint64
+	ComputeUnitLimit uint32
+}
+
+// PriorityFee returns the priority fee in lamports, rounded up.
+func (f Fee) PriorityFee() uint64 {
+	n := new(big.Int).SetUint64(f.ComputeUnitPrice)
+	n.Mul(n, big.NewInt(int64(f.ComputeUnitLimit)))
+	n.Add(n, big.NewInt(999_999))
+	return n.Div(n, big.NewInt(1_000_000)).Uint64()
+}
+
+// ApplySwap sets the compute budget of a swap request.
+func (f Fee) ApplySwap(req *dex.GetSwapTxRequest) {
+	req.ComputeUnitPrice = strconv.FormatUint(f.ComputeUnitPrice, 10)
+	req.ComputeUnitLimit = strconv.FormatUint(uint64(f.ComputeUnitLimit), 10)
+}
+
+// ApplyInstruction sets the compute budget of a swap instruction request.
+func (f Fee) ApplyInstruction(req *dex.GetSolSwapInstructionRequest) {
+	req.ComputeUnitPrice = strconv.FormatUint(f.ComputeUnitPrice, 10)
+	req.ComputeUnitLimit = strconv.FormatUint(uint64(f.ComputeUnitLimit), 10)
+}
+
+// ApplyBuild sets the compute budget of a transaction built by Builder.
+func (f Fee) ApplyBuild(req *BuildRequest) {
+	req.ComputeUnitPrice = strconv.FormatUint(f.ComputeUnitPrice, 10)
+	req.ComputeUnitLimit = strconv.FormatUint(uint64(f.ComputeUnitLimit), 10)
+}
+
+// FeeStrategy chooses compute budgets from the sign info and a policy, paying more while recent
+// transactions fail to land. It is safe for concurrent use.
+type FeeStrategy struct {
+	policy   FeePolicy
+	estimate bool
+
+	mu      sync.Mutex
+	landed  []bool
+	next    int
+	samples int
+}
+
+// NewFeeStrategy returns a FeeStrategy for policy.
+func NewFeeStrategy(policy FeePolicy) *FeeStrategy {
+	estimate := policy.BaseComputeUnits != 0 || policy.UnitsPerInstruction != 0
+	if policy.Level == "" {
+		policy.Level = FeeNormal
+	}
+	if policy.BaseComputeUnits == 0 {
+		policy.BaseComputeUnits = 150_000
+	}
+	if policy.UnitsPerInstruction == 0 {
+		policy.UnitsPerInstruction = 40_000
+	}
+	if policy.LandingWindow <= 0 {
+		policy.LandingWindow = 20
+	}
+	if policy.TargetLandingRate <= 0 || policy.TargetLandingRate > 1 {
+		policy.TargetLandingRate = 0.9
+	}
+	return &FeeStrategy{policy: policy, estimate: estimate, landed: make([]bool, policy.LandingWindow)}
+}
+
+// RecordLanding records whether a transaction sent with a chosen fee landed.
+func (s *FeeStrategy) RecordLanding(landed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.landed[s.next] = landed
+	s.next = (s.next + 1) % len(s.landed)
+	if s.samples < len(s.landed) {
+		s.samples++
+	}
+}
+
+// LandingRate returns the share of recent transactions that landed, and 1 without samples.
+func (s *FeeStrategy) LandingRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.landingRate()
+}
+
+func (s *FeeStrategy) landingRate() float64 {
+	if s.samples == 0 {
+		return 1
+	}
+	var n int
+	for i := 0; i < s.samples; i++ {
+		if s.landed[i] {
+			n++
+		}
+	}
+	return float64(n) / float64(s.samples)
+}
+
+// Fee returns the compute budget of a transaction of the given number of instructions,
+// or DefaultInstructions if it is not positive.
+func (s *FeeStrategy) Fee(info *wallet.SignInfoSolana, instructions int) (Fee, error) {
+	if info == nil || info.PriorityFee == nil {
+		return Fee{}, ErrNoPriorityFee
+	}
+	p := s.policy
+	if instructions <= 0 {
+		instructions = DefaultInstructions
+	}
+	return s.fee(info, uint64(p.BaseComputeUnits)+uint64(p.UnitsPerInstruction)*uint64(instructions)), nil
+}
+
+// fee returns the compute budget of a transaction with a limit of units.
+func (s *FeeStrategy) fee(info *wallet.SignInfoSolana, units uint64) Fee {
+	p := s.policy
+	if units > MaxComputeUnitLimit {
+		units = MaxComputeUnitLimit
+	}
+	fee := Fee{ComputeUnitLimit: uint32(units)}
+
+	pf := info.PriorityFee
+	maxPrice := pf.MaxUnitPrice.Big()
+	var price *big.Int
+	switch p.Level {
+	case FeeEconomy:
+		price = pf.MinUnitPrice.Big()
+	case FeeUrgent:
+		price = maxPrice
+	default:
+		price = pf.NormalUnitPrice.Big()
+	}
+
+	// Move the price towards the maximum in proportion to the landing shortfall.
+	s.mu.Lock()
+	rate := s.landingRate()
+	s.mu.Unlock()
+	if rate < p.TargetLandingRate && maxPrice.Cmp(price) > 0 {
+		shortfall := int64((p.TargetLandingRate - rate) / p.TargetLandingRate * 1000)
+		step := new(big.Int).Sub(maxPrice, price)
+		step.Mul(step, big.NewInt(shortfall)).Div(step, big.NewInt(1000))
+		price = new(big.Int).Add(price, step)
+	}
+
+	if p.MaxPriorityFee > 0 && units > 0 {
+		// price <= cap * 1e6 / units
+		limit := new(big.Int).SetUint64(p.MaxPriorityFee)
+		limit.Mul(limit, big.NewInt(1_000_000)).Div(limit, new(big.Int).SetUint64(units))
+		if price.Cmp(limit) > 0 {
+			price = limit
+		}
+	}
+	if !price.IsUint64() {
+		price = new(big.Int).SetUint64(^uint64(0))
+	}
+	fee.ComputeUnitPrice = price.Uint64()
+	return fee
+}
+
+// FillSwap sets the compute budget of a swap request, whose instruction count is not known yet.
+func (s *FeeStrategy) FillSwap(req *dex.GetSwapTxRequest, info *wallet.SignInfoSolana) error {
+	fee, err := s.Fee(info, 0)
+	if err != nil {
+		return err
+	}
+	fee.ApplySwap(req)
+	return nil
+}
+
+// FillInstruction sets the compute budget of a swap instruction request.
+func (s *FeeStrategy) FillInstruction(req *dex.GetSolSwapInstructionRequest, info *wallet.SignInfoSolana) error {
+	fee, err := s.Fee(info, 0)
+	if err != nil {
+		return err
+	}
+	fee.ApplyInstruction(req)
+	return nil
+}
+
+// FillBuild sets the compute budget of a build request from its sign info. The limit set by the
+// swap instructions is kept and only the price is set, unless the policy estimates the limit or
+// the instructions set none, in which case it is estimated from their number.
+func (s *FeeStrategy) FillBuild(req *BuildRequest) error {
+	if req.SignInfo == nil || req.SignInfo.PriorityFee == nil {
+		return ErrNoPriorityFee
+	}
+	var instructions []Instruction
+	if req.Swap != nil {
+		var err error
+		if instructions, err = SwapInstructions(req.Swap); err != nil {
+			return err
+		}
+	}
+	// a zero limit is not a simulation result, the limit is estimated instead.
+	if units, ok := computeUnitLimit(instructions); ok && units > 0 && !s.estimate {
+		fee := s.fee(req.SignInfo, uint64(units))
+		req.ComputeUnitPrice = strconv.FormatUint(fee.ComputeUnitPrice, 10)
+		return nil
+	}
+	fee, err := s.Fee(req.SignInfo, len(instructions))
+	if err != nil {
+		return err
+	}
+	fee.ApplyBuild(req)
+	return nil
+}
+
+// computeUnitLimit returns the limit set by the compute budget instructions of instructions.
+func computeUnitLimit(instructions []Instruction) (uint32, bool) {
+	for _, ins := range instructions {
+		if ins.ProgramID == ComputeBudgetProgram && len(ins.Data) == 5 && ins.Data[0] == 2 {
+			return binary.LittleEndian.Uint32(ins.Data[1:]), true
+		}
+	}
+	return 0, false
+}
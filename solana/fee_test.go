@@ -0,0 +1,120 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package solana
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+func TestFeeStrategy(t *testing.T) {
+	info := &wallet.SignInfoSolana{PriorityFee: &wallet.PriorityFee{
+		MinUnitPrice:    types.Uint256FromUint64(100),
+		NormalUnitPrice: types.Uint256FromUint64(1000),
+		MaxUnitPrice:    types.Uint256FromUint64(5000),
+	}}
+
+	s := NewFeeStrategy(FeePolicy{})
+	fee, err := s.Fee(info, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.ComputeUnitPrice != 1000 || fee.ComputeUnitLimit != 310_000 || fee.PriorityFee() != 310 {
+		t.Fatalf("fee = %+v", fee)
+	}
+
+	// Half of the transactions failed: the price moves 4/9 of the way to the maximum.
+	for i := 0; i < 10; i++ {
+		s.RecordLanding(i%2 == 0)
+	}
+	if fee, _ = s.Fee(info, 4); fee.ComputeUnitPrice != 2776 {
+		t.Fatalf("escalated price = %d", fee.ComputeUnitPrice)
+	}
+
+	// The cap wins over the level.
+	capped := NewFeeStrategy(FeePolicy{Level: FeeUrgent, MaxPriorityFee: 100})
+	var req dex.GetSwapTxRequest
+	if err := capped.FillSwap(&req, info); err != nil {
+		t.Fatal(err)
+	}
+	if req.ComputeUnitPrice != "256" || req.ComputeUnitLimit != "390000" {
+		t.Fatalf("request = %s / %s", req.ComputeUnitPrice, req.ComputeUnitLimit)
+	}
+
+	if _, err := s.Fee(&wallet.SignInfoSolana{}, 1); err != ErrNoPriorityFee {
+		t.Fatalf("missing priority fee error = %v", err)
+	}
+}
+
+func TestFeeStrategyFillBuild(t *testing.T) {
+	info := &wallet.SignInfoSolana{PriorityFee: &wallet.PriorityFee{
+		MinUnitPrice:    types.Uint256FromUint64(100),
+		NormalUnitPrice: types.Uint256FromUint64(1000),
+		MaxUnitPrice:    types.Uint256FromUint64(5000),
+	}}
+	swap := &dex.GetSolSwapInstructionResult{InstructionLists: []dex.InstructionInfo{
+		{ProgramId: ComputeBudgetProgram.String(), Data: base64.StdEncoding.EncodeToString(SetComputeUnitLimit(80_000).Data)},
+		{ProgramId: key(1).String(), Data: base64.StdEncoding.EncodeToString([]byte{9})},
+	}}
+
+	// The simulated limit of the swap is kept, and caps the price with the fee.
+	req := &BuildRequest{Swap: swap, SignInfo: info}
+	if err := NewFeeStrategy(FeePolicy{MaxPriorityFee: 40}).FillBuild(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.ComputeUnitPrice != "500" || req.ComputeUnitLimit != "" {
+		t.Fatalf("request = %s / %s", req.ComputeUnitPrice, req.ComputeUnitLimit)
+	}
+
+	// A policy that estimates the limit replaces it.
+	req = &BuildRequest{Swap: swap, SignInfo: info}
+	if err := NewFeeStrategy(FeePolicy{BaseComputeUnits: 100_000}).FillBuild(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.ComputeUnitPrice != "1000" || req.ComputeUnitLimit != "180000" {
+		t.Fatalf("estimated request = %s / %s", req.ComputeUnitPrice, req.ComputeUnitLimit)
+	}
+
+	// Without a limit in the swap, it is estimated.
+	req = &BuildRequest{Swap: &dex.GetSolSwapInstructionResult{InstructionLists: swap.InstructionLists[1:]}, SignInfo: info}
+	if err := NewFeeStrategy(FeePolicy{}).FillBuild(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.ComputeUnitLimit != "190000" {
+		t.Fatalf("limit = %s", req.ComputeUnitLimit)
+	}
+
+	// A zero limit in the swap is estimated too.
+	zero := &dex.GetSolSwapInstructionResult{InstructionLists: []dex.InstructionInfo{
+		{ProgramId: ComputeBudgetProgram.String(), Data: base64.StdEncoding.EncodeToString(SetComputeUnitLimit(0).Data)},
+		swap.InstructionLists[1],
+	}}
+	req = &BuildRequest{Swap: zero, SignInfo: info}
+	if err := NewFeeStrategy(FeePolicy{MaxPriorityFee: 46}).FillBuild(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.ComputeUnitPrice != "200" || req.ComputeUnitLimit != "230000" {
+		t.Fatalf("zero limit request = %s / %s", req.ComputeUnitPrice, req.ComputeUnitLimit)
+	}
+}
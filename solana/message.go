@@ -0,0 +1,196 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package solana
+
+import "fmt"
+
+// MessageHeader counts the kinds of static accounts of a message.
+type MessageHeader struct {
+	NumRequiredSignatures       uint8
+	NumReadonlySignedAccounts   uint8
+	NumReadonlyUnsignedAccounts uint8
+}
+
+// CompiledInstruction is an instruction whose program and accounts are indexes into the
+// accounts of the message.
+type CompiledInstruction struct {
+	ProgramIDIndex uint8
+	Accounts       []uint8
+	Data           []byte
+}
+
+// LookupTable is an address lookup table account and the addresses it holds.
+type LookupTable struct {
+	Key       PublicKey
+	Addresses []PublicKey
+}
+
+// MessageLookup loads accounts of a message from an address lookup table.
+type MessageLookup struct {
+	AccountKey      PublicKey
+	WritableIndexes []uint8
+	ReadonlyIndexes []uint8
+}
+
+// Message is a v0 message. The accounts of the instructions are the static keys followed by
+// the writable and then the readonly accounts loaded from the lookup tables.
+type Message struct {
+	Header          MessageHeader
+	StaticKeys      []PublicKey
+	RecentBlockhash [32]byte
+	Instructions    []CompiledInstruction
+	Lookups         []MessageLookup
+}
+
+type accountFlags struct {
+	signer, writable, invoked bool
+}
+
+// CompileMessage compiles instructions paid by payer into a v0 message. Accounts that are
+// neither signers nor programs are loaded from tables when one of them holds them.
+func CompileMessage(payer PublicKey, instructions []Instruction, blockhash [32]byte, tables []LookupTable) (*Message, error) {
+	flags := map[PublicKey]*accountFlags{payer: {signer: true, writable: true}}
+	order := []PublicKey{payer}
+	mark := func(key PublicKey) *accountFlags {
+		f, ok := flags[key]
+		if !ok {
+			f = new(accountFlags)
+			flags[key] = f
+			order = append(order, key)
+		}
+		return f
+	}
+	for _, ins := range instructions {
+		for _, acc := range ins.Accounts {
+			f := mark(acc.PublicKey)
+			f.signer = f.signer || acc.IsSigner
+			f.writable = f.writable || acc.IsWritable
+		}
+		mark(ins.ProgramID).invoked = true
+	}
+
+	// Accounts found in a lookup table, in the order of the tables.
+	looked := make(map[PublicKey]bool)
+	lookups := make([]MessageLookup, 0, len(tables))
+	var writableLoaded, readonlyLoaded []PublicKey
+	for _, table := range tables {
+		lookup := MessageLookup{AccountKey: table.Key}
+		for i, addr := range table.Addresses {
+			f, ok := flags[addr]
+			if !ok || f.signer || f.invoked || looked[addr] {
+				continue
+			}
+			if i > 255 {
+				break
+			}
+			looked[addr] = true
+			if f.writable {
+				lookup.WritableIndexes = append(lookup.WritableIndexes, uint8(i))
+				writableLoaded = append(writableLoaded, addr)
+			} else {
+				lookup.ReadonlyIndexes = append(lookup.ReadonlyIndexes, uint8(i))
+				readonlyLoaded = append(readonlyLoaded, addr)
+			}
+		}
+		if len(lookup.WritableIndexes)+len(lookup.ReadonlyIndexes) > 0 {
+			lookups = append(lookups, lookup)
+		}
+	}
+
+	// Static keys: writable signers (the payer first), readonly signers, writable and readonly non-signers.
+	var groups [4][]PublicKey
+	for _, key := range order {
+		if looked[key] {
+			continue
+		}
+		f := flags[key]
+		switch {
+		case f.signer && f.writable:
+			groups[0] = append(groups[0], key)
+		case f.signer:
+			groups[1] = append(groups[1], key)
+		case f.writable:
+			groups[2] = append(groups[2], key)
+		default:
+			groups[3] = append(groups[3], key)
+		}
+	}
+
+	m := &Message{RecentBlockhash: blockhash, Lookups: lookups}
+	for _, g := range groups {
+		m.StaticKeys = append(m.StaticKeys, g...)
+	}
+	all := append(append(append([]PublicKey(nil), m.StaticKeys...), writableLoaded...), readonlyLoaded...)
+	if len(all) > 256 {
+		return nil, fmt.Errorf("%w: %d", ErrTooManyAccounts, len(all))
+	}
+	m.Header = MessageHeader{
+		NumRequiredSignatures:       uint8(len(groups[0]) + len(groups[1])),
+		NumReadonlySignedAccounts:   uint8(len(groups[1])),
+		NumReadonlyUnsignedAccounts: uint8(len(groups[3])),
+	}
+
+	index := make(map[PublicKey]uint8, len(all))
+	for i, key := range all {
+		index[key] = uint8(i)
+	}
+	for _, ins := range instructions {
+		c := CompiledInstruction{ProgramIDIndex: index[ins.ProgramID], Data: ins.Data}
+		for _, acc := range ins.Accounts {
+			c.Accounts = append(c.Accounts, index[acc.PublicKey])
+		}
+		m.Instructions = append(m.Instructions, c)
+	}
+	return m, nil
+}
+
+// Signers returns the accounts that must sign the message, the fee payer first.
+func (m *Message) Signers() []PublicKey {
+	return m.StaticKeys[:m.Header.NumRequiredSignatures]
+}
+
+// MarshalBinary returns the serialized message, the bytes that are signed.
+func (m *Message) MarshalBinary() ([]byte, error) {
+	b := []byte{0x80, m.Header.NumRequiredSignatures, m.Header.NumReadonlySignedAccounts, m.Header.NumReadonlyUnsignedAccounts}
+	b = appendCompactU16(b, len(m.StaticKeys))
+	for _, key := range m.StaticKeys {
+		b = append(b, key[:]...)
+	}
+	b = append(b, m.RecentBlockhash[:]...)
+
+	b = appendCompactU16(b, len(m.Instructions))
+	for _, ins := range m.Instructions {
+		b = append(b, ins.ProgramIDIndex)
+		b = appendCompactU16(b, len(ins.Accounts))
+		b = append(b, ins.Accounts...)
+		b = appendCompactU16(b, len(ins.Data))
+		b = append(b, ins.Data...)
+	}
+
+	b = appendCompactU16(b, len(m.Lookups))
+	for _, l := range m.Lookups {
+		b = append(b, l.AccountKey[:]...)
+		b = appendCompactU16(b, len(l.WritableIndexes))
+		b = append(b, l.WritableIndexes...)
+		b = appendCompactU16(b, len(l.ReadonlyIndexes))
+		b = append(b, l.ReadonlyIndexes...)
+	}
+	return b, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package solana assembles, signs and encodes Solana versioned transactions from the swap
+// instructions of the DEX API, for broadcasting through wallet.TransactionBroadcast.
+package solana
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/internal/base58"
+)
+
+var (
+	ErrInvalidPublicKey = errors.New("solana: invalid public key")
+	ErrTooManyAccounts  = errors.New("solana: too many accounts")
+	ErrMissingSignature = errors.New("solana: missing signature")
+)
+
+// PublicKey is a 32 byte Solana account address.
+type PublicKey [32]byte
+
+// ComputeBudgetProgram is the address of the compute budget program.
+var ComputeBudgetProgram = MustParsePublicKey("ComputeBudget111111111111111111111111111111")
+
+// ParsePublicKey parses a base58 encoded address.
+func ParsePublicKey(s string) (PublicKey, error) {
+	var k PublicKey
+	b, err := base58.Decode(s)
+	if err != nil || len(b) != len(k) {
+		return k, fmt.Errorf("%w: %q", ErrInvalidPublicKey, s)
+	}
+	copy(k[:], b)
+	return k, nil
+}
+
+// MustParsePublicKey is like ParsePublicKey but panics on error.
+func MustParsePublicKey(s string) PublicKey {
+	k, err := ParsePublicKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// String returns the base58 encoding of k.
+func (k PublicKey) String() string {
+	return base58.Encode(k[:])
+}
+
+func (k PublicKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *PublicKey) UnmarshalText(text []byte) (err error) {
+	*k, err = ParsePublicKey(string(text))
+	return
+}
+
+// AccountMeta is an account referenced by an instruction.
+type AccountMeta struct {
+	PublicKey  PublicKey
+	IsSigner   bool
+	IsWritable bool
+}
+
+// Instruction is a call of a program.
+type Instruction struct {
+	ProgramID PublicKey
+	Accounts  []AccountMeta
+	Data      []byte
+}
+
+// SwapInstructions converts the instructions returned by DexAPI.GetSolSwapInstruction.
+// Their data is base64 encoded.
+func SwapInstructions(result *dex.GetSolSwapInstructionResult) ([]Instruction, error) {
+	out := make([]Instruction, 0, len(result.InstructionLists))
+	for i, info := range result.InstructionLists {
+		program, err := ParsePublicKey(info.ProgramId)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: %w", i, err)
+		}
+		data, err := base64.StdEncoding.DecodeString(info.Data)
+		if err != nil {
+			return nil, fmt.Errorf("solana: instruction %d: invalid data: %w", i, err)
+		}
+		ins := Instruction{ProgramID: program, Data: data, Accounts: make([]AccountMeta, 0, len(info.Accounts))}
+		for _, acc := range info.Accounts {
+			key, err := ParsePublicKey(acc.Pubkey)
+			if err != nil {
+				return nil, fmt.Errorf("instruction %d: %w", i, err)
+			}
+			ins.Accounts = append(ins.Accounts, AccountMeta{PublicKey: key, IsSigner: acc.IsSigner, IsWritable: acc.IsWritable})
+		}
+		out = append(out, ins)
+	}
+	return out, nil
+}
+
+// SetComputeUnitLimit returns the compute budget instruction that sets the compute unit limit.
+func SetComputeUnitLimit(units uint32) Instruction {
+	data := make([]byte, 5)
+	data[0] = 2
+	binary.LittleEndian.PutUint32(data[1:], units)
+	return Instruction{ProgramID: ComputeBudgetProgram, Data: data}
+}
+
+// SetComputeUnitPrice returns the compute budget instruction that sets the compute unit price
+// in micro-lamports.
+func SetComputeUnitPrice(microLamports uint64) Instruction {
+	data := make([]byte, 9)
+	data[0] = 3
+	binary.LittleEndian.PutUint64(data[1:], microLamports)
+	return Instruction{ProgramID: ComputeBudgetProgram, Data: data}
+}
+
+// appendCompactU16 appends n in Solana's compact-u16 encoding.
+func appendCompactU16(b []byte, n int) []byte {
+	for {
+		c := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package solana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/imzhongqi/okxos/internal/base58"
+)
+
+// Signer signs messages with the key of a single account. Keypair is the local implementation;
+// keys held elsewhere can be used by implementing this interface.
+type Signer interface {
+	PublicKey() PublicKey
+	// Sign returns the 64 byte ed25519 signature of message.
+	Sign(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// Keypair is an ed25519 key held in memory.
+type Keypair struct {
+	key ed25519.PrivateKey
+}
+
+// NewKeypair returns the keypair of a 32 byte seed or a 64 byte secret key.
+func NewKeypair(b []byte) (*Keypair, error) {
+	switch len(b) {
+	case ed25519.SeedSize:
+		return &Keypair{key: ed25519.NewKeyFromSeed(b)}, nil
+	case ed25519.PrivateKeySize:
+		key := ed25519.NewKeyFromSeed(b[:ed25519.SeedSize])
+		if string(key[ed25519.SeedSize:]) != string(b[ed25519.SeedSize:]) {
+			return nil, fmt.Errorf("solana: secret key does not match its public key")
+		}
+		return &Keypair{key: key}, nil
+	}
+	return nil, fmt.Errorf("solana: invalid secret key length %d", len(b))
+}
+
+// KeypairFromBase58 parses a base58 encoded 64 byte secret key, as exported by wallets.
+func KeypairFromBase58(s string) (*Keypair, error) {
+	b, err := base58.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("solana: invalid secret key: %w", err)
+	}
+	return NewKeypair(b)
+}
+
+func (k *Keypair) PublicKey() PublicKey {
+	var pub PublicKey
+	copy(pub[:], k.key.Public().(ed25519.PublicKey))
+	return pub
+}
+
+func (k *Keypair) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	return ed25519.Sign(k.key, message), nil
+}
+
+// Transaction is a versioned transaction and the signatures of its signers.
+type Transaction struct {
+	Message *Message
+	// Signatures are ordered as Message.Signers; unsigned entries are zero.
+	Signatures [][64]byte
+}
+
+// NewTransaction returns an unsigned transaction of m.
+func NewTransaction(m *Message) *Transaction {
+	return &Transaction{Message: m, Signatures: make([][64]byte, m.Header.NumRequiredSignatures)}
+}
+
+// Sign adds the signatures of signers. Signers that are not required by the message are an error.
+func (tx *Transaction) Sign(ctx context.Context, signers ...Signer) error {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	required := tx.Message.Signers()
+	for _, s := range signers {
+		pub := s.PublicKey()
+		i := indexOf(required, pub)
+		if i < 0 {
+			return fmt.Errorf("solana: %s is not a signer of the transaction", pub)
+		}
+		sig, err := s.Sign(ctx, message)
+		if err != nil {
+			return err
+		}
+		if len(sig) != ed25519.SignatureSize || !ed25519.Verify(pub[:], message, sig) {
+			return fmt.Errorf("solana: invalid signature of %s", pub)
+		}
+		copy(tx.Signatures[i][:], sig)
+	}
+	return nil
+}
+
+// MarshalBinary returns the wire encoding of the signed transaction.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var zero [64]byte
+	b := appendCompactU16(nil, len(tx.Signatures))
+	for i, sig := range tx.Signatures {
+		if sig == zero {
+			return nil, fmt.Errorf("%w: %s", ErrMissingSignature, tx.Message.StaticKeys[i])
+		}
+		b = append(b, sig[:]...)
+	}
+	return append(b, message...), nil
+}
+
+// SignedTx returns the base58 encoding of the signed transaction, as accepted by
+// wallet.TransactionBroadcastRequest.SignedTx.
+func (tx *Transaction) SignedTx() (string, error) {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base58.Encode(b), nil
+}
+
+// ID returns the transaction id, the base58 encoding of the fee payer's signature.
+func (tx *Transaction) ID() string {
+	if len(tx.Signatures) == 0 {
+		return ""
+	}
+	return base58.Encode(tx.Signatures[0][:])
+}
+
+func indexOf(keys []PublicKey, key PublicKey) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,499 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package swap runs single-chain swaps end to end: quote, approve, swap, broadcast and confirm.
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+var (
+	ErrSwapFailed       = errors.New("swap failed")
+	ErrUnsupportedChain = errors.New("swap: only EVM chains are supported")
+)
+
+// Executor runs swaps as a state machine, signing with its Signer and broadcasting through the wallet API.
+//
+// The approval, when needed, is confirmed before the swap is built, because the swap transaction
+// is simulated against the current allowance. Both transactions take consecutive nonces from the
+// pending nonce of the signer, or lease them from the NonceLeaser set with WithNonceLeaser.
+//
+// A transaction that times out unconfirmed is broadcast again by the next Resume. One the node
+// already knows counts as broadcast, and one the node refuses for good is signed again.
+type Executor struct {
+	dex    *dex.DexAPI
+	wallet *wallet.WalletAPI
+	signer Signer
+
+	allowance   AllowanceChecker
+	onEvent     func(Event)
+	accountId   string
+	verifier    *Verifier
+	tracker     *wallet.Tracker
+	waitOptions []dex.WaitOption
+	nonces      NonceLeaser
+}
+
+func NewExecutor(dexAPI *dex.DexAPI, walletAPI *wallet.WalletAPI, signer Signer, opts ...Option) *Executor {
+	options := newOptions(opts...)
+	tracker := options.tracker
+	if tracker == nil {
+		tracker = wallet.NewTracker(walletAPI, wallet.WithTrackBackoff(options.pollInterval, 30*time.Second))
+	}
+	waitOptions := append([]dex.WaitOption{dex.WithWaitBackoff(options.pollInterval, 20*time.Second)}, options.waitOptions...)
+	return &Executor{
+		dex:         dexAPI,
+		wallet:      walletAPI,
+		signer:      signer,
+		allowance:   options.allowance,
+		onEvent:     options.onEvent,
+		accountId:   options.accountId,
+		verifier:    options.verifier,
+		tracker:     tracker,
+		waitOptions: waitOptions,
+		nonces:      options.nonces,
+	}
+}
+
+// Execute runs a new swap until it is done, it fails or ctx is done.
+// The returned Progress can be passed to Resume when an error interrupts the swap.
+func (e *Executor) Execute(ctx context.Context, req *Request) (*Progress, error) {
+	p := &Progress{State: StateQuote, Request: *req, From: e.signer.Address()}
+	return p, e.Resume(ctx, p)
+}
+
+// Resume continues the swap described by p from its current state.
+// ErrSwapFailed is returned when a transaction was mined but reverted.
+func (e *Executor) Resume(ctx context.Context, p *Progress) error {
+	// the transactions are built and signed as EVM transactions.
+	if !p.Request.ChainId.IsEVM() {
+		return fmt.Errorf("%w: chain %s", ErrUnsupportedChain, p.Request.ChainId)
+	}
+	if p.From == "" {
+		p.From = e.signer.Address()
+	}
+	for !p.State.IsFinal() {
+		from := p.State
+		next, err := e.step(ctx, p)
+		if err != nil {
+			p.Error = err.Error()
+			e.emit(Event{From: from, To: p.State, Progress: p, Err: err})
+			return err
+		}
+		p.State = next
+		if next != StateFailed {
+			p.Error = ""
+		}
+		e.emit(Event{From: from, To: next, Progress: p})
+	}
+	if p.State == StateFailed {
+		return fmt.Errorf("%w: %s", ErrSwapFailed, p.Error)
+	}
+	return nil
+}
+
+func (e *Executor) emit(ev Event) {
+	if e.onEvent != nil {
+		ev.Time = time.Now()
+		e.onEvent(ev)
+	}
+}
+
+func (e *Executor) step(ctx context.Context, p *Progress) (State, error) {
+	switch p.State {
+	case StateQuote:
+		return e.quote(ctx, p)
+	case StateApprove:
+		return e.approve(ctx, p)
+	case StateWaitApprove:
+		return e.waitApprove(ctx, p)
+	case StateSwap:
+		return e.swap(ctx, p)
+	case StateWaitSwap:
+		return e.waitSwap(ctx, p)
+	}
+	return p.State, fmt.Errorf("swap: unknown state %q", p.State)
+}
+
+func (e *Executor) quote(ctx context.Context, p *Progress) (State, error) {
+	req := &p.Request
+	quote, err := e.dex.GetQuotes(ctx, &dex.GetQuotesRequest{
+		ChainId:          req.ChainId,
+		Amount:           req.Amount,
+		FromTokenAddress: req.FromTokenAddress,
+		ToTokenAddress:   req.ToTokenAddress,
+		DexIds:           req.DexIds,
+		FeePercent:       req.FeePercent,
+	})
+	if err != nil {
+		return p.State, err
+	}
+	p.Quote = quote
+
+	p.NeedsApprove, err = e.needsApprove(ctx, p)
+	if err != nil {
+		return p.State, err
+	}
+	if p.NeedsApprove {
+		return StateApprove, nil
+	}
+	return StateSwap, nil
+}
+
+func (e *Executor) needsApprove(ctx context.Context, p *Progress) (bool, error) {
+	req := &p.Request
+	if c, ok := chains.Lookup(req.ChainId); ok && c.IsNativeToken(req.FromTokenAddress) {
+		return false, nil
+	}
+	if !req.ChainId.IsEVM() {
+		return false, nil
+	}
+	if e.allowance == nil {
+		return true, nil
+	}
+
+	infos, err := e.dex.GetSupportedChains(ctx, req.ChainId)
+	if err != nil {
+		return false, err
+	}
+	if len(infos) == 0 || infos[0].DexTokenApproveAddr == "" {
+		return true, nil
+	}
+	allowance, err := e.allowance.Allowance(ctx, req.ChainId, req.FromTokenAddress, p.From, infos[0].DexTokenApproveAddr)
+	if err != nil {
+		return false, err
+	}
+	amount, err := types.ParseUint256(req.Amount)
+	if err != nil {
+		return false, err
+	}
+	return allowance.Cmp(amount) < 0, nil
+}
+
+// nonce returns the next nonce of the signer: a lease of the leaser, or the pending nonce
+// fetched the first time.
+func (e *Executor) nonce(ctx context.Context, p *Progress) (uint64, error) {
+	if e.nonces != nil {
+		lease, err := e.nonces.Lease(ctx, p.Request.ChainId, p.From)
+		if err != nil {
+			return 0, err
+		}
+		p.lease = lease
+		return lease.Nonce(), nil
+	}
+	if !p.NonceLoaded {
+		n, err := e.wallet.GetNonce(ctx, &wallet.GetNonceRequest{ChainIndex: p.Request.ChainId, Address: p.From})
+		if err != nil {
+			return 0, err
+		}
+		next := n.Nonce.Int64()
+		if n.PendingNonce.Int64() > next {
+			next = n.PendingNonce.Int64()
+		}
+		p.NextNonce, p.NonceLoaded = uint64(next), true
+	}
+	return p.NextNonce, nil
+}
+
+func (e *Executor) approve(ctx context.Context, p *Progress) (State, error) {
+	req := &p.Request
+	if p.ApproveSignedTx == "" {
+		amount := req.ApproveAmount
+		if amount == "" {
+			amount = req.Amount
+		}
+		approveReq := &dex.ApproveTransactionsRequest{
+			ChainId:              req.ChainId,
+			TokenContractAddress: req.FromTokenAddress,
+			ApproveAmount:        amount,
+		}
+		approve, err := e.dex.GetApproveTx(ctx, approveReq)
+		if err != nil {
+			return p.State, err
+		}
+		if e.verifier != nil {
+			if err := e.verifier.VerifyApprove(ctx, approveReq, approve); err != nil {
+				return p.State, err
+			}
+		}
+		nonce, err := e.nonce(ctx, p)
+		if err != nil {
+			return p.State, err
+		}
+		signed, hash, err := e.signer.SignTransaction(ctx, &Transaction{
+			ChainID:  req.ChainId,
+			Nonce:    nonce,
+			To:       req.FromTokenAddress,
+			Data:     approve.Data,
+			Gas:      approve.GasLimit,
+			GasPrice: approve.GasPrice,
+		})
+		if err != nil {
+			e.settle(ctx, p, true, err)
+			return p.State, err
+		}
+		p.ApproveSignedTx, p.ApproveTxHash = signed, hash
+		p.NextNonce = nonce + 1
+	}
+
+	orderId, resign, err := e.send(ctx, p, p.ApproveSignedTx, p.ApproveTxHash)
+	if serr := e.settle(ctx, p, resign, err); err == nil {
+		err = serr
+	}
+	if resign {
+		p.ApproveSignedTx, p.ApproveTxHash = "", ""
+		p.NonceLoaded = false
+	}
+	if err != nil {
+		return p.State, err
+	}
+	p.ApproveOrderId = orderId
+	return StateWaitApprove, nil
+}
+
+func (e *Executor) waitApprove(ctx context.Context, p *Progress) (State, error) {
+	var success bool
+	if p.ApproveOrderId != "" {
+		order, err := e.tracker.Wait(ctx, wallet.OrderRef{
+			ChainIndex: p.Request.ChainId,
+			Address:    p.From,
+			AccountId:  e.accountId,
+			OrderId:    p.ApproveOrderId,
+		})
+		if errors.Is(err, wallet.ErrTrackTimeout) {
+			// the approval may have been dropped, broadcast it again on resume.
+			p.State = StateApprove
+		}
+		if err != nil {
+			return p.State, err
+		}
+		success = order.Status() == wallet.OrderSuccess
+	} else {
+		// the approval was broadcast outside the wallet API, so it is followed by its hash.
+		outcome, err := e.dex.WaitForSwap(ctx, p.Request.ChainId, p.ApproveTxHash, e.waitOptions...)
+		if errors.Is(err, dex.ErrSwapNotIndexed) {
+			p.State = StateApprove
+		}
+		if err != nil {
+			return p.State, err
+		}
+		success = outcome.Status == dex.SwapSuccess
+	}
+	if !success {
+		p.Error = "approve transaction " + p.ApproveTxHash + " failed"
+		return StateFailed, nil
+	}
+	return StateSwap, nil
+}
+
+func (e *Executor) swap(ctx context.Context, p *Progress) (State, error) {
+	req := &p.Request
+	if p.SwapSignedTx == "" {
+		swapReq := &dex.GetSwapTxRequest{
+			ChainId:             req.ChainId,
+			Amount:              req.Amount,
+			FromTokenAddress:    req.FromTokenAddress,
+			ToTokenAddress:      req.ToTokenAddress,
+			Slippage:            req.Slippage,
+			UserWalletAddress:   p.From,
+			SwapReceiverAddress: req.SwapReceiverAddress,
+			FeePercent:          req.FeePercent,
+			GasLevel:            req.GasLevel,
+			DexIds:              req.DexIds,
+		}
+		result, err := e.dex.GetSwapTx(ctx, swapReq)
+		if err != nil {
+			return p.State, err
+		}
+		if result.Tx == nil {
+			return p.State, errors.New("swap: swap response has no transaction")
+		}
+		// the response is checked against the independent quote, its own route could be tampered with too.
+		if e.verifier != nil {
+			if err := e.verifier.VerifySwap(ctx, swapReq, result, p.Quote); err != nil {
+				return p.State, err
+			}
+		}
+		nonce, err := e.nonce(ctx, p)
+		if err != nil {
+			return p.State, err
+		}
+		tx := result.Tx
+		signed, hash, err := e.signer.SignTransaction(ctx, &Transaction{
+			ChainID:              req.ChainId,
+			Nonce:                nonce,
+			To:                   tx.To,
+			Data:                 tx.Data,
+			Value:                tx.Value,
+			Gas:                  tx.Gas,
+			GasPrice:             tx.GasPrice,
+			MaxPriorityFeePerGas: tx.MaxPriorityFeePerGas,
+		})
+		if err != nil {
+			e.settle(ctx, p, true, err)
+			return p.State, err
+		}
+		p.SwapTx, p.SwapSignedTx, p.SwapTxHash = tx, signed, hash
+		p.NextNonce = nonce + 1
+	}
+
+	orderId, resign, err := e.send(ctx, p, p.SwapSignedTx, p.SwapTxHash)
+	if serr := e.settle(ctx, p, resign, err); err == nil {
+		err = serr
+	}
+	if resign {
+		p.SwapTx, p.SwapSignedTx, p.SwapTxHash = nil, "", ""
+		p.NonceLoaded = false
+	}
+	if err != nil {
+		return p.State, err
+	}
+	p.SwapOrderId = orderId
+	return StateWaitSwap, nil
+}
+
+func (e *Executor) waitSwap(ctx context.Context, p *Progress) (State, error) {
+	outcome, err := e.dex.WaitForSwap(ctx, p.Request.ChainId, p.SwapTxHash, e.waitOptions...)
+	if errors.Is(err, dex.ErrSwapNotIndexed) {
+		// the swap may have been dropped, broadcast it again on resume.
+		p.State = StateSwap
+	}
+	if err != nil {
+		return p.State, err
+	}
+	p.Status = outcome.Result
+	if outcome.Status == dex.SwapFail {
+		p.Error = "swap transaction " + p.SwapTxHash + " failed"
+		if outcome.Result.ErrorMsg != "" {
+			p.Error += ": " + outcome.Result.ErrorMsg
+		}
+		return StateFailed, nil
+	}
+	return StateDone, nil
+}
+
+// send broadcasts signedTx and returns the id of its order. A transaction the node already has
+// counts as broadcast, with the order of its earlier broadcast when there is one, so a swap
+// interrupted between the broadcast and saving its progress can resume. resign reports that the
+// node refused the transaction for good, e.g. because another transaction took its nonce, so it
+// must be signed again.
+func (e *Executor) send(ctx context.Context, p *Progress, signedTx, txHash string) (orderId string, resign bool, err error) {
+	orderId, err = e.broadcast(ctx, p, signedTx)
+	if err == nil {
+		return orderId, false, nil
+	}
+
+	known, nonceUsed := wallet.IsTxAlreadyKnown(err), wallet.IsNonceTooLow(err)
+	if known || nonceUsed {
+		id, found, ferr := e.findOrder(ctx, p, txHash)
+		if ferr != nil {
+			return "", false, ferr
+		}
+		if found || known {
+			return id, false, nil
+		}
+		// the nonce may be used by this very transaction, already mined.
+		status, serr := e.dex.GetTransactionStatus(ctx, &dex.GetTransactionStatusRequest{ChainId: p.Request.ChainId, TxHash: txHash})
+		if serr != nil && !errors.Is(serr, errcode.ErrResultsNotFound) {
+			return "", false, serr
+		}
+		if status != nil {
+			return "", false, nil
+		}
+		return "", true, err
+	}
+	return "", refused(err), err
+}
+
+// settle commits the nonce lease of a broadcast transaction, or releases it when the transaction
+// is not signed or must be signed again. A nonce another transaction took is committed and the
+// leaser reconciled, so the next lease skips it. The lease is kept while the broadcast is retried.
+func (e *Executor) settle(ctx context.Context, p *Progress, resign bool, err error) error {
+	if p.lease == nil || (err != nil && !resign) {
+		return nil
+	}
+	lease := p.lease
+	p.lease = nil
+	switch {
+	case err == nil:
+		return lease.Commit(ctx)
+	case wallet.IsNonceTooLow(err):
+		if cerr := lease.Commit(ctx); cerr != nil {
+			return cerr
+		}
+		return e.nonces.Reconcile(ctx, p.Request.ChainId, p.From)
+	}
+	return lease.Release(ctx)
+}
+
+// findOrder looks for the order of the transaction txHash among the recent orders of the sender.
+func (e *Executor) findOrder(ctx context.Context, p *Progress, txHash string) (string, bool, error) {
+	orders, err := e.wallet.GetTransactionOrder(ctx, &wallet.TransactionOrderRequest{
+		Address:    p.From,
+		AccountId:  e.accountId,
+		ChainIndex: p.Request.ChainId,
+		Limit:      "100",
+	})
+	if errors.Is(err, errcode.ErrResultsNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	for _, o := range orders {
+		if strings.EqualFold(o.TxHash, txHash) {
+			return o.OrderId, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// refused reports whether err is a response of the API refusing a broadcast, rather than a
+// failure to reach it or a temporary condition.
+func refused(err error) bool {
+	if errcode.FromError(err) == nil {
+		return false
+	}
+	return !errcode.IsServiceUnavailable(err) && !errcode.IsRateLimitReached(err) &&
+		!errcode.IsSystemError(err) && !errcode.IsRepeatedRequest(err) && !errcode.IsInvalidSignature(err)
+}
+
+func (e *Executor) broadcast(ctx context.Context, p *Progress, signedTx string) (string, error) {
+	result, err := e.wallet.TransactionBroadcast(ctx, &wallet.TransactionBroadcastRequest{
+		SignedTx:   signedTx,
+		ChainIndex: p.Request.ChainId,
+		Address:    p.From,
+		AccountId:  e.accountId,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.OrderId, nil
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/internal/transporttest"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+type fakeSigner struct {
+	fail   bool
+	nonces []uint64
+}
+
+func (s *fakeSigner) Address() string {
+	return "0x3f6a3f57569358a512ccc0e513f171516b0fd42a"
+}
+
+func (s *fakeSigner) SignTransaction(ctx context.Context, tx *Transaction) (string, string, error) {
+	if s.fail {
+		return "", "", errors.New("signer unavailable")
+	}
+	s.nonces = append(s.nonces, tx.Nonce)
+	return fmt.Sprintf("0xsigned%d", tx.Nonce), fmt.Sprintf("0xhash%d", tx.Nonce), nil
+}
+
+func TestExecutorResume(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/dex/aggregator/quote":                         `[{"chainId":"1","toTokenAmount":"1000"}]`,
+		"/api/v5/dex/aggregator/approve-transaction":           `[{"data":"0x095ea7b3","gasLimit":"50000","gasPrice":"1"}]`,
+		"/api/v5/dex/aggregator/history":                       `{"status":"success","hash":"0xhash8"}`,
+		"/api/v5/wallet/pre-transaction/nonce":                 `[{"nonce":"7","pendingNonce":"7"}]`,
+		"/api/v5/wallet/pre-transaction/broadcast-transaction": `[{"orderId":"order"}]`,
+		"/api/v5/wallet/post-transaction/orders":               `[{"orderId":"order","txStatus":"2"}]`,
+	})
+
+	var states []State
+	signer := &fakeSigner{}
+	e := NewExecutor(dex.NewDexAPI(tr), wallet.NewWalletAPI(tr), signer,
+		WithPollInterval(time.Millisecond),
+		WithEventHandler(func(ev Event) {
+			if ev.Err == nil {
+				states = append(states, ev.To)
+			}
+		}),
+	)
+	req := &Request{
+		ChainId:          "1",
+		FromTokenAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+		ToTokenAddress:   "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee",
+		Amount:           "1000000",
+		Slippage:         "0.01",
+	}
+
+	// the swap is interrupted after the approval is confirmed.
+	const swapData = `[{"tx":{"to":"0x7d0ccaa3fac1e5a943c5168b6ced828691b46b36","data":"0x","gas":"200000"}}]`
+	tr.Delete("/api/v5/dex/aggregator/swap")
+	p, err := e.Execute(context.Background(), req)
+	if err == nil || p.State != StateSwap || p.Error == "" {
+		t.Fatalf("Execute = %v, state %s", err, p.State)
+	}
+
+	// resuming after a round trip through JSON must not approve again.
+	b, _ := json.Marshal(p)
+	var resumed Progress
+	if err := json.Unmarshal(b, &resumed); err != nil {
+		t.Fatal(err)
+	}
+	tr.Set("/api/v5/dex/aggregator/swap", swapData)
+	if err := e.Resume(context.Background(), &resumed); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []State{StateApprove, StateWaitApprove, StateSwap, StateWaitSwap, StateDone}
+	if fmt.Sprint(states) != fmt.Sprint(want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+	if fmt.Sprint(signer.nonces) != "[7 8]" {
+		t.Errorf("nonces = %v, want [7 8]", signer.nonces)
+	}
+	if n := tr.Calls("/api/v5/dex/aggregator/approve-transaction"); n != 1 {
+		t.Errorf("approve built %d times, want 1", n)
+	}
+}
+
+func TestExecutorRebroadcast(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/dex/aggregator/history":         `{"status":"success","hash":"0xhash9"}`,
+		"/api/v5/wallet/pre-transaction/nonce":   `[{"nonce":"7","pendingNonce":"7"}]`,
+		"/api/v5/wallet/post-transaction/orders": `[{"orderId":"earlier","txHash":"0xHASH9","txStatus":"1"}]`,
+		"/api/v5/dex/aggregator/swap":            `[{"tx":{"to":"0x7d0ccaa3fac1e5a943c5168b6ced828691b46b36","data":"0x","gas":"200000"}}]`,
+	})
+	broadcastErr := errcode.New(81451, "already known")
+	tr.Handle("/api/v5/wallet/pre-transaction/broadcast-transaction", func(*transporttest.Request) (string, error) {
+		if broadcastErr != nil {
+			return "", broadcastErr
+		}
+		return `[{"orderId":"order"}]`, nil
+	})
+	signer := &fakeSigner{}
+	e := NewExecutor(dex.NewDexAPI(tr), wallet.NewWalletAPI(tr), signer, WithPollInterval(time.Millisecond))
+
+	// the swap was broadcast before a crash, the node already has it.
+	p := &Progress{
+		State:        StateSwap,
+		Request:      Request{ChainId: "1", FromTokenAddress: "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", ToTokenAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Amount: "1", Slippage: "0.01"},
+		From:         signer.Address(),
+		SwapSignedTx: "0xsigned9",
+		SwapTxHash:   "0xhash9",
+	}
+	if err := e.Resume(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	if p.State != StateDone || p.SwapOrderId != "earlier" || len(signer.nonces) != 0 {
+		t.Fatalf("state %s, order %q, signed %v", p.State, p.SwapOrderId, signer.nonces)
+	}
+
+	// a transaction refused for good is signed again on resume.
+	broadcastErr = errcode.New(81451, "insufficient funds for gas * price + value")
+	p.State, p.SwapOrderId = StateSwap, ""
+	if err := e.Resume(context.Background(), p); err == nil || p.SwapSignedTx != "" {
+		t.Fatalf("Resume = %v, signed tx %q", err, p.SwapSignedTx)
+	}
+	broadcastErr = nil
+	if err := e.Resume(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	if p.SwapTxHash != "0xhash7" || p.SwapOrderId != "order" || fmt.Sprint(signer.nonces) != "[7]" {
+		t.Errorf("hash %s, order %s, nonces %v", p.SwapTxHash, p.SwapOrderId, signer.nonces)
+	}
+
+	p.Request.ChainId = chains.Solana
+	if _, err := e.Execute(context.Background(), &p.Request); !errors.Is(err, ErrUnsupportedChain) {
+		t.Errorf("Execute on Solana = %v", err)
+	}
+}
+
+type fakeLeaser struct {
+	next       uint64
+	released   []uint64
+	committed  []uint64
+	reconciled int
+}
+
+type fakeLease struct {
+	l     *fakeLeaser
+	nonce uint64
+}
+
+func (l *fakeLeaser) Lease(ctx context.Context, chainId chains.ChainID, address string) (NonceLease, error) {
+	if n := len(l.released); n > 0 {
+		nonce := l.released[n-1]
+		l.released = l.released[:n-1]
+		return &fakeLease{l: l, nonce: nonce}, nil
+	}
+	l.next++
+	return &fakeLease{l: l, nonce: l.next - 1}, nil
+}
+
+func (l *fakeLeaser) Reconcile(ctx context.Context, chainId chains.ChainID, address string) error {
+	l.reconciled++
+	l.next += 10
+	return nil
+}
+
+func (l *fakeLease) Nonce() uint64 {
+	return l.nonce
+}
+
+func (l *fakeLease) Commit(ctx context.Context) error {
+	l.l.committed = append(l.l.committed, l.nonce)
+	return nil
+}
+
+func (l *fakeLease) Release(ctx context.Context) error {
+	l.l.released = append(l.l.released, l.nonce)
+	return nil
+}
+
+func TestExecutorNonceLeaser(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/dex/aggregator/quote":           `[{"chainId":"1","toTokenAmount":"1000"}]`,
+		"/api/v5/dex/aggregator/history":         `{"status":"success"}`,
+		"/api/v5/wallet/post-transaction/orders": `[{"orderId":"order","txStatus":"2"}]`,
+		"/api/v5/dex/aggregator/swap":            `[{"tx":{"to":"0x7d0ccaa3fac1e5a943c5168b6ced828691b46b36","data":"0x","gas":"200000"}}]`,
+	})
+	var broadcastErr error
+	tr.Handle("/api/v5/wallet/pre-transaction/broadcast-transaction", func(*transporttest.Request) (string, error) {
+		if broadcastErr != nil {
+			return "", broadcastErr
+		}
+		return `[{"orderId":"order"}]`, nil
+	})
+	signer := &fakeSigner{}
+	leaser := &fakeLeaser{next: 3}
+	e := NewExecutor(dex.NewDexAPI(tr), wallet.NewWalletAPI(tr), signer,
+		WithPollInterval(time.Millisecond), WithNonceLeaser(leaser))
+	req := &Request{ChainId: "1", FromTokenAddress: "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", ToTokenAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Amount: "1", Slippage: "0.01"}
+
+	// a refused transaction gives its nonce back.
+	broadcastErr = errcode.New(81451, "insufficient funds for gas * price + value")
+	p, err := e.Execute(context.Background(), req)
+	if err == nil || fmt.Sprint(leaser.released) != "[3]" {
+		t.Fatalf("Execute = %v, released %v", err, leaser.released)
+	}
+
+	// a nonce taken by another transaction is committed and the leaser reconciled.
+	broadcastErr = errcode.New(81451, "nonce too low")
+	tr.Set("/api/v5/dex/aggregator/history", `null`)
+	if err := e.Resume(context.Background(), p); err == nil || leaser.reconciled != 1 {
+		t.Fatalf("Resume = %v, reconciled %d", err, leaser.reconciled)
+	}
+
+	broadcastErr = nil
+	tr.Set("/api/v5/dex/aggregator/history", `{"status":"success"}`)
+	if err := e.Resume(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(signer.nonces) != "[3 3 14]" || fmt.Sprint(leaser.committed) != "[3 14]" || len(leaser.released) != 0 {
+		t.Errorf("nonces %v, committed %v, released %v", signer.nonces, leaser.committed, leaser.released)
+	}
+}
+
+func TestPollIntervalDefault(t *testing.T) {
+	// a zero interval would never back off.
+	if o := newOptions(WithPollInterval(0)); o.pollInterval != 3*time.Second {
+		t.Errorf("poll interval = %s, want 3s", o.pollInterval)
+	}
+}
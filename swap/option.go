@@ -0,0 +1,122 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package swap
+
+import (
+	"time"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+type Options struct {
+	allowance    AllowanceChecker
+	onEvent      func(Event)
+	pollInterval time.Duration
+	accountId    string
+	verifier     *Verifier
+	tracker      *wallet.Tracker
+	waitOptions  []dex.WaitOption
+	nonces       NonceLeaser
+}
+
+type Option interface {
+	apply(o *Options)
+}
+
+type optionFunc func(o *Options)
+
+func (f optionFunc) apply(o *Options) {
+	f(o)
+}
+
+// WithAllowanceChecker skips approvals whose allowance is already sufficient.
+func WithAllowanceChecker(checker AllowanceChecker) Option {
+	return optionFunc(func(o *Options) {
+		o.allowance = checker
+	})
+}
+
+// WithEventHandler calls handler with every event of the swaps run by the executor.
+// It is called synchronously, so it should not block.
+func WithEventHandler(handler func(Event)) Option {
+	return optionFunc(func(o *Options) {
+		o.onEvent = handler
+	})
+}
+
+// WithPollInterval sets the first interval between checks of the status of a broadcast
+// transaction, 3s by default. The interval backs off while the status does not change.
+// An interval that is not positive keeps the default.
+func WithPollInterval(d time.Duration) Option {
+	return optionFunc(func(o *Options) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	})
+}
+
+// WithAccountId broadcasts the transactions on behalf of a wallet API account.
+func WithAccountId(accountId string) Option {
+	return optionFunc(func(o *Options) {
+		o.accountId = accountId
+	})
+}
+
+// WithVerifier checks the approve and swap transactions with verifier before they are signed.
+func WithVerifier(verifier *Verifier) Option {
+	return optionFunc(func(o *Options) {
+		o.verifier = verifier
+	})
+}
+
+// WithTracker waits for approvals with tracker. By default, the executor uses a tracker of its
+// wallet API that starts polling at the poll interval and times out after 10m.
+func WithTracker(tracker *wallet.Tracker) Option {
+	return optionFunc(func(o *Options) {
+		o.tracker = tracker
+	})
+}
+
+// WithSwapWaitOptions passes opts to dex.DexAPI.WaitForSwap when waiting for swaps, e.g. to
+// change the index timeout.
+func WithSwapWaitOptions(opts ...dex.WaitOption) Option {
+	return optionFunc(func(o *Options) {
+		o.waitOptions = append(o.waitOptions, opts...)
+	})
+}
+
+// WithNonceLeaser takes the nonces of the transactions from leaser instead of the pending nonce
+// of the signer, so several executors can send from the same address.
+func WithNonceLeaser(leaser NonceLeaser) Option {
+	return optionFunc(func(o *Options) {
+		o.nonces = leaser
+	})
+}
+
+func newOptions(opts ...Option) Options {
+	o := Options{
+		pollInterval: 3 * time.Second,
+	}
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+	return o
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+// reportScale is the number of decimals of the ratios of a report.
+const reportScale = 18
+
+// PriceSource returns real-time token prices in USD. *wallet.WalletAPI implements it.
+type PriceSource interface {
+	GetRealTimeTokenPrice(ctx context.Context, req []*wallet.GetRealTimeTokenPriceRequest) ([]wallet.TokenPrice, error)
+}
+
+// Report compares a settled swap with its quote. Amounts are in token units, ratios are
+// fractions (0.01 is 1%) except the price impacts, which are percentages like PriceImpactPct.
+// Fields that cannot be computed are left unset.
+type Report struct {
+	ChainId   chains.ChainID `json:"chainId"`
+	TxHash    string         `json:"txHash"`
+	Status    dex.SwapStatus `json:"status"`
+	FromToken string         `json:"fromToken"`
+	ToToken   string         `json:"toToken"`
+
+	QuotedFromAmount types.Decimal `json:"quotedFromAmount"`
+	QuotedToAmount   types.Decimal `json:"quotedToAmount"`
+	FromAmount       types.Decimal `json:"fromAmount"`
+	ToAmount         types.Decimal `json:"toAmount"`
+	// QuotedPrice and ExecutionPrice are to-token units per from-token unit.
+	QuotedPrice    types.Decimal `json:"quotedPrice"`
+	ExecutionPrice types.Decimal `json:"executionPrice"`
+	// RealizedSlippage is the shortfall of the received amount against the quoted one;
+	// negative if more was received.
+	RealizedSlippage types.Decimal `json:"realizedSlippage"`
+
+	// QuotedPriceImpactPct is the quoted PriceImpactPct, RealizedPriceImpactPct the difference of
+	// the USD values received and paid, relative to the value paid.
+	QuotedPriceImpactPct   types.Decimal `json:"quotedPriceImpactPct"`
+	RealizedPriceImpactPct types.Decimal `json:"realizedPriceImpactPct"`
+
+	// EstimatedFee and Fee are the estimated and actual network fees in native token units,
+	// GasOverrun the excess of the actual fee relative to the estimate.
+	EstimatedFee types.Decimal `json:"estimatedFee"`
+	Fee          types.Decimal `json:"fee"`
+	GasUsed      types.Uint256 `json:"gasUsed"`
+	GasOverrun   types.Decimal `json:"gasOverrun"`
+
+	FromValueUSD     types.Decimal `json:"fromValueUsd"`
+	ToValueUSD       types.Decimal `json:"toValueUsd"`
+	QuotedToValueUSD types.Decimal `json:"quotedToValueUsd"`
+	FeeUSD           types.Decimal `json:"feeUsd"`
+	// ValueDifferenceUSD is the USD value received minus the value quoted.
+	ValueDifferenceUSD types.Decimal `json:"valueDifferenceUsd"`
+}
+
+// NewReport compares the settled swap status with quote. The USD values use the current prices
+// of prices, and are left unset if prices is nil.
+func NewReport(ctx context.Context, prices PriceSource, quote *dex.QuotesResult, status *dex.TransactionStatusResult) (*Report, error) {
+	if quote == nil || status == nil {
+		return nil, errors.New("swap: report needs a quote and a transaction status")
+	}
+	r := &Report{
+		ChainId:              quote.ChainId,
+		TxHash:               status.Hash,
+		Status:               status.SwapStatus(),
+		FromToken:            quote.FromToken.TokenContractAddress,
+		ToToken:              quote.ToToken.TokenContractAddress,
+		QuotedPriceImpactPct: quote.PriceImpactPct,
+		GasUsed:              status.GasUsed,
+		Fee:                  status.TxFee,
+	}
+	var err error
+	if r.QuotedFromAmount, err = types.FromMinimalUnits(quote.FromTokenAmount, int(quote.FromToken.Decimal)); err != nil {
+		return nil, err
+	}
+	if r.QuotedToAmount, err = types.FromMinimalUnits(quote.ToTokenAmount, int(quote.ToToken.Decimal)); err != nil {
+		return nil, err
+	}
+	if status.FromTokenDetails != nil {
+		r.FromAmount = status.FromTokenDetails.Amount
+	}
+	if status.ToTokenDetails != nil {
+		r.ToAmount = status.ToTokenDetails.Amount
+	}
+
+	r.QuotedPrice = ratio(r.QuotedToAmount, r.QuotedFromAmount)
+	r.ExecutionPrice = ratio(r.ToAmount, r.FromAmount)
+	if r.ToAmount.IsSet() {
+		r.RealizedSlippage = ratio(r.QuotedToAmount.Sub(r.ToAmount), r.QuotedToAmount)
+	}
+
+	native := nativeDecimals(quote.ChainId)
+	if quote.EstimateGasFee.IsSet() {
+		if r.EstimatedFee, err = types.FromMinimalUnits(quote.EstimateGasFee, native); err != nil {
+			return nil, err
+		}
+	}
+	if !r.Fee.IsSet() && status.GasUsed.IsSet() && status.GasPrice.IsSet() {
+		r.Fee = types.NewDecimal(status.GasUsed.Big(), 0).Mul(types.NewDecimal(status.GasPrice.Big(), 0)).Shift(-int32(native))
+	}
+	if r.Fee.IsSet() {
+		r.GasOverrun = ratio(r.Fee.Sub(r.EstimatedFee), r.EstimatedFee)
+	}
+
+	if prices == nil {
+		return r, nil
+	}
+	usd, err := fetchPrices(ctx, prices, quote.ChainId, r.FromToken, r.ToToken, "")
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := usd[strings.ToLower(r.FromToken)]; ok && r.FromAmount.IsSet() {
+		r.FromValueUSD = r.FromAmount.Mul(p)
+	}
+	if p, ok := usd[strings.ToLower(r.ToToken)]; ok {
+		r.QuotedToValueUSD = r.QuotedToAmount.Mul(p)
+		if r.ToAmount.IsSet() {
+			r.ToValueUSD = r.ToAmount.Mul(p)
+			r.ValueDifferenceUSD = r.ToValueUSD.Sub(r.QuotedToValueUSD)
+		}
+	}
+	if p, ok := usd[""]; ok && r.Fee.IsSet() {
+		r.FeeUSD = r.Fee.Mul(p)
+	}
+	if r.FromValueUSD.IsSet() && r.ToValueUSD.IsSet() {
+		if impact := ratio(r.ToValueUSD.Sub(r.FromValueUSD), r.FromValueUSD); impact.IsSet() {
+			r.RealizedPriceImpactPct = impact.Mul(types.DecimalFromInt64(100))
+		}
+	}
+	return r, nil
+}
+
+// Report returns the execution report of a settled swap, or an error if it has not settled.
+func (p *Progress) Report(ctx context.Context, prices PriceSource) (*Report, error) {
+	if p.Status == nil || !p.Status.SwapStatus().IsFinal() {
+		return nil, errors.New("swap: swap has not settled")
+	}
+	return NewReport(ctx, prices, p.Quote, p.Status)
+}
+
+// fetchPrices returns the USD prices of tokens on chainId by lowercase address; "" is the native token.
+func fetchPrices(ctx context.Context, prices PriceSource, chainId chains.ChainID, tokens ...string) (map[string]types.Decimal, error) {
+	reqs := make([]*wallet.GetRealTimeTokenPriceRequest, 0, len(tokens))
+	for _, token := range tokens {
+		if c, ok := chains.Lookup(chainId); ok && token != "" && c.IsNativeToken(token) {
+			continue
+		}
+		reqs = append(reqs, &wallet.GetRealTimeTokenPriceRequest{ChainIndex: chainId, TokenAddress: token})
+	}
+	list, err := prices.GetRealTimeTokenPrice(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]types.Decimal, len(tokens))
+	for _, p := range list {
+		if p.Price.IsSet() {
+			out[strings.ToLower(p.TokenAddress)] = p.Price
+		}
+	}
+	// The native token may be priced under its placeholder address.
+	if native, ok := out[""]; ok {
+		for _, token := range tokens {
+			if c, ok := chains.Lookup(chainId); ok && token != "" && c.IsNativeToken(token) {
+				out[strings.ToLower(token)] = native
+			}
+		}
+	}
+	return out, nil
+}
+
+// ratio returns a / b, unset if either is unset or b is zero.
+func ratio(a, b types.Decimal) types.Decimal {
+	if !a.IsSet() || !b.IsSet() || b.IsZero() {
+		return types.Decimal{}
+	}
+	return a.Quo(b, reportScale)
+}
+
+func nativeDecimals(chainId chains.ChainID) int {
+	if c, ok := chains.Lookup(chainId); ok && c.NativeDecimals > 0 {
+		return c.NativeDecimals
+	}
+	return 18
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+type fakePrices map[string]string
+
+func (f fakePrices) GetRealTimeTokenPrice(ctx context.Context, req []*wallet.GetRealTimeTokenPriceRequest) ([]wallet.TokenPrice, error) {
+	var out []wallet.TokenPrice
+	for _, r := range req {
+		var p wallet.TokenPrice
+		if err := json.Unmarshal([]byte(`{"tokenAddress":"`+r.TokenAddress+`","price":"`+f[r.TokenAddress]+`"}`), &p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func TestReport(t *testing.T) {
+	var quote dex.QuotesResult
+	var status dex.TransactionStatusResult
+	if err := json.Unmarshal([]byte(`{
+		"chainId": "1",
+		"estimateGasFee": "1000000000000000",
+		"fromToken": {"decimal": "6", "tokenContractAddress": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		"fromTokenAmount": "100000000",
+		"toToken": {"decimal": "18", "tokenContractAddress": "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"},
+		"toTokenAmount": "50000000000000000",
+		"priceImpactPct": "-0.5"
+	}`), &quote); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{
+		"hash": "0xabc",
+		"status": "success",
+		"fromTokenDetails": {"amount": "100"},
+		"toTokenDetails": {"amount": "0.049"},
+		"txFee": "0.0012"
+	}`), &status); err != nil {
+		t.Fatal(err)
+	}
+	prices := fakePrices{
+		"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48": "1",
+		"": "2000",
+	}
+
+	p := &Progress{Quote: &quote, Status: &status}
+	r, err := p.Report(context.Background(), prices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"realizedSlippage", r.RealizedSlippage.Text(), "0.02"},
+		{"gasOverrun", r.GasOverrun.Text(), "0.2"},
+		{"toValueUsd", r.ToValueUSD.Text(), "98"},
+		{"valueDifferenceUsd", r.ValueDifferenceUSD.Text(), "-2"},
+		{"feeUsd", r.FeeUSD.Text(), "2.4"},
+		{"realizedPriceImpactPct", r.RealizedPriceImpactPct.Text(), "-2"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %s, want %s", c.name, c.got, c.want)
+		}
+	}
+	if _, err := json.Marshal(r); err != nil {
+		t.Fatal(err)
+	}
+
+	p.Status = nil
+	if _, err := p.Report(context.Background(), prices); err == nil {
+		t.Fatal("report of an unsettled swap")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package swap
+
+import (
+	"context"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/types"
+)
+
+// Transaction is an unsigned EVM transaction built by the executor.
+type Transaction struct {
+	ChainID  chains.ChainID `json:"chainId"`
+	Nonce    uint64         `json:"nonce"`
+	To       string         `json:"to"`
+	Data     string         `json:"data"`
+	Value    types.Uint256  `json:"value"`
+	Gas      types.Uint256  `json:"gas"`
+	GasPrice types.Uint256  `json:"gasPrice"`
+	// MaxPriorityFeePerGas is set when the swap should be sent as an EIP-1559 transaction.
+	MaxPriorityFeePerGas types.Uint256 `json:"maxPriorityFeePerGas"`
+}
+
+// Signer signs the transactions of a single address. Keys never pass through the executor.
+type Signer interface {
+	// Address returns the address the transactions are sent from.
+	Address() string
+	// SignTransaction returns tx signed and encoded as accepted by wallet.TransactionBroadcast,
+	// and the hash of the signed transaction.
+	SignTransaction(ctx context.Context, tx *Transaction) (signedTx string, txHash string, err error)
+}
+
+// AllowanceChecker reads the ERC-20 allowance owner has granted spender, so approvals that are
+// already in place can be skipped. Without one, the executor approves every non-native token.
+type AllowanceChecker interface {
+	Allowance(ctx context.Context, chainId chains.ChainID, token, owner, spender string) (types.Uint256, error)
+}
+
+// NonceLeaser hands out the nonces of the transactions, so concurrent swaps of one sender do
+// not take the same nonce. evm.SwapNonceLeaser adapts an evm.NonceManager.
+type NonceLeaser interface {
+	Lease(ctx context.Context, chainId chains.ChainID, address string) (NonceLease, error)
+	// Reconcile aligns the leases of address with the nonces the node reports.
+	Reconcile(ctx context.Context, chainId chains.ChainID, address string) error
+}
+
+// NonceLease is a leased nonce, committed once its transaction is broadcast or released when
+// the transaction never reaches the network.
+type NonceLease interface {
+	Nonce() uint64
+	Commit(ctx context.Context) error
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package swap
+
+import (
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+)
+
+// State is a step of a swap. The executor moves through the states in the order they are declared.
+type State string
+
+const (
+	StateQuote       State = "quote"
+	StateApprove     State = "approve"
+	StateWaitApprove State = "wait_approve"
+	StateSwap        State = "swap"
+	StateWaitSwap    State = "wait_swap"
+	StateDone        State = "done"
+	StateFailed      State = "failed"
+)
+
+// IsFinal reports whether the swap has finished, successfully or not.
+func (s State) IsFinal() bool {
+	return s == StateDone || s == StateFailed
+}
+
+// Request describes a single-chain swap.
+type Request struct {
+	ChainId          chains.ChainID `json:"chainId"`
+	FromTokenAddress string         `json:"fromTokenAddress"`
+	ToTokenAddress   string         `json:"toTokenAddress"`
+	// Amount is the amount to sell, in minimal divisible units.
+	Amount string `json:"amount"`
+	// Slippage is the accepted slippage, between 0 and 1.
+	Slippage string `json:"slippage"`
+	// ApproveAmount is the allowance to grant when an approval is needed. Defaults to Amount.
+	ApproveAmount       string   `json:"approveAmount,omitempty"`
+	SwapReceiverAddress string   `json:"swapReceiverAddress,omitempty"`
+	DexIds              []string `json:"dexIds,omitempty"`
+	FeePercent          string   `json:"feePercent,omitempty"`
+	GasLevel            string   `json:"gasLevel,omitempty"`
+}
+
+// Progress is the resumable state of a swap. It is updated in place as the swap advances and
+// can be stored as JSON and passed to Executor.Resume, for instance after a restart.
+// Signed transactions are kept until they are broadcast, so a resumed swap broadcasts the same
+// transaction again instead of signing a new one with another nonce.
+type Progress struct {
+	State   State   `json:"state"`
+	Request Request `json:"request"`
+	From    string  `json:"from"`
+
+	Quote        *dex.QuotesResult `json:"quote,omitempty"`
+	NeedsApprove bool              `json:"needsApprove"`
+	// NextNonce is the nonce of the next transaction, 0 until the nonce is fetched.
+	NextNonce   uint64 `json:"nextNonce"`
+	NonceLoaded bool   `json:"nonceLoaded"`
+
+	ApproveSignedTx string `json:"approveSignedTx,omitempty"`
+	ApproveTxHash   string `json:"approveTxHash,omitempty"`
+	ApproveOrderId  string `json:"approveOrderId,omitempty"`
+
+	SwapTx       *dex.Tx `json:"swapTx,omitempty"`
+	SwapSignedTx string  `json:"swapSignedTx,omitempty"`
+	SwapTxHash   string  `json:"swapTxHash,omitempty"`
+	SwapOrderId  string  `json:"swapOrderId,omitempty"`
+
+	Status *dex.TransactionStatusResult `json:"status,omitempty"`
+	// Error is the reason the swap failed, or the last error the swap was interrupted by.
+	Error string `json:"error,omitempty"`
+
+	// lease is the nonce lease of the signed transaction not broadcast yet. It is not stored: a
+	// NonceManager restores its leases as committed on load and reconciles them.
+	lease NonceLease
+}
+
+// Event is emitted when a swap changes state, or when a step fails and the swap is interrupted.
+type Event struct {
+	From     State
+	To       State
+	Progress *Progress
+	Err      error
+	Time     time.Time
+}
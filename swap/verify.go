@@ -0,0 +1,339 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package swap
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/dex/calldata"
+)
+
+// ViolationCode identifies a check that a transaction failed.
+type ViolationCode string
+
+const (
+	ViolationRouter          ViolationCode = "router"
+	ViolationFrom            ViolationCode = "from"
+	ViolationValue           ViolationCode = "value"
+	ViolationMinReceive      ViolationCode = "min_receive"
+	ViolationApproveAddress  ViolationCode = "approve_address"
+	ViolationApproveCalldata ViolationCode = "approve_calldata"
+	ViolationCalldata        ViolationCode = "calldata"
+)
+
+// Violation describes why a transaction must not be signed.
+type Violation struct {
+	Code    ViolationCode `json:"code"`
+	Field   string        `json:"field"`
+	Message string        `json:"message"`
+}
+
+// VerifyError is returned when a transaction fails one or more checks.
+type VerifyError struct {
+	Violations []*Violation `json:"violations"`
+}
+
+func (e *VerifyError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Field+": "+v.Message)
+	}
+	return "unsafe transaction: " + strings.Join(msgs, "; ")
+}
+
+func (e *VerifyError) add(code ViolationCode, field, format string, args ...any) {
+	e.Violations = append(e.Violations, &Violation{Code: code, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *VerifyError) err() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Verifier checks the transactions returned by the DEX API before they are signed, so a
+// compromised or faulty response cannot send funds to an unknown contract.
+//
+// The router and approve addresses of each chain must be pinned by the caller from a trusted
+// source, such as the OKX documentation. A swap on a chain without a pinned router fails
+// verification. When the Verifier has a DexAPI, the approve address reported by the supported
+// chains API is checked against the pinned one.
+type Verifier struct {
+	dex *dex.DexAPI
+
+	mu       sync.Mutex
+	routers  map[chains.ChainID][]string
+	approves map[chains.ChainID]string
+	reported map[chains.ChainID]string
+}
+
+// NewVerifier creates a Verifier. dexAPI may be nil to skip the cross-check with the API.
+func NewVerifier(dexAPI *dex.DexAPI) *Verifier {
+	return &Verifier{
+		dex:      dexAPI,
+		routers:  make(map[chains.ChainID][]string),
+		approves: make(map[chains.ChainID]string),
+		reported: make(map[chains.ChainID]string),
+	}
+}
+
+// PinRouter records the router contracts swap transactions on chainId may be sent to.
+func (v *Verifier) PinRouter(chainId chains.ChainID, routers ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.routers[chainId] = append(v.routers[chainId], routers...)
+}
+
+// PinApproveAddress records the contract tokens on chainId are approved to.
+func (v *Verifier) PinApproveAddress(chainId chains.ChainID, addr string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.approves[chainId] = addr
+}
+
+// VerifySwap checks the transaction of a swap response against its request:
+// Tx.To must be a pinned router, Tx.From the user wallet, Tx.Value zero unless the source token
+// is native, and MinReceiveAmount no lower than the quoted output less the requested slippage.
+// The quoted output is taken from quote, or from the route of the response when quote is nil,
+// which only catches inconsistent responses: pass a quote fetched separately where possible.
+func (v *Verifier) VerifySwap(ctx context.Context, req *dex.GetSwapTxRequest, result *dex.GetSwapTxResult, quote *dex.QuotesResult) error {
+	e := new(VerifyError)
+	if result == nil || result.Tx == nil {
+		e.add(ViolationRouter, "tx", "is missing")
+		return e
+	}
+	tx := result.Tx
+
+	v.mu.Lock()
+	routers := v.routers[req.ChainId]
+	v.mu.Unlock()
+	if len(routers) == 0 {
+		e.add(ViolationRouter, "tx.to", "no router is pinned for chain %s", req.ChainId)
+	} else if !containsAddress(routers, tx.To) {
+		e.add(ViolationRouter, "tx.to", "%s is not a pinned router", tx.To)
+	}
+	if err := v.checkReportedApprove(ctx, req.ChainId, e); err != nil {
+		return err
+	}
+
+	if tx.From != "" && !sameAddress(tx.From, req.UserWalletAddress) {
+		e.add(ViolationFrom, "tx.from", "%s is not the user wallet %s", tx.From, req.UserWalletAddress)
+	}
+
+	native := false
+	if c, ok := chains.Lookup(req.ChainId); ok {
+		native = c.IsNativeToken(req.FromTokenAddress)
+	}
+	if native {
+		amount, _ := new(big.Int).SetString(req.Amount, 10)
+		if value := tx.Value.Big(); amount != nil && value != nil && value.Cmp(amount) > 0 {
+			e.add(ViolationValue, "tx.value", "%s exceeds the amount %s", value, amount)
+		}
+	} else if !tx.Value.IsZero() {
+		e.add(ViolationValue, "tx.value", "must be 0 when the source token is not native, got %s", tx.Value)
+	}
+
+	if quote == nil {
+		quote = result.RouterResult
+	}
+	floor := v.checkMinReceive(req, tx, quote, e)
+	if req.ChainId.IsEVM() {
+		v.checkSwapCalldata(req, tx, floor, e)
+	}
+	return e.err()
+}
+
+// checkSwapCalldata checks the decoded router call, which is what the router enforces,
+// against the request. floor is the lowest acceptable output, nil if unknown.
+func (v *Verifier) checkSwapCalldata(req *dex.GetSwapTxRequest, tx *dex.Tx, floor *big.Int, e *VerifyError) {
+	c, err := calldata.Decode(tx.Data)
+	if err != nil {
+		e.add(ViolationCalldata, "tx.data", "is not a known router call: %v", err)
+		return
+	}
+	if !c.IsSwap() {
+		e.add(ViolationCalldata, "tx.data", "is a %s call, not a swap", c.Function)
+		return
+	}
+
+	if amount, ok := new(big.Int).SetString(req.Amount, 10); ok && c.Amount.Big().Cmp(amount) > 0 {
+		e.add(ViolationCalldata, "tx.data.amount", "%s exceeds the amount %s", c.Amount, amount)
+	}
+	if floor != nil && c.MinReturn.Big().Cmp(floor) < 0 {
+		e.add(ViolationMinReceive, "tx.data.minReturn", "%s is below %s", c.MinReturn, floor)
+	}
+	if c.FromToken != "" && !sameToken(req.ChainId, c.FromToken, req.FromTokenAddress) {
+		e.add(ViolationCalldata, "tx.data.fromToken", "%s is not %s", c.FromToken, req.FromTokenAddress)
+	}
+	if c.ToToken != "" && !sameToken(req.ChainId, c.ToToken, req.ToTokenAddress) {
+		e.add(ViolationCalldata, "tx.data.toToken", "%s is not %s", c.ToToken, req.ToTokenAddress)
+	}
+
+	receiver := req.SwapReceiverAddress
+	if receiver == "" {
+		receiver = req.UserWalletAddress
+	}
+	if c.Receiver != "" && !sameAddress(c.Receiver, receiver) {
+		e.add(ViolationCalldata, "tx.data.receiver", "%s is not %s", c.Receiver, receiver)
+	}
+
+	if c.Commission != nil {
+		referrers := []string{req.ReferrerAddress, req.ToTokenReferrerAddress, req.FromTokenReferrerWalletAddress, req.ToTokenReferrerWalletAddress}
+		if req.FeePercent == "" {
+			e.add(ViolationCalldata, "tx.data.commission", "pays %s a commission that was not requested", c.Commission.Referrer)
+		} else if !containsAddress(referrers, c.Commission.Referrer) {
+			e.add(ViolationCalldata, "tx.data.commission", "pays %s, which is not a requested referrer", c.Commission.Referrer)
+		}
+	}
+}
+
+// checkMinReceive returns the lowest acceptable output, or nil if it cannot be computed.
+func (v *Verifier) checkMinReceive(req *dex.GetSwapTxRequest, tx *dex.Tx, quote *dex.QuotesResult, e *VerifyError) *big.Int {
+	if quote == nil || !quote.ToTokenAmount.IsSet() {
+		e.add(ViolationMinReceive, "routerResult.toTokenAmount", "no quoted output to compare with")
+		return nil
+	}
+	slippage := req.Slippage
+	if req.AutoSlippage && req.MaxAutoSlippage != "" {
+		slippage = req.MaxAutoSlippage
+	}
+	s, ok := new(big.Rat).SetString(slippage)
+	if !ok || s.Sign() < 0 || s.Cmp(big.NewRat(1, 1)) > 0 {
+		e.add(ViolationMinReceive, "slippage", "%q is not between 0 and 1", slippage)
+		return nil
+	}
+
+	// floor = quoted * (1 - slippage), rounded up.
+	keep := new(big.Rat).Sub(big.NewRat(1, 1), s)
+	num := new(big.Int).Mul(quote.ToTokenAmount.Big(), keep.Num())
+	floor, rem := new(big.Int).QuoRem(num, keep.Denom(), new(big.Int))
+	if rem.Sign() > 0 {
+		floor.Add(floor, big.NewInt(1))
+	}
+
+	minReceive := tx.MinReceiveAmount.Big()
+	if minReceive == nil {
+		e.add(ViolationMinReceive, "tx.minReceiveAmount", "is missing")
+	} else if minReceive.Cmp(floor) < 0 {
+		e.add(ViolationMinReceive, "tx.minReceiveAmount", "%s is below %s, the quoted %s less %s slippage", minReceive, floor, quote.ToTokenAmount, slippage)
+	}
+	return floor
+}
+
+// VerifyApprove checks an approve response: the approved contract must be the pinned approve
+// address, and the calldata must be approve(spender, amount) for that contract and amount.
+func (v *Verifier) VerifyApprove(ctx context.Context, req *dex.ApproveTransactionsRequest, result *dex.ApproveTransactionsResult) error {
+	e := new(VerifyError)
+
+	v.mu.Lock()
+	pinned := v.approves[req.ChainId]
+	v.mu.Unlock()
+	if pinned == "" {
+		e.add(ViolationApproveAddress, "dexContractAddress", "no approve address is pinned for chain %s", req.ChainId)
+	} else if !sameAddress(result.DexContractAddress, pinned) {
+		e.add(ViolationApproveAddress, "dexContractAddress", "%s is not the pinned approve address %s", result.DexContractAddress, pinned)
+	}
+	if err := v.checkReportedApprove(ctx, req.ChainId, e); err != nil {
+		return err
+	}
+
+	c, err := calldata.Decode(result.Data)
+	if err != nil || c.Function != calldata.Approve {
+		e.add(ViolationApproveCalldata, "data", "is not an approve(address,uint256) call")
+		return e.err()
+	}
+	if !sameAddress(c.Spender, result.DexContractAddress) {
+		e.add(ViolationApproveCalldata, "data", "approves %s instead of %s", c.Spender, result.DexContractAddress)
+	}
+	if amount, ok := new(big.Int).SetString(req.ApproveAmount, 10); ok && c.Amount.Big().Cmp(amount) != 0 {
+		e.add(ViolationApproveCalldata, "data", "approves %s instead of %s", c.Amount, amount)
+	}
+	return e.err()
+}
+
+// checkReportedApprove compares the approve address of the supported chains API with the pinned one.
+func (v *Verifier) checkReportedApprove(ctx context.Context, chainId chains.ChainID, e *VerifyError) error {
+	if v.dex == nil {
+		return nil
+	}
+	v.mu.Lock()
+	pinned, reported := v.approves[chainId], v.reported[chainId]
+	v.mu.Unlock()
+	if pinned == "" {
+		return nil
+	}
+
+	if reported == "" {
+		infos, err := v.dex.GetSupportedChains(ctx, chainId)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if info.ChainId == chainId {
+				reported = info.DexTokenApproveAddr
+			}
+		}
+		v.mu.Lock()
+		v.reported[chainId] = reported
+		v.mu.Unlock()
+	}
+	if reported != "" && !sameAddress(reported, pinned) {
+		e.add(ViolationApproveAddress, "dexTokenApproveAddress", "the API reports %s but %s is pinned", reported, pinned)
+	}
+	return nil
+}
+
+func sameAddress(a, b string) bool {
+	if strings.HasPrefix(a, "0x") || strings.HasPrefix(a, "0X") {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// sameToken compares token addresses, treating the zero address as the native token sentinel.
+func sameToken(chainId chains.ChainID, a, b string) bool {
+	if sameAddress(a, b) {
+		return true
+	}
+	c, ok := chains.Lookup(chainId)
+	if !ok {
+		return false
+	}
+	native := func(s string) bool {
+		return c.IsNativeToken(s) || s == "0x0000000000000000000000000000000000000000"
+	}
+	return native(a) && native(b)
+}
+
+func containsAddress(list []string, addr string) bool {
+	for _, a := range list {
+		if sameAddress(a, addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/types"
+)
+
+func word(hex string) string {
+	return strings.Repeat("0", 64-len(hex)) + hex
+}
+
+func TestVerifySwap(t *testing.T) {
+	const (
+		router = "0x7d0ccaa3fac1e5a943c5168b6ced828691b46b36"
+		user   = "0x3f6a3f57569358a512ccc0e513f171516b0fd42a"
+	)
+	v := NewVerifier(nil)
+	v.PinRouter("1", router)
+
+	req := &dex.GetSwapTxRequest{
+		ChainId:           "1",
+		Amount:            "1000000",
+		FromTokenAddress:  "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+		ToTokenAddress:    "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee",
+		Slippage:          "0.01",
+		UserWalletAddress: user,
+	}
+	result := &dex.GetSwapTxResult{
+		RouterResult: &dex.QuotesResult{ToTokenAmount: types.MustParseUint256("1000")},
+		Tx: &dex.Tx{
+			From:             "0x3F6A3F57569358A512CCC0E513F171516B0FD42A",
+			To:               router,
+			MinReceiveAmount: types.MustParseUint256("990"),
+			// smartSwapByOrderId selling 1000000 for at least 990, with empty batches.
+			Data: "0xb80c2f09" + word("1") +
+				word("a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48") + word("eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee") +
+				word("f4240") + word("3de") + word("6553f100") +
+				word("e0") + word("100") + word("120") + word("0") + word("0") + word("0"),
+		},
+	}
+	if err := v.VerifySwap(context.Background(), req, result, nil); err != nil {
+		t.Fatalf("VerifySwap = %v", err)
+	}
+
+	result.Tx.To = "0x0000000000000000000000000000000000000bad"
+	result.Tx.Value = types.MustParseUint256("1")
+	result.Tx.MinReceiveAmount = types.MustParseUint256("989")
+	result.Tx.Data = strings.Replace(result.Tx.Data, word("3de"), word("1"), 1)
+	err := v.VerifySwap(context.Background(), req, result, nil)
+	var verr *VerifyError
+	if !errors.As(err, &verr) {
+		t.Fatalf("VerifySwap = %v, want *VerifyError", err)
+	}
+	var codes []ViolationCode
+	for _, violation := range verr.Violations {
+		codes = append(codes, violation.Code)
+	}
+	want := []ViolationCode{ViolationRouter, ViolationValue, ViolationMinReceive, ViolationMinReceive}
+	if fmt.Sprint(codes) != fmt.Sprint(want) {
+		t.Errorf("violations = %v, want %v", codes, want)
+	}
+}
+
+func TestVerifyApprove(t *testing.T) {
+	const spender = "0x40aa958dd87fc8305b97f2ba922cddca374bcd7f"
+	v := NewVerifier(nil)
+	v.PinApproveAddress("1", spender)
+
+	req := &dex.ApproveTransactionsRequest{ChainId: "1", TokenContractAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", ApproveAmount: "1000000"}
+	result := &dex.ApproveTransactionsResult{
+		DexContractAddress: spender,
+		Data: "0x095ea7b3" +
+			"00000000000000000000000040aa958dd87fc8305b97f2ba922cddca374bcd7f" +
+			"00000000000000000000000000000000000000000000000000000000000f4240",
+	}
+	if err := v.VerifyApprove(context.Background(), req, result); err != nil {
+		t.Fatalf("VerifyApprove = %v", err)
+	}
+
+	result.Data = "0x095ea7b3" +
+		"0000000000000000000000000000000000000000000000000000000000000bad" +
+		"00000000000000000000000000000000000000000000000000000000000f4240"
+	if err := v.VerifyApprove(context.Background(), req, result); err == nil {
+		t.Fatal("VerifyApprove accepted an approval to another spender")
+	}
+}
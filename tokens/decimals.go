@@ -0,0 +1,146 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package tokens resolves token metadata, such as symbols and decimals, from the OKX OS token lists.
+package tokens
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+var ErrTokenNotFound = errors.New("token not found")
+
+type tokenKey struct {
+	chainId chains.ChainID
+	address string
+}
+
+// DecimalsResolver looks up token decimals and caches them.
+//
+// Decimals are taken from the DEX token list of the chain first, then from the cross-chain
+// token list, and finally from the wallet token detail, so tokens outside the lists can be
+// resolved too. A token list is fetched at most once per chain.
+type DecimalsResolver struct {
+	lists  *listLoader
+	wallet *wallet.WalletAPI
+
+	mu       sync.Mutex
+	decimals map[tokenKey]int
+}
+
+// NewDecimalsResolver creates a DecimalsResolver. walletAPI may be nil, in which case only the
+// DEX and cross-chain token lists are used.
+func NewDecimalsResolver(dexAPI *dex.DexAPI, walletAPI *wallet.WalletAPI) *DecimalsResolver {
+	return &DecimalsResolver{
+		lists:    newListLoader(dexAPI),
+		wallet:   walletAPI,
+		decimals: make(map[tokenKey]int),
+	}
+}
+
+// Set records the decimals of a token, overriding what the API reports.
+func (r *DecimalsResolver) Set(chainId chains.ChainID, tokenAddress string, decimals int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decimals[newTokenKey(chainId, tokenAddress)] = decimals
+}
+
+// Decimals returns the decimals of the token at tokenAddress on chainId.
+func (r *DecimalsResolver) Decimals(ctx context.Context, chainId chains.ChainID, tokenAddress string) (int, error) {
+	key := newTokenKey(chainId, tokenAddress)
+
+	r.mu.Lock()
+	d, ok := r.decimals[key]
+	r.mu.Unlock()
+	if ok {
+		return d, nil
+	}
+
+	list, err := r.lists.list(ctx, chainId)
+	if err != nil && r.wallet == nil {
+		return 0, err
+	}
+	if list != nil {
+		if t, ok := list.lookup(chainId, tokenAddress); ok {
+			return t.Decimals, nil
+		}
+	}
+	if r.wallet == nil {
+		return 0, ErrTokenNotFound
+	}
+
+	info, err := r.wallet.ProjectInformation(ctx, &wallet.ProjectInformationRequest{
+		ChainIndex:   chainId,
+		TokenAddress: tokenAddress,
+	})
+	if errors.Is(err, errcode.ErrResultsNotFound) {
+		return 0, ErrTokenNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d, ok := r.decimals[key]; ok {
+		return d, nil
+	}
+	r.decimals[key] = int(info.Decimals)
+	return int(info.Decimals), nil
+}
+
+// ToMinimalUnits converts a human-readable amount of a token, such as "1.5", into minimal
+// divisible units, ready to be used as the amount of a quote or swap request.
+func (r *DecimalsResolver) ToMinimalUnits(ctx context.Context, chainId chains.ChainID, tokenAddress, amount string) (types.Uint256, error) {
+	d, err := types.ParseDecimal(amount)
+	if err != nil {
+		return types.Uint256{}, err
+	}
+	decimals, err := r.Decimals(ctx, chainId, tokenAddress)
+	if err != nil {
+		return types.Uint256{}, err
+	}
+	return types.ToMinimalUnits(d, decimals)
+}
+
+// FromMinimalUnits converts an amount of a token in minimal divisible units into a human-readable amount.
+func (r *DecimalsResolver) FromMinimalUnits(ctx context.Context, chainId chains.ChainID, tokenAddress string, amount types.Uint256) (types.Decimal, error) {
+	decimals, err := r.Decimals(ctx, chainId, tokenAddress)
+	if err != nil {
+		return types.Decimal{}, err
+	}
+	return types.FromMinimalUnits(amount, decimals)
+}
+
+// newTokenKey normalizes hex addresses to lowercase, base58 addresses are case-sensitive.
+func newTokenKey(chainId chains.ChainID, tokenAddress string) tokenKey {
+	if strings.HasPrefix(tokenAddress, "0x") || strings.HasPrefix(tokenAddress, "0X") {
+		tokenAddress = strings.ToLower(tokenAddress)
+	}
+	return tokenKey{chainId: chainId, address: tokenAddress}
+}
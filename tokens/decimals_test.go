@@ -0,0 +1,65 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tokens
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/internal/transporttest"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+func TestDecimalsResolver(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/dex/aggregator/all-tokens": `[
+			{"decimals":"6","tokenContractAddress":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48","tokenSymbol":"USDC"},
+			{"decimals":"18","tokenContractAddress":"0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee","tokenSymbol":"ETH"}
+		]`,
+		"/api/v5/dex/cross-chain/supported/tokens": `[]`,
+		"/api/v5/wallet/token/token-detail":        `[{"decimals":"9","symbol":"ABC"}]`,
+	})
+	r := NewDecimalsResolver(dex.NewDexAPI(tr), wallet.NewWalletAPI(tr))
+	ctx := context.Background()
+
+	amount, err := r.ToMinimalUnits(ctx, "1", "0xA0b86991c6218b36c1d19d4a2e9eB0cE3606eB48", "1.5")
+	if err != nil || amount.String() != "1500000" {
+		t.Fatalf("ToMinimalUnits = %s, %v", amount, err)
+	}
+	human, err := r.FromMinimalUnits(ctx, "1", "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", amount)
+	if err != nil || human.Text() != "0.0000000000015" {
+		t.Fatalf("FromMinimalUnits = %s, %v", human, err)
+	}
+	d, err := r.Decimals(ctx, "1", "0x0000000000000000000000000000000000000001")
+	if err != nil || d != 9 {
+		t.Fatalf("Decimals = %d, %v", d, err)
+	}
+	if _, err := r.Decimals(ctx, "1", "0x0000000000000000000000000000000000000001"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := tr.Calls("/api/v5/dex/aggregator/all-tokens"); n != 1 {
+		t.Errorf("token list fetched %d times, want 1", n)
+	}
+	if n := tr.Calls("/api/v5/wallet/token/token-detail"); n != 1 {
+		t.Errorf("token detail fetched %d times, want 1", n)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tokens
+
+import (
+	"context"
+	"sync"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+)
+
+// tokenList is the merged DEX and cross-chain token list of a chain.
+type tokenList struct {
+	byAddress map[string]Token
+	bySymbol  map[symbolKey][]Token
+}
+
+// lookup returns the listed token at tokenAddress.
+func (l *tokenList) lookup(chainId chains.ChainID, tokenAddress string) (Token, bool) {
+	t, ok := l.byAddress[newTokenKey(chainId, tokenAddress).address]
+	return t, ok
+}
+
+// add records t unless a token with the same address is already listed.
+func (l *tokenList) add(t Token) {
+	if t.Address == "" {
+		return
+	}
+	addr := newTokenKey(t.ChainID, t.Address).address
+	if _, ok := l.byAddress[addr]; ok {
+		return
+	}
+	if c, ok := chains.Lookup(t.ChainID); ok {
+		t.Native = c.IsNativeToken(t.Address)
+	}
+	l.byAddress[addr] = t
+	if t.Symbol != "" {
+		key := newSymbolKey(t.ChainID, t.Symbol)
+		l.bySymbol[key] = append(l.bySymbol[key], t)
+	}
+}
+
+type listCall struct {
+	done chan struct{}
+	list *tokenList
+	err  error
+}
+
+// listLoader fetches the token lists of each chain once. Concurrent lookups of a chain share a
+// single fetch, and the fetch runs without holding the lock, so lookups of other chains and of
+// cached lists are not blocked by it.
+type listLoader struct {
+	dex *dex.DexAPI
+
+	mu    sync.Mutex
+	calls map[chains.ChainID]*listCall
+}
+
+func newListLoader(dexAPI *dex.DexAPI) *listLoader {
+	return &listLoader{dex: dexAPI, calls: make(map[chains.ChainID]*listCall)}
+}
+
+// list returns the token list of chainId, fetching it on first use. Failed fetches are not
+// cached.
+func (l *listLoader) list(ctx context.Context, chainId chains.ChainID) (*tokenList, error) {
+	l.mu.Lock()
+	c, ok := l.calls[chainId]
+	if !ok {
+		c = &listCall{done: make(chan struct{})}
+		l.calls[chainId] = c
+		l.mu.Unlock()
+
+		c.list, c.err = l.fetch(ctx, chainId)
+		if c.err != nil {
+			l.mu.Lock()
+			if l.calls[chainId] == c {
+				delete(l.calls, chainId)
+			}
+			l.mu.Unlock()
+		}
+		close(c.done)
+		return c.list, c.err
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.list, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// invalidate drops the token list of chainId, so it is fetched again on the next lookup.
+func (l *listLoader) invalidate(chainId chains.ChainID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.calls, chainId)
+}
+
+func (l *listLoader) fetch(ctx context.Context, chainId chains.ChainID) (*tokenList, error) {
+	list, err := l.dex.GetSupportedTokens(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	tl := &tokenList{byAddress: make(map[string]Token), bySymbol: make(map[symbolKey][]Token)}
+	for _, t := range list {
+		tl.add(Token{
+			ChainID:  chainId,
+			Symbol:   t.TokenSymbol,
+			Name:     t.TokenName,
+			Address:  t.TokenContractAddress,
+			Decimals: int(t.Decimals),
+		})
+	}
+
+	// not every chain supports cross-chain swaps, so failures here are not fatal.
+	if l.dex.CrossChain != nil {
+		if list, err := l.dex.CrossChain.GetSupportedTokens(ctx, chainId); err == nil {
+			for _, t := range list {
+				tl.add(Token{
+					ChainID:  chainId,
+					Symbol:   t.TokenSymbol,
+					Name:     t.TokenName,
+					Address:  t.TokenContractAddress,
+					Decimals: int(t.Decimals),
+				})
+			}
+		}
+	}
+	return tl, nil
+}
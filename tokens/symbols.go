@@ -0,0 +1,236 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+var ErrAmbiguousSymbol = errors.New("ambiguous token symbol")
+
+// Token is a token resolved from its symbol.
+type Token struct {
+	ChainID  chains.ChainID `json:"chainId"`
+	Symbol   string         `json:"symbol"`
+	Name     string         `json:"name,omitempty"`
+	Address  string         `json:"address"`
+	Decimals int            `json:"decimals"`
+	// Native reports whether the token is the native token of the chain, addressed by its sentinel address.
+	Native bool `json:"native,omitempty"`
+}
+
+// AmbiguousSymbolError is returned when several tokens of a chain share a symbol and none of them
+// is allowlisted. Candidates lists every token with the symbol.
+type AmbiguousSymbolError struct {
+	ChainID    chains.ChainID
+	Symbol     string
+	Candidates []Token
+}
+
+func (e *AmbiguousSymbolError) Error() string {
+	addrs := make([]string, 0, len(e.Candidates))
+	for _, t := range e.Candidates {
+		addrs = append(addrs, t.Address)
+	}
+	return fmt.Sprintf("ambiguous token symbol %s on chain %s: %s", e.Symbol, e.ChainID, strings.Join(addrs, ", "))
+}
+
+func (e *AmbiguousSymbolError) Is(target error) bool {
+	return target == ErrAmbiguousSymbol
+}
+
+// AllowlistEntry pins a symbol of a chain to a token address. Decimals may be omitted,
+// in which case they are resolved like those of any other token.
+type AllowlistEntry struct {
+	ChainID  chains.ChainID `json:"chainId"`
+	Symbol   string         `json:"symbol"`
+	Address  string         `json:"address"`
+	Decimals *int           `json:"decimals,omitempty"`
+}
+
+type symbolKey struct {
+	chainId chains.ChainID
+	symbol  string
+}
+
+func newSymbolKey(chainId chains.ChainID, symbol string) symbolKey {
+	return symbolKey{chainId: chainId, symbol: strings.ToUpper(strings.TrimSpace(symbol))}
+}
+
+// SymbolResolver maps token symbols, such as "USDT", to token addresses and decimals.
+//
+// Symbols are matched case-insensitively against the allowlist first, then against the native
+// token of the chain, and finally against the DEX and cross-chain token lists of the chain,
+// which are fetched at most once per chain. A symbol that matches several tokens in the lists
+// is reported as an *AmbiguousSymbolError; allowlist one of them to pick it.
+type SymbolResolver struct {
+	lists  *listLoader
+	wallet *wallet.WalletAPI
+
+	mu        sync.Mutex
+	allowlist map[symbolKey]AllowlistEntry
+}
+
+// NewSymbolResolver creates a SymbolResolver. walletAPI may be nil, in which case allowlisted
+// tokens outside the token lists must declare their decimals.
+func NewSymbolResolver(dexAPI *dex.DexAPI, walletAPI *wallet.WalletAPI) *SymbolResolver {
+	return &SymbolResolver{
+		lists:     newListLoader(dexAPI),
+		wallet:    walletAPI,
+		allowlist: make(map[symbolKey]AllowlistEntry),
+	}
+}
+
+// Allow pins entry.Symbol on entry.ChainID to entry.Address, overriding the token lists.
+func (r *SymbolResolver) Allow(entries ...AllowlistEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range entries {
+		r.allowlist[newSymbolKey(e.ChainID, e.Symbol)] = e
+	}
+}
+
+// LoadAllowlist reads a JSON array of allowlist entries from rd, e.g.
+//
+//	[{"chainId": "1", "symbol": "USDT", "address": "0xdac17f958d2ee523a2206206994597c13d831ec7", "decimals": 6}]
+func (r *SymbolResolver) LoadAllowlist(rd io.Reader) error {
+	var entries []AllowlistEntry
+	if err := json.NewDecoder(rd).Decode(&entries); err != nil {
+		return fmt.Errorf("tokens: decode allowlist: %w", err)
+	}
+	for i, e := range entries {
+		if e.ChainID == "" || e.Symbol == "" || e.Address == "" {
+			return fmt.Errorf("tokens: allowlist entry %d: chainId, symbol and address are required", i)
+		}
+	}
+	r.Allow(entries...)
+	return nil
+}
+
+// LoadAllowlistFile is like LoadAllowlist but reads the named file.
+func (r *SymbolResolver) LoadAllowlistFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.LoadAllowlist(f)
+}
+
+// Invalidate drops the cached token lists of chainId, so they are fetched again on the next lookup.
+func (r *SymbolResolver) Invalidate(chainId chains.ChainID) {
+	r.lists.invalidate(chainId)
+}
+
+// Resolve returns the token with symbol on chainId.
+func (r *SymbolResolver) Resolve(ctx context.Context, chainId chains.ChainID, symbol string) (*Token, error) {
+	key := newSymbolKey(chainId, symbol)
+
+	r.mu.Lock()
+	e, allowed := r.allowlist[key]
+	r.mu.Unlock()
+	if allowed {
+		return r.resolveAllowed(ctx, e)
+	}
+
+	if c, ok := chains.Lookup(chainId); ok && c.NativeTokenAddress != "" && strings.EqualFold(c.NativeSymbol, key.symbol) {
+		return &Token{
+			ChainID:  chainId,
+			Symbol:   c.NativeSymbol,
+			Address:  c.NativeTokenAddress,
+			Decimals: c.NativeDecimals,
+			Native:   true,
+		}, nil
+	}
+
+	list, err := r.lists.list(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	switch candidates := list.bySymbol[key]; len(candidates) {
+	case 0:
+		return nil, ErrTokenNotFound
+	case 1:
+		t := candidates[0]
+		return &t, nil
+	default:
+		return nil, &AmbiguousSymbolError{
+			ChainID:    chainId,
+			Symbol:     symbol,
+			Candidates: append([]Token(nil), candidates...),
+		}
+	}
+}
+
+// ResolveAddress is like Resolve but returns only the token address, which is what the
+// quote and swap requests take.
+func (r *SymbolResolver) ResolveAddress(ctx context.Context, chainId chains.ChainID, symbol string) (string, error) {
+	t, err := r.Resolve(ctx, chainId, symbol)
+	if err != nil {
+		return "", err
+	}
+	return t.Address, nil
+}
+
+func (r *SymbolResolver) resolveAllowed(ctx context.Context, e AllowlistEntry) (*Token, error) {
+	t := &Token{ChainID: e.ChainID, Symbol: e.Symbol, Address: e.Address}
+	if c, ok := chains.Lookup(e.ChainID); ok {
+		t.Native = c.IsNativeToken(e.Address)
+	}
+	if e.Decimals != nil {
+		t.Decimals = *e.Decimals
+		return t, nil
+	}
+
+	// the token lists are the cheapest source of decimals, fall back to the token detail.
+	if list, err := r.lists.list(ctx, e.ChainID); err == nil {
+		if c, ok := list.lookup(e.ChainID, e.Address); ok {
+			t.Name, t.Decimals = c.Name, c.Decimals
+			return t, nil
+		}
+	}
+	if r.wallet == nil {
+		return nil, ErrTokenNotFound
+	}
+	info, err := r.wallet.ProjectInformation(ctx, &wallet.ProjectInformationRequest{
+		ChainIndex:   e.ChainID,
+		TokenAddress: e.Address,
+	})
+	if errors.Is(err, errcode.ErrResultsNotFound) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Decimals = int(info.Decimals)
+	return t, nil
+}
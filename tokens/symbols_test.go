@@ -0,0 +1,86 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tokens
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/dex"
+	"github.com/imzhongqi/okxos/internal/transporttest"
+	"github.com/imzhongqi/okxos/wallet"
+)
+
+func TestSymbolResolver(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/dex/aggregator/all-tokens": `[
+			{"decimals":"6","tokenContractAddress":"0xdac17f958d2ee523a2206206994597c13d831ec7","tokenSymbol":"USDT"},
+			{"decimals":"6","tokenContractAddress":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48","tokenSymbol":"USDC"},
+			{"decimals":"18","tokenContractAddress":"0x1111111111111111111111111111111111111111","tokenSymbol":"USDC"}
+		]`,
+		"/api/v5/dex/cross-chain/supported/tokens": `[
+			{"decimals":"6","tokenContractAddress":"0xdAC17F958D2ee523a2206206994597C13D831ec7","tokenSymbol":"USDT"}
+		]`,
+		"/api/v5/wallet/token/token-detail": `[{"decimals":"8","symbol":"WBTC"}]`,
+	})
+	r := NewSymbolResolver(dex.NewDexAPI(tr), wallet.NewWalletAPI(tr))
+	ctx := context.Background()
+
+	tok, err := r.Resolve(ctx, chains.Ethereum, "usdt")
+	if err != nil || tok.Decimals != 6 || tok.Address != "0xdac17f958d2ee523a2206206994597c13d831ec7" {
+		t.Fatalf("Resolve(USDT) = %+v, %v", tok, err)
+	}
+
+	tok, err = r.Resolve(ctx, chains.Ethereum, "ETH")
+	if err != nil || !tok.Native || tok.Address != chains.EVMNativeTokenAddress || tok.Decimals != 18 {
+		t.Fatalf("Resolve(ETH) = %+v, %v", tok, err)
+	}
+
+	_, err = r.Resolve(ctx, chains.Ethereum, "USDC")
+	var ambiguous *AmbiguousSymbolError
+	if !errors.Is(err, ErrAmbiguousSymbol) || !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("Resolve(USDC) error = %v", err)
+	}
+
+	if _, err = r.Resolve(ctx, chains.Ethereum, "NOPE"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Resolve(NOPE) error = %v", err)
+	}
+
+	err = r.LoadAllowlist(strings.NewReader(`[
+		{"chainId":"1","symbol":"USDC","address":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		{"chainId":"1","symbol":"WBTC","address":"0x2260fac5e5542a773aa44fbcfedf7c193bc2c599"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok, err = r.Resolve(ctx, chains.Ethereum, "USDC"); err != nil || tok.Decimals != 6 {
+		t.Fatalf("Resolve(USDC) after allowlist = %+v, %v", tok, err)
+	}
+	if tok, err = r.Resolve(ctx, chains.Ethereum, "WBTC"); err != nil || tok.Decimals != 8 {
+		t.Fatalf("Resolve(WBTC) = %+v, %v", tok, err)
+	}
+
+	if n := tr.Calls("/api/v5/dex/aggregator/all-tokens"); n != 1 {
+		t.Errorf("token list fetched %d times, want 1", n)
+	}
+}
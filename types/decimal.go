@@ -0,0 +1,303 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var bigTen = big.NewInt(10)
+
+// maxExponent bounds the exponent accepted by ParseDecimal, so that a hostile value such as
+// "1e50000000" cannot make scaling take unbounded time and memory.
+const maxExponent = 1000
+
+// Decimal is an arbitrary-precision decimal number, such as a price, a rate or an amount in
+// human-readable units. It is stored exactly as unscaled * 10^-scale. The zero value is unset.
+type Decimal struct {
+	raw      string
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns the decimal unscaled * 10^-scale.
+func NewDecimal(unscaled *big.Int, scale int32) Decimal {
+	d := Decimal{unscaled: new(big.Int).Set(unscaled), scale: scale}
+	return d.normalize()
+}
+
+// DecimalFromInt64 returns n as a Decimal.
+func DecimalFromInt64(n int64) Decimal {
+	return Decimal{unscaled: big.NewInt(n)}
+}
+
+// ParseDecimal parses a decimal number such as "-1.25", "0.000001" or "1.5e-7".
+// The empty string parses to an unset value.
+func ParseDecimal(s string) (Decimal, error) {
+	if s == "" {
+		return Decimal{}, nil
+	}
+
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			return Decimal{}, fmt.Errorf("types: invalid decimal %q", s)
+		}
+		if exp > maxExponent || exp < -maxExponent {
+			return Decimal{}, fmt.Errorf("types: decimal %q out of range", s)
+		}
+		mantissa = s[:i]
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	if digits == "" || digits == "-" || digits == "+" || strings.ContainsAny(fracPart, "+-") {
+		return Decimal{}, fmt.Errorf("types: invalid decimal %q", s)
+	}
+	unscaled, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("types: invalid decimal %q", s)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale < -1<<31 || scale > 1<<31-1 {
+		return Decimal{}, fmt.Errorf("types: decimal %q out of range", s)
+	}
+	d := Decimal{unscaled: unscaled, scale: int32(scale)}
+	d = d.normalize()
+	d.raw = s
+	return d, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on error.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// normalize makes the scale non-negative, so that integers have scale 0.
+func (d Decimal) normalize() Decimal {
+	if d.scale < 0 {
+		d.unscaled = new(big.Int).Mul(d.unscaled, pow10(int64(-d.scale)))
+		d.scale = 0
+	}
+	return d
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(n), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale returns the unscaled value of d at the given scale, which must not be smaller than d's.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return new(big.Int).Set(d.int())
+	}
+	return new(big.Int).Mul(d.int(), pow10(int64(scale-d.scale)))
+}
+
+// IsSet reports whether d holds a value.
+func (d Decimal) IsSet() bool {
+	return d.unscaled != nil
+}
+
+// IsZero reports whether d is unset or zero.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Unscaled returns a copy of the unscaled value of d.
+func (d Decimal) Unscaled() *big.Int {
+	return new(big.Int).Set(d.int())
+}
+
+// Cmp compares d and y and returns -1, 0 or +1.
+func (d Decimal) Cmp(y Decimal) int {
+	scale := maxScale(d, y)
+	return d.rescale(scale).Cmp(y.rescale(scale))
+}
+
+// Add returns d + y.
+func (d Decimal) Add(y Decimal) Decimal {
+	scale := maxScale(d, y)
+	return Decimal{unscaled: new(big.Int).Add(d.rescale(scale), y.rescale(scale)), scale: scale}
+}
+
+// Sub returns d - y.
+func (d Decimal) Sub(y Decimal) Decimal {
+	scale := maxScale(d, y)
+	return Decimal{unscaled: new(big.Int).Sub(d.rescale(scale), y.rescale(scale)), scale: scale}
+}
+
+// Mul returns d * y.
+func (d Decimal) Mul(y Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), y.int()), scale: d.scale + y.scale}
+}
+
+// Quo returns d / y truncated toward zero to the given number of digits after the decimal point.
+// It panics if y is zero.
+func (d Decimal) Quo(y Decimal, scale int32) Decimal {
+	// d/y = (d.unscaled * 10^(scale + y.scale - d.scale)) / y.unscaled * 10^-scale
+	num := d.Unscaled()
+	shift := int64(scale) + int64(y.scale) - int64(d.scale)
+	den := y.Unscaled()
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return Decimal{unscaled: num.Quo(num, den), scale: scale}.normalize()
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Shift returns d * 10^n.
+func (d Decimal) Shift(n int32) Decimal {
+	return Decimal{unscaled: d.Unscaled(), scale: d.scale - n}.normalize()
+}
+
+// Truncate returns d with at most scale digits after the decimal point, rounding toward zero.
+func (d Decimal) Truncate(scale int32) Decimal {
+	if scale >= d.scale {
+		return Decimal{unscaled: d.Unscaled(), scale: d.scale}
+	}
+	q := new(big.Int).Quo(d.int(), pow10(int64(d.scale-scale)))
+	return Decimal{unscaled: q, scale: scale}.normalize()
+}
+
+// RoundUp returns d with at most scale digits after the decimal point, rounding away from zero.
+func (d Decimal) RoundUp(scale int32) Decimal {
+	if scale >= d.scale {
+		return Decimal{unscaled: d.Unscaled(), scale: d.scale}
+	}
+	q, r := new(big.Int).QuoRem(d.int(), pow10(int64(d.scale-scale)), new(big.Int))
+	if r.Sign() != 0 {
+		q.Add(q, big.NewInt(int64(d.Sign())))
+	}
+	return Decimal{unscaled: q, scale: scale}.normalize()
+}
+
+// BigInt returns the integer part of d and whether d is an integer.
+func (d Decimal) BigInt() (*big.Int, bool) {
+	if d.scale == 0 {
+		return d.Unscaled(), true
+	}
+	q, r := new(big.Int).QuoRem(d.int(), pow10(int64(d.scale)), new(big.Int))
+	return q, r.Sign() == 0
+}
+
+// Rat returns d as a big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.int(), pow10(int64(d.scale)))
+}
+
+// Float64 returns the nearest float64 to d.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// String returns the text the value was parsed from, or its plain decimal representation.
+func (d Decimal) String() string {
+	if d.raw != "" || d.unscaled == nil {
+		return d.raw
+	}
+	return d.Text()
+}
+
+// Text returns the plain decimal representation of d without trailing zeros, e.g. "1.5".
+func (d Decimal) Text() string {
+	n := d.int()
+	if d.scale == 0 {
+		return n.String()
+	}
+	digits := new(big.Int).Abs(n).String()
+	if len(digits) <= int(d.scale) {
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(d.scale)
+	s := strings.TrimRight(digits[:point]+"."+digits[point:], "0")
+	s = strings.TrimSuffix(s, ".")
+	if n.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(text []byte) (err error) {
+	*d, err = ParseDecimal(strings.TrimSpace(string(text)))
+	return
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return quote(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s, err := unquote(b)
+	if err != nil {
+		return err
+	}
+	*d, err = ParseDecimal(s)
+	return err
+}
+
+func maxScale(x, y Decimal) int32 {
+	if x.scale > y.scale {
+		return x.scale
+	}
+	return y.scale
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Int is a lenient int64. It decodes quoted and bare JSON numbers, and treats the empty
+// string and null as zero. Integral values written with a fraction, such as "6.0", are accepted.
+type Int int64
+
+// ParseInt parses s leniently as described on Int.
+func ParseInt(s string) (Int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return Int(n), nil
+	}
+	d, err := ParseDecimal(s)
+	if err != nil {
+		return 0, fmt.Errorf("types: invalid integer %q", s)
+	}
+	n, exact := d.BigInt()
+	if !exact || !n.IsInt64() {
+		return 0, fmt.Errorf("types: invalid integer %q", s)
+	}
+	return Int(n.Int64()), nil
+}
+
+// Int64 returns n as an int64.
+func (n Int) Int64() int64 {
+	return int64(n)
+}
+
+func (n Int) String() string {
+	return strconv.FormatInt(int64(n), 10)
+}
+
+func (n Int) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n *Int) UnmarshalText(text []byte) (err error) {
+	*n, err = ParseInt(string(text))
+	return
+}
+
+func (n Int) MarshalJSON() ([]byte, error) {
+	return quote(n.String()), nil
+}
+
+func (n *Int) UnmarshalJSON(b []byte) error {
+	s, err := unquote(b)
+	if err != nil {
+		return err
+	}
+	*n, err = ParseInt(s)
+	return err
+}
+
+// Millis is a Unix timestamp in milliseconds, as used by the API for transaction and price times.
+// It decodes like Int, so an unset timestamp is zero.
+type Millis int64
+
+// MillisOf returns t as a Millis.
+func MillisOf(t time.Time) Millis {
+	return Millis(t.UnixMilli())
+}
+
+// Time returns m as a time.Time, or the zero time.Time if m is zero.
+func (m Millis) Time() time.Time {
+	if m == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(m))
+}
+
+// IsZero reports whether m is unset.
+func (m Millis) IsZero() bool {
+	return m == 0
+}
+
+func (m Millis) String() string {
+	return strconv.FormatInt(int64(m), 10)
+}
+
+func (m Millis) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Millis) UnmarshalText(text []byte) error {
+	n, err := ParseInt(string(text))
+	*m = Millis(n)
+	return err
+}
+
+func (m Millis) MarshalJSON() ([]byte, error) {
+	return quote(m.String()), nil
+}
+
+func (m *Millis) UnmarshalJSON(b []byte) error {
+	var n Int
+	err := n.UnmarshalJSON(b)
+	*m = Millis(n)
+	return err
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUint256JSON(t *testing.T) {
+	var v struct {
+		A Uint256 `json:"a"`
+		B Uint256 `json:"b"`
+		C Uint256 `json:"c"`
+		D Uint256 `json:"d"`
+	}
+	err := json.Unmarshal([]byte(`{"a":"1000000000000000000","b":21000,"c":"","d":null}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.A.String() != "1000000000000000000" || v.B.String() != "21000" {
+		t.Errorf("unexpected values %s %s", v.A, v.B)
+	}
+	if v.C.IsSet() || v.D.IsSet() || !v.C.IsZero() {
+		t.Error("expected empty and null amounts to be unset")
+	}
+	if n, ok := v.B.Uint64(); !ok || n != 21000 {
+		t.Errorf("Uint64() = %d, %v", n, ok)
+	}
+
+	for _, s := range []string{"-1", "1.5", "0x1" + strings.Repeat("0", 64)} {
+		if _, err := ParseUint256(s); err == nil {
+			t.Errorf("ParseUint256(%q) expected error", s)
+		}
+	}
+	if u := MustParseUint256("0xff"); u.Big().Int64() != 255 {
+		t.Errorf("0xff parsed as %s", u.Big())
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		in, text string
+	}{
+		{"1.50", "1.5"},
+		{"-0.0001", "-0.0001"},
+		{"1.5e-7", "0.00000015"},
+		{"12E3", "12000"},
+		{"100", "100"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q): %v", tt.in, err)
+		}
+		if d.String() != tt.in {
+			t.Errorf("String() = %q, want the original %q", d.String(), tt.in)
+		}
+		if d.Text() != tt.text {
+			t.Errorf("ParseDecimal(%q).Text() = %q, want %q", tt.in, d.Text(), tt.text)
+		}
+	}
+
+	for _, s := range []string{".", "-", "1.2.3", "abc", "1e", "1e50000000", "1e-2147483648"} {
+		if _, err := ParseDecimal(s); err == nil {
+			t.Errorf("ParseDecimal(%q) expected error", s)
+		}
+	}
+
+	a, b := MustParseDecimal("1.25"), MustParseDecimal("0.5")
+	if got := a.Add(b).Text(); got != "1.75" {
+		t.Errorf("Add = %s", got)
+	}
+	if got := a.Sub(b).Text(); got != "0.75" {
+		t.Errorf("Sub = %s", got)
+	}
+	if got := a.Mul(b).Text(); got != "0.625" {
+		t.Errorf("Mul = %s", got)
+	}
+	if got := a.Quo(MustParseDecimal("3"), 4).Text(); got != "0.4166" {
+		t.Errorf("Quo = %s", got)
+	}
+	if got := a.Shift(6).Text(); got != "1250000" {
+		t.Errorf("Shift = %s", got)
+	}
+	if got := MustParseDecimal("1.2345").Truncate(2).Text(); got != "1.23" {
+		t.Errorf("Truncate = %s", got)
+	}
+	if got := MustParseDecimal("1.2301").RoundUp(2).Text(); got != "1.24" {
+		t.Errorf("RoundUp = %s", got)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(MustParseDecimal("1.250")) != 0 {
+		t.Error("unexpected Cmp results")
+	}
+	if n, exact := MustParseDecimal("42.0").BigInt(); !exact || n.Int64() != 42 {
+		t.Errorf("BigInt = %s, %v", n, exact)
+	}
+}
+
+func TestIntAndMillis(t *testing.T) {
+	var v struct {
+		A Int    `json:"a"`
+		B Int    `json:"b"`
+		C Int    `json:"c"`
+		D Int    `json:"d"`
+		T Millis `json:"t"`
+	}
+	err := json.Unmarshal([]byte(`{"a":"18","b":6,"c":"","d":"6.0","t":"1597026383085"}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 18 || v.B != 6 || v.C != 0 || v.D != 6 {
+		t.Errorf("unexpected values %+v", v)
+	}
+	if want := time.Date(2020, 8, 10, 2, 26, 23, 85e6, time.UTC); !v.T.Time().Equal(want) {
+		t.Errorf("Time() = %s, want %s", v.T.Time(), want)
+	}
+	if err := json.Unmarshal([]byte(`{"a":"1.5"}`), &v); err == nil {
+		t.Error("expected error for a fractional integer")
+	}
+	if err := json.Unmarshal([]byte(`{"a":"1e50000000"}`), &v); err == nil {
+		t.Error("expected error for a huge exponent")
+	}
+}
+
+func TestMinimalUnits(t *testing.T) {
+	u, err := ToMinimalUnits(MustParseDecimal("1.5"), 6)
+	if err != nil || u.String() != "1500000" {
+		t.Errorf("ToMinimalUnits(1.5, 6) = %s, %v", u, err)
+	}
+	u, err = ToMinimalUnits(MustParseDecimal("0.123456789012345678"), 18)
+	if err != nil || u.String() != "123456789012345678" {
+		t.Errorf("ToMinimalUnits(0.123456789012345678, 18) = %s, %v", u, err)
+	}
+	if _, err := ToMinimalUnits(MustParseDecimal("1.0000001"), 6); err == nil {
+		t.Error("expected error for too many decimals")
+	}
+	if _, err := ToMinimalUnits(MustParseDecimal("-1"), 6); err == nil {
+		t.Error("expected error for a negative amount")
+	}
+	if got, err := FromMinimalUnits(MustParseUint256("1500000"), 6); err != nil || got.Text() != "1.5" {
+		t.Errorf("FromMinimalUnits = %s, %v", got.Text(), err)
+	}
+	if got, err := FromMinimalUnits(MustParseUint256("1"), 18); err != nil || got.Text() != "0.000000000000000001" {
+		t.Errorf("FromMinimalUnits = %s, %v", got.Text(), err)
+	}
+
+	// decimals from a malformed response must not wrap around int32.
+	for _, decimals := range []int{-1, MaxDecimals + 1, 1<<31 - 1} {
+		if _, err := ToMinimalUnits(MustParseDecimal("1"), decimals); err == nil {
+			t.Errorf("ToMinimalUnits with %d decimals: expected error", decimals)
+		}
+		if _, err := FromMinimalUnits(MustParseUint256("1"), decimals); err == nil {
+			t.Errorf("FromMinimalUnits with %d decimals: expected error", decimals)
+		}
+	}
+}
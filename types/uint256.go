@@ -0,0 +1,144 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Uint256 is an unsigned 256-bit integer, such as a token amount in minimal divisible units
+// or a gas price in wei. The zero value is an unset amount.
+type Uint256 struct {
+	raw string
+	n   *big.Int
+}
+
+// NewUint256 returns n as a Uint256. It panics if n is negative or does not fit in 256 bits.
+func NewUint256(n *big.Int) Uint256 {
+	if n.Sign() < 0 || n.Cmp(maxUint256) > 0 {
+		panic(fmt.Sprintf("types: %s out of uint256 range", n))
+	}
+	return Uint256{n: new(big.Int).Set(n)}
+}
+
+// Uint256FromUint64 returns n as a Uint256.
+func Uint256FromUint64(n uint64) Uint256 {
+	return Uint256{n: new(big.Int).SetUint64(n)}
+}
+
+// ParseUint256 parses a decimal or 0x-prefixed hexadecimal integer. The empty string
+// parses to an unset amount.
+func ParseUint256(s string) (Uint256, error) {
+	if s == "" {
+		return Uint256{}, nil
+	}
+	n, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		n, ok = n.SetString(s[2:], 16)
+	} else {
+		n, ok = n.SetString(s, 10)
+	}
+	if !ok {
+		return Uint256{}, fmt.Errorf("types: invalid uint256 %q", s)
+	}
+	if n.Sign() < 0 || n.Cmp(maxUint256) > 0 {
+		return Uint256{}, fmt.Errorf("types: %q out of uint256 range", s)
+	}
+	return Uint256{raw: s, n: n}, nil
+}
+
+// MustParseUint256 is like ParseUint256 but panics on error.
+func MustParseUint256(s string) Uint256 {
+	u, err := ParseUint256(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// IsSet reports whether u holds a value.
+func (u Uint256) IsSet() bool {
+	return u.n != nil
+}
+
+// IsZero reports whether u is unset or zero.
+func (u Uint256) IsZero() bool {
+	return u.n == nil || u.n.Sign() == 0
+}
+
+// Big returns a copy of u as a big.Int. An unset amount is zero.
+func (u Uint256) Big() *big.Int {
+	if u.n == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(u.n)
+}
+
+// Uint64 returns u as a uint64 and whether it fits.
+func (u Uint256) Uint64() (uint64, bool) {
+	if u.n == nil {
+		return 0, true
+	}
+	return u.n.Uint64(), u.n.IsUint64()
+}
+
+// Decimal returns u as a Decimal.
+func (u Uint256) Decimal() Decimal {
+	return NewDecimal(u.Big(), 0)
+}
+
+// Cmp compares u and v and returns -1, 0 or +1.
+func (u Uint256) Cmp(v Uint256) int {
+	return u.Big().Cmp(v.Big())
+}
+
+// String returns the text the value was parsed from, or its decimal representation.
+func (u Uint256) String() string {
+	if u.raw != "" || u.n == nil {
+		return u.raw
+	}
+	return u.n.String()
+}
+
+func (u Uint256) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *Uint256) UnmarshalText(text []byte) (err error) {
+	*u, err = ParseUint256(strings.TrimSpace(string(text)))
+	return
+}
+
+func (u Uint256) MarshalJSON() ([]byte, error) {
+	return quote(u.String()), nil
+}
+
+func (u *Uint256) UnmarshalJSON(b []byte) error {
+	s, err := unquote(b)
+	if err != nil {
+		return err
+	}
+	*u, err = ParseUint256(s)
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package types
+
+import (
+	"fmt"
+)
+
+// MaxDecimals is the largest number of token decimals accepted by the unit conversions. Decimals
+// come from API responses and token lists, so larger values are rejected as malformed.
+const MaxDecimals = 255
+
+func checkDecimals(decimals int) error {
+	if decimals < 0 || decimals > MaxDecimals {
+		return fmt.Errorf("types: invalid decimals %d", decimals)
+	}
+	return nil
+}
+
+// ToMinimalUnits converts a human-readable token amount, such as 1.5 USDC, into minimal
+// divisible units using the token decimals, e.g. 1500000 for 6 decimals.
+// It fails instead of rounding when amount has more fractional digits than decimals.
+func ToMinimalUnits(amount Decimal, decimals int) (Uint256, error) {
+	if err := checkDecimals(decimals); err != nil {
+		return Uint256{}, err
+	}
+	if amount.Sign() < 0 {
+		return Uint256{}, fmt.Errorf("types: negative amount %s", amount)
+	}
+	n, exact := amount.Shift(int32(decimals)).BigInt()
+	if !exact {
+		return Uint256{}, fmt.Errorf("types: amount %s has more than %d decimals", amount, decimals)
+	}
+	if n.Cmp(maxUint256) > 0 {
+		return Uint256{}, fmt.Errorf("types: amount %s out of uint256 range", amount)
+	}
+	return Uint256{n: n}, nil
+}
+
+// FromMinimalUnits converts an amount in minimal divisible units into a human-readable amount
+// using the token decimals, e.g. 1500000 with 6 decimals is 1.5.
+func FromMinimalUnits(amount Uint256, decimals int) (Decimal, error) {
+	if err := checkDecimals(decimals); err != nil {
+		return Decimal{}, err
+	}
+	return NewDecimal(amount.Big(), int32(decimals)), nil
+}
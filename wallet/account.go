@@ -21,24 +21,49 @@ package wallet
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/chains"
 	"github.com/imzhongqi/okxos/errcode"
 )
 
 type Address struct {
-	ChainIndex string `json:"chainIndex"`
-	Address    string `json:"address"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
+	Address    string         `json:"address"`
 }
 
 type CreateAccountRequest struct {
 	Addresses []*Address `json:"addresses"`
 }
 
+// Validate reports the fields of the request that are missing or malformed.
+func (r *CreateAccountRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	if len(r.Addresses) == 0 {
+		v.Add("addresses", "is required")
+	}
+	for i, addr := range r.Addresses {
+		if addr == nil {
+			v.Add(fmt.Sprintf("addresses[%d]", i), "is required")
+			continue
+		}
+		v.Required(fmt.Sprintf("addresses[%d].chainIndex", i), addr.ChainIndex.String())
+		v.Required(fmt.Sprintf("addresses[%d].address", i), addr.Address)
+		address.Check(v, fmt.Sprintf("addresses[%d].address", i), addr.ChainIndex, addr.Address)
+	}
+	return v.Err()
+}
+
 type CreateAccountResult struct {
 	AccountId string `json:"accountId"`
 }
 
 func (w *WalletAPI) CreateAccount(ctx context.Context, req *CreateAccountRequest) (result *CreateAccountResult, err error) {
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	req = &CreateAccountRequest{Addresses: normalizeAddresses(req.Addresses)}
 	var results []*CreateAccountResult
 	err = w.tr.Post(ctx, "/api/v5/wallet/account/create-wallet-account", req, &results)
 	if err != nil {
@@ -68,8 +93,34 @@ type UpdateAccountRequest struct {
 	Addresses  []*Address `json:"addresses"`
 }
 
+// Validate reports the fields of the request that are missing or malformed.
+func (r *UpdateAccountRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("accountId", r.AccountId)
+	v.Required("updateType", string(r.UpdateType))
+	v.OneOf("updateType", string(r.UpdateType), string(UpdateTypeAdd), string(UpdateTypeDelete))
+	if len(r.Addresses) == 0 {
+		v.Add("addresses", "is required")
+	}
+	for i, addr := range r.Addresses {
+		if addr == nil {
+			v.Add(fmt.Sprintf("addresses[%d]", i), "is required")
+			continue
+		}
+		v.Required(fmt.Sprintf("addresses[%d].chainIndex", i), addr.ChainIndex.String())
+		v.Required(fmt.Sprintf("addresses[%d].address", i), addr.Address)
+		address.Check(v, fmt.Sprintf("addresses[%d].address", i), addr.ChainIndex, addr.Address)
+	}
+	return v.Err()
+}
+
 func (w *WalletAPI) UpdateAccount(ctx context.Context, req *UpdateAccountRequest) (err error) {
-	err = w.tr.Post(ctx, "/api/v5/wallet/account/update-wallet-account", req, nil)
+	if err = req.Validate(); err != nil {
+		return err
+	}
+	n := *req
+	n.Addresses = normalizeAddresses(req.Addresses)
+	err = w.tr.Post(ctx, "/api/v5/wallet/account/update-wallet-account", &n, nil)
 	return
 }
 
@@ -0,0 +1,73 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wallet
+
+import (
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/errcode"
+)
+
+// checkAddress checks addr against each of the chains it will be queried on,
+// and records at most one error.
+func checkAddress(v *errcode.ValidationError, field string, chainIds []chains.ChainID, addr string) {
+	for _, chainId := range chainIds {
+		n := len(v.Fields)
+		address.Check(v, field, chainId, addr)
+		if len(v.Fields) > n {
+			return
+		}
+	}
+}
+
+// canonicalAddress normalizes an address that is queried on several chains, which is only
+// possible when its shape identifies the family, as it does for EVM addresses.
+func canonicalAddress(chainIds []chains.ChainID, addr string) string {
+	if len(chainIds) == 1 {
+		return address.Canonical(chainIds[0], addr)
+	}
+	return address.Canonical("", addr)
+}
+
+func normalizeAddresses(addrs []*Address) []*Address {
+	out := make([]*Address, len(addrs))
+	for i, addr := range addrs {
+		out[i] = &Address{ChainIndex: addr.ChainIndex, Address: address.Canonical(addr.ChainIndex, addr.Address)}
+	}
+	return out
+}
+
+func normalizeTokenAddresses(tokens []*TokenAddress) []*TokenAddress {
+	out := make([]*TokenAddress, len(tokens))
+	for i, token := range tokens {
+		out[i] = &TokenAddress{ChainIndex: token.ChainIndex, TokenAddress: address.NormalizeToken(token.ChainIndex, token.TokenAddress)}
+	}
+	return out
+}
+
+func tokenChains(tokens []*TokenAddress) []chains.ChainID {
+	ids := make([]chains.ChainID, 0, len(tokens))
+	for _, token := range tokens {
+		if token != nil {
+			ids = append(ids, token.ChainIndex)
+		}
+	}
+	return ids
+}
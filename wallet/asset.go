@@ -21,43 +21,64 @@ package wallet
 
 import (
 	"context"
-	"strconv"
-	"strings"
+	"encoding/json"
+	"fmt"
 
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type GetTotalValueByAddressRequest struct {
 	// Address Get the total valuation for the address
 	Address string `json:"address"`
 	// Chains Query the total balance of the multiple chains, which can be separated by ",". Supports up to 50 chains
-	Chains []string `json:"chains"`
+	Chains []chains.ChainID `json:"chains"`
 	// AssetType Type of asset to query, defaults to total balance of all assets.
 	// 0: Query total balance of all assets, including tokens and DeFi assets;
 	// 1: Query only token balance;
 	// 2: Query only DeFi balance
-	AssetType string `json:"assetType"`
+	AssetType string `json:"assetType,omitempty"`
 	// ExcludeRiskToken Option to filter out potentially risky airdrop tokens.
 	// Defaults to filtering.
 	// true: filter
 	// false: do not filter
-	ExcludeRiskToken *bool `json:"excludeRiskToken"`
+	ExcludeRiskToken *bool `json:"excludeRiskToken,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTotalValueByAddressRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("address", r.Address)
+	if len(r.Chains) == 0 {
+		v.Add("chains", "is required")
+	}
+	checkAddress(v, "address", r.Chains, r.Address)
+	v.OneOf("assetType", r.AssetType, "0", "1", "2")
+	return v.Err()
+}
+
+func (r *GetTotalValueByAddressRequest) normalize() *GetTotalValueByAddressRequest {
+	n := *r
+	n.Address = canonicalAddress(r.Chains, r.Address)
+	return &n
 }
 
 type TotalValueResult struct {
 	// Returns the total asset balance based on the query asset type, expressed in USD
-	TotalValue string `json:"totalValue"`
+	TotalValue types.Decimal `json:"totalValue"`
 }
 
 // Get Total Value By Address
 func (w *WalletAPI) GetTotalValueByAddress(ctx context.Context, req *GetTotalValueByAddressRequest) (result *TotalValueResult, err error) {
-	params := map[string]string{
-		"address":   req.Address,
-		"chains":    strings.Join(req.Chains, ","),
-		"assetType": req.AssetType,
+	if err = req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.ExcludeRiskToken != nil {
-		params["excludeRiskToken"] = strconv.FormatBool(*req.ExcludeRiskToken)
+	params, err := client.EncodeQuery(req.normalize())
+	if err != nil {
+		return nil, err
 	}
 	var results []*TotalValueResult
 	err = w.tr.Get(ctx, "/api/v5/wallet/asset/total-value-by-address", params, &results)
@@ -74,38 +95,67 @@ type GetTotalTokenBalancesByAddressRequest struct {
 	// Address Query the token balances for the specified address
 	Address string `json:"address"`
 	// Chains Query the token balances for the specified chains, which can be separated by ",". A maximum of 50 chains is supported.
-	Chains []string `json:"chains"`
+	Chains []chains.ChainID `json:"chains"`
 	// Filter
 	// 0: Filter out risk airdrop tokens
 	// 1: Do not filter
 	// Default is to filter
-	Filter string `json:"filter"`
+	Filter string `json:"filter,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTotalTokenBalancesByAddressRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("address", r.Address)
+	if len(r.Chains) == 0 {
+		v.Add("chains", "is required")
+	}
+	checkAddress(v, "address", r.Chains, r.Address)
+	v.OneOf("filter", r.Filter, "0", "1")
+	return v.Err()
+}
+
+func (r *GetTotalTokenBalancesByAddressRequest) normalize() *GetTotalTokenBalancesByAddressRequest {
+	n := *r
+	n.Address = canonicalAddress(r.Chains, r.Address)
+	return &n
 }
 
 type TokenBalance struct {
-	ChainIndex      string `json:"chainIndex"`
-	TokenAddress    string `json:"tokenAddress"`
-	Address         string `json:"address"`
-	Symbol          string `json:"symbol"`
-	Balance         string `json:"balance"`
-	TokenPrice      string `json:"tokenPrice"`
-	TokenType       string `json:"tokenType"`
-	TransferAmount  string `json:"transferAmount"`
-	AvailableAmount string `json:"availableAmount"`
-	IsRiskToken     bool   `json:"isRiskToken"`
+	ChainIndex      chains.ChainID `json:"chainIndex"`
+	TokenAddress    string         `json:"tokenAddress"`
+	Address         string         `json:"address"`
+	Symbol          string         `json:"symbol"`
+	Balance         types.Decimal  `json:"balance"`
+	TokenPrice      types.Decimal  `json:"tokenPrice"`
+	TokenType       string         `json:"tokenType"`
+	TransferAmount  types.Decimal  `json:"transferAmount"`
+	AvailableAmount types.Decimal  `json:"availableAmount"`
+	IsRiskToken     bool           `json:"isRiskToken"`
+
+	// Extra holds the response fields that are not declared above yet.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *TokenBalance) UnmarshalJSON(data []byte) (err error) {
+	type alias TokenBalance
+	r.Extra, err = client.DecodeExtra(data, (*alias)(r))
+	return
 }
 
 type TokenBalanceResult struct {
 	TokenAssets []*TokenBalance `json:"tokenAssets"`
-	TimeStamp   string          `json:"timeStamp"`
+	TimeStamp   types.Millis    `json:"timeStamp"`
 }
 
 // Get Total Token Balances By Address
 func (w *WalletAPI) GetTotalTokenBalancesByAddress(ctx context.Context, req *GetTotalTokenBalancesByAddressRequest) (result *TokenBalanceResult, err error) {
-	params := map[string]string{
-		"address": req.Address,
-		"chains":  strings.Join(req.Chains, ","),
-		"filter":  req.Filter,
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := client.EncodeQuery(req.normalize())
+	if err != nil {
+		return nil, err
 	}
 	var results []*TokenBalanceResult
 	err = w.tr.Get(ctx, "/api/v5/wallet/asset/all-token-balances-by-address", params, &results)
@@ -119,8 +169,8 @@ func (w *WalletAPI) GetTotalTokenBalancesByAddress(ctx context.Context, req *Get
 }
 
 type TokenAddress struct {
-	TokenAddress string `json:"tokenAddress"`
-	ChainIndex   string `json:"chainIndex"`
+	TokenAddress string         `json:"tokenAddress"`
+	ChainIndex   chains.ChainID `json:"chainIndex"`
 }
 
 type GetTokenBalancesByAddressRequest struct {
@@ -135,10 +185,36 @@ type GetTokenBalancesByAddressRequest struct {
 	Filter string `json:"filter"`
 }
 
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTokenBalancesByAddressRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("address", r.Address)
+	checkAddress(v, "address", tokenChains(r.TokenAddresses), r.Address)
+	if len(r.TokenAddresses) == 0 {
+		v.Add("tokenAddresses", "is required")
+	}
+	for i, token := range r.TokenAddresses {
+		if token == nil {
+			v.Add(fmt.Sprintf("tokenAddresses[%d]", i), "is required")
+			continue
+		}
+		v.Required(fmt.Sprintf("tokenAddresses[%d].chainIndex", i), token.ChainIndex.String())
+		address.CheckToken(v, fmt.Sprintf("tokenAddresses[%d].tokenAddress", i), token.ChainIndex, token.TokenAddress)
+	}
+	v.OneOf("filter", r.Filter, "0", "1")
+	return v.Err()
+}
+
 // Get Token Balances By Address
 func (w *WalletAPI) GetTokenBalancesByAddress(ctx context.Context, req *GetTokenBalancesByAddressRequest) (result *TokenBalanceResult, err error) {
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	n := *req
+	n.Address = canonicalAddress(tokenChains(req.TokenAddresses), req.Address)
+	n.TokenAddresses = normalizeTokenAddresses(req.TokenAddresses)
 	var results []*TokenBalanceResult
-	err = w.tr.Post(ctx, "/api/v5/wallet/asset/token-balances-by-address", req, &results)
+	err = w.tr.Post(ctx, "/api/v5/wallet/asset/token-balances-by-address", &n, &results)
 	if err != nil {
 		return nil, err
 	}
@@ -152,26 +228,33 @@ type GetTotalValueByAccountRequest struct {
 	// AccountId Query the total valuation for the specified account
 	AccountId string `json:"accountId"`
 	// Chains Query the total valuation for the specified chains, which can be separated by ",". A maximum of 50 chains is supported.
-	Chains []string `json:"chains"`
+	Chains []chains.ChainID `json:"chains,omitempty"`
 	// AssetType Type of asset to query, defaults to total balance of all assets.
 	// 0: Query total balance of all assets, including tokens and DeFi assets;
 	// 1: Query only token balance;
 	// 2: Query only DeFi balance
-	AssetType string `json:"assetType"`
+	AssetType string `json:"assetType,omitempty"`
 	// ExcludeRiskToken Option to filter out potentially risky airdrop tokens. Defaults to filtering.
 	// true: filter, false: do not filter
-	ExcludeRiskToken *bool `json:"excludeRiskToken"`
+	ExcludeRiskToken *bool `json:"excludeRiskToken,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTotalValueByAccountRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("accountId", r.AccountId)
+	v.OneOf("assetType", r.AssetType, "0", "1", "2")
+	return v.Err()
 }
 
 // Get Total Value By Account
 func (w *WalletAPI) GetTotalValueByAccount(ctx context.Context, req *GetTotalValueByAccountRequest) (result *TotalValueResult, err error) {
-	params := map[string]string{
-		"accountId": req.AccountId,
-		"chains":    strings.Join(req.Chains, ","),
-		"assetType": req.AssetType,
+	if err = req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.ExcludeRiskToken != nil {
-		params["excludeRiskToken"] = strconv.FormatBool(*req.ExcludeRiskToken)
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 	var results []*TotalValueResult
 	err = w.tr.Get(ctx, "/api/v5/wallet/asset/total-value", params, &results)
@@ -188,24 +271,30 @@ type GetTotalTokenBalancesByAccountRequest struct {
 	// AccountId Query the token balances for the specified account
 	AccountId string `json:"accountId"`
 	// Chains Query the token balances for the specified chains, which can be separated by ",". A maximum of 50 chains is supported.
-	Chains []string `json:"chains"`
+	Chains []chains.ChainID `json:"chains,omitempty"`
 	// Filter
 	// 0: Filter out risk airdrop tokens
 	// 1: Do not filter
 	// Default is to filter
-	Filter string `json:"filter"`
+	Filter string `json:"filter,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTotalTokenBalancesByAccountRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("accountId", r.AccountId)
+	v.OneOf("filter", r.Filter, "0", "1")
+	return v.Err()
 }
 
 // Get Total Token Balances By Account
 func (w *WalletAPI) GetTotalTokenBalancesByAccount(ctx context.Context, req *GetTotalTokenBalancesByAccountRequest) (result *TokenBalanceResult, err error) {
-	params := map[string]string{
-		"accountId": req.AccountId,
-	}
-	if len(req.Chains) > 0 {
-		params["chains"] = strings.Join(req.Chains, ",")
+	if err = req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.Filter != "" {
-		params["filter"] = req.Filter
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 	var results []*TokenBalanceResult
 	err = w.tr.Get(ctx, "/api/v5/wallet/asset/wallet-all-token-balances", params, &results)
@@ -225,10 +314,33 @@ type GetTokenBalancesByAccountRequest struct {
 	TokenAddresses []*TokenAddress `json:"tokenAddresses"`
 }
 
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTokenBalancesByAccountRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("accountId", r.AccountId)
+	if len(r.TokenAddresses) == 0 {
+		v.Add("tokenAddresses", "is required")
+	}
+	for i, token := range r.TokenAddresses {
+		if token == nil {
+			v.Add(fmt.Sprintf("tokenAddresses[%d]", i), "is required")
+			continue
+		}
+		v.Required(fmt.Sprintf("tokenAddresses[%d].chainIndex", i), token.ChainIndex.String())
+		address.CheckToken(v, fmt.Sprintf("tokenAddresses[%d].tokenAddress", i), token.ChainIndex, token.TokenAddress)
+	}
+	return v.Err()
+}
+
 // Get Token Balances By Account
 func (w *WalletAPI) GetTokenBalancesByAccount(ctx context.Context, req *GetTokenBalancesByAccountRequest) (result *TokenBalanceResult, err error) {
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	n := *req
+	n.TokenAddresses = normalizeTokenAddresses(req.TokenAddresses)
 	var results []*TokenBalanceResult
-	err = w.tr.Post(ctx, "/api/v5/wallet/asset/token-balances", req, &results)
+	err = w.tr.Post(ctx, "/api/v5/wallet/asset/token-balances", &n, &results)
 	if err != nil {
 		return nil, err
 	}
@@ -21,16 +21,18 @@ package wallet
 
 import (
 	"context"
-	"strconv"
 
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type SupportedChains struct {
-	Name       string `json:"name"`
-	LogoUrl    string `json:"logoUrl"`
-	ShortName  string `json:"shortName"`
-	ChainIndex string `json:"chainIndex"`
+	Name       string         `json:"name"`
+	LogoUrl    string         `json:"logoUrl"`
+	ShortName  string         `json:"shortName"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 }
 
 // Supported Blockchains
@@ -39,16 +41,34 @@ func (w *WalletAPI) SupportedChains(ctx context.Context) (result []*SupportedCha
 	return
 }
 
+// ListChains lists the chains supported by the wallet API, it implements chains.Source.
+func (w *WalletAPI) ListChains(ctx context.Context) ([]chains.Chain, error) {
+	supported, err := w.SupportedChains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]chains.Chain, 0, len(supported))
+	for _, c := range supported {
+		list = append(list, chains.Chain{
+			ID:        c.ChainIndex,
+			Name:      c.Name,
+			ShortName: c.ShortName,
+			Wallet:    true,
+		})
+	}
+	return list, nil
+}
+
 type TokenIndexPriceRequest struct {
-	ChainIndex   string `json:"chainIndex"`
-	TokenAddress string `json:"tokenAddress"`
+	ChainIndex   chains.ChainID `json:"chainIndex"`
+	TokenAddress string         `json:"tokenAddress"`
 }
 
 type TokenPrice struct {
-	ChainIndex   string `json:"chainIndex"`
-	TokenAddress string `json:"tokenAddress"`
-	Price        string `json:"price"`
-	Time         string `json:"time"`
+	ChainIndex   chains.ChainID `json:"chainIndex"`
+	TokenAddress string         `json:"tokenAddress"`
+	Price        types.Decimal  `json:"price"`
+	Time         types.Millis   `json:"time"`
 }
 
 func (w *WalletAPI) TokenCurrentPrice(ctx context.Context, req []*TokenIndexPriceRequest) (result []TokenPrice, err error) {
@@ -57,7 +77,7 @@ func (w *WalletAPI) TokenCurrentPrice(ctx context.Context, req []*TokenIndexPric
 }
 
 type GetRealTimeTokenPriceRequest struct {
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 	// Token address.
 	// 1: Pass an empty string "" to query the native token of the corresponding chain.
 	// 2: Pass the specific token contract address to query the corresponding token.
@@ -76,7 +96,7 @@ func (w *WalletAPI) GetRealTimeTokenPrice(ctx context.Context, req []*GetRealTim
 }
 
 type HistoricalTokenPriceRequest struct {
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 	// Token address.
 	// 1: Pass an empty string "" to query the native token of the corresponding chain.
 	// 2: Pass the specific token contract address to query the corresponding token.
@@ -87,22 +107,36 @@ type HistoricalTokenPriceRequest struct {
 	// SRC-20: Use btc-src20-name, such as btc-src20-utxo
 	TokenAddress string `json:"tokenAddress"`
 	// Number of entries per query, default is 50, maximum is 200
-	Limit int64 `json:"limit"`
+	Limit int64 `json:"limit,omitempty"`
 	// Cursor position, defaults to the first entry
-	Cursor int64 `json:"cursor"`
+	Cursor int64 `json:"cursor,omitempty"`
 	// Start time to query historical prices after. Unix timestamp in milliseconds
-	Begin int64 `json:"begin"`
+	Begin int64 `json:"begin,omitempty"`
 	// End time to query historical prices before.
 	// If neither begin nor end is provided, query historical prices before the current time.
 	// Unix timestamp in milliseconds
-	End int64 `json:"end"`
+	End int64 `json:"end,omitempty"`
 	// Time interval unit:
 	// 1m: 1 minute
 	// 5m: 5 minutes
 	// 30m: 30 minutes
 	// 1h: 1 hour
 	// 1d: 1 day (default)
-	Period string `json:"period"`
+	Period string `json:"period,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *HistoricalTokenPriceRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainIndex", r.ChainIndex.String())
+	if r.Limit < 0 || r.Limit > 200 {
+		v.Add("limit", "must be between 0 and 200")
+	}
+	if r.Begin != 0 && r.End != 0 && r.Begin > r.End {
+		v.Add("begin", "must not be after end")
+	}
+	v.OneOf("period", r.Period, "1m", "5m", "30m", "1h", "1d")
+	return v.Err()
 }
 
 type HistoricalTokenPriceResult struct {
@@ -112,22 +146,28 @@ type HistoricalTokenPriceResult struct {
 
 // Historical Token Price
 func (w *WalletAPI) HistoricalTokenPrice(ctx context.Context, req *HistoricalTokenPriceRequest) (result *HistoricalTokenPriceResult, err error) {
-	params := map[string]string{
-		"chainIndex":   req.ChainIndex,
-		"tokenAddress": req.TokenAddress,
-		"limit":        strconv.FormatInt(req.Limit, 10),
-		"cursor":       strconv.FormatInt(req.Cursor, 10),
-		"begin":        strconv.FormatInt(req.Begin, 10),
-		"end":          strconv.FormatInt(req.End, 10),
-		"period":       req.Period,
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 	err = w.tr.Get(ctx, "/api/v5/wallet/token/historical-price", params, &result)
 	return
 }
 
 type ProjectInformationRequest struct {
-	ChainIndex   string `json:"chainIndex"`
-	TokenAddress string `json:"tokenAddress"`
+	ChainIndex   chains.ChainID `json:"chainIndex"`
+	TokenAddress string         `json:"tokenAddress"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *ProjectInformationRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainIndex", r.ChainIndex.String())
+	v.Required("tokenAddress", r.TokenAddress)
+	return v.Err()
 }
 
 type SocialUrls struct {
@@ -139,25 +179,28 @@ type SocialUrls struct {
 }
 
 type ProjectInformation struct {
-	LogoUrl         string     `json:"logoUrl"`
-	OfficialWebsite string     `json:"officialWebsite"`
-	SocialUrls      SocialUrls `json:"socialUrls"`
-	Decimals        string     `json:"decimals"`
-	TokenAddress    string     `json:"tokenAddress"`
-	ChainIndex      string     `json:"chainIndex"`
-	ChainName       string     `json:"chainName"`
-	Symbol          string     `json:"symbol"`
-	MaxSupply       string     `json:"maxSupply"`
-	TotalSupply     string     `json:"totalSupply"`
-	Volume24h       string     `json:"volume24h"`
-	MarketCap       string     `json:"marketCap"`
+	LogoUrl         string         `json:"logoUrl"`
+	OfficialWebsite string         `json:"officialWebsite"`
+	SocialUrls      SocialUrls     `json:"socialUrls"`
+	Decimals        types.Int      `json:"decimals"`
+	TokenAddress    string         `json:"tokenAddress"`
+	ChainIndex      chains.ChainID `json:"chainIndex"`
+	ChainName       string         `json:"chainName"`
+	Symbol          string         `json:"symbol"`
+	MaxSupply       types.Decimal  `json:"maxSupply"`
+	TotalSupply     types.Decimal  `json:"totalSupply"`
+	Volume24h       types.Decimal  `json:"volume24h"`
+	MarketCap       types.Decimal  `json:"marketCap"`
 }
 
 // Project Information
 func (w *WalletAPI) ProjectInformation(ctx context.Context, req *ProjectInformationRequest) (result *ProjectInformation, err error) {
-	params := map[string]string{
-		"chainIndex":   req.ChainIndex,
-		"tokenAddress": req.TokenAddress,
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 	var results []*ProjectInformation
 	err = w.tr.Get(ctx, "/api/v5/wallet/token/token-detail", params, &results)
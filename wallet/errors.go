@@ -19,7 +19,11 @@
 
 package wallet
 
-import "github.com/imzhongqi/okxos/errcode"
+import (
+	"strings"
+
+	"github.com/imzhongqi/okxos/errcode"
+)
 
 // IsBlockchainNotSupported 81104 Blockchain not supported
 func IsBlockchainNotSupported(err error) bool {
@@ -120,3 +124,28 @@ func IsAddressIsIllegal(err error) bool {
 func IsNodeReturnFailed(err error) bool {
 	return errcode.Is(err, 81451)
 }
+
+// IsTxAlreadyKnown reports whether a broadcast was refused because the node already has the
+// transaction, e.g. after it was broadcast before.
+func IsTxAlreadyKnown(err error) bool {
+	return errorContains(err, "already known", "known transaction", "already imported", "already exists")
+}
+
+// IsNonceTooLow reports whether a broadcast was refused because the nonce of the transaction
+// is already used, by this transaction or another one.
+func IsNonceTooLow(err error) bool {
+	return errorContains(err, "nonce too low", "nonce has already been used")
+}
+
+func errorContains(err error, substrs ...string) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range substrs {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
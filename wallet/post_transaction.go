@@ -21,59 +21,60 @@ package wallet
 
 import (
 	"context"
-	"strconv"
-	"strings"
+	"encoding/json"
 
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type TransactionOrderRequest struct {
-	Address    string `json:"address"`
-	AccountId  string `json:"accountId"`
-	ChainIndex string `json:"chainIndex"`
+	Address    string         `json:"address,omitempty"`
+	AccountId  string         `json:"accountId,omitempty"`
+	ChainIndex chains.ChainID `json:"chainIndex,omitempty"`
 	// Transaction Status
 	// 1: Pending
 	// 2: Success
 	// 3: Failed
-	TxStatus string `json:"txStatus"`
-	OrderId  string `json:"orderId"`
-	Cursor   string `json:"cursor"`
-	Limit    string `json:"limit"`
+	TxStatus string `json:"txStatus,omitempty"`
+	OrderId  string `json:"orderId,omitempty"`
+	Cursor   string `json:"cursor,omitempty"`
+	Limit    string `json:"limit,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *TransactionOrderRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	if r.Address == "" && r.AccountId == "" {
+		v.Add("address", "is required when accountId is not set")
+	}
+	address.Check(v, "address", r.ChainIndex, r.Address)
+	v.OneOf("txStatus", r.TxStatus, "1", "2", "3")
+	return v.Err()
 }
 
 type TransactionOrder struct {
-	ChainIndex int64  `json:"chainIndex"`
-	Address    string `json:"address"`
-	AccountId  string `json:"accountId"`
-	OrderId    string `json:"orderId"`
-	TxStatus   string `json:"txStatus"`
-	TxHash     string `json:"txHash"`
-	Limit      string `json:"limit"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
+	Address    string         `json:"address"`
+	AccountId  string         `json:"accountId"`
+	OrderId    string         `json:"orderId"`
+	TxStatus   string         `json:"txStatus"`
+	TxHash     string         `json:"txHash"`
+	Limit      string         `json:"limit"`
 }
 
 // Get Transaction Order
 func (w *WalletAPI) GetTransactionOrder(ctx context.Context, req *TransactionOrderRequest) (result []TransactionOrder, err error) {
-	params := map[string]string{}
-	if req.Address != "" {
-		params["address"] = req.Address
-	}
-	if req.AccountId != "" {
-		params["accountId"] = req.AccountId
-	}
-	if req.ChainIndex != "" {
-		params["chainIndex"] = req.ChainIndex
-	}
-	if req.TxStatus != "" {
-		params["txStatus"] = req.TxStatus
-	}
-	if req.OrderId != "" {
-		params["orderId"] = req.OrderId
-	}
-	if req.Cursor != "" {
-		params["cursor"] = req.Cursor
+	if err = req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.Limit != "" {
-		params["limit"] = req.Limit
+	n := *req
+	n.Address = address.Canonical(req.ChainIndex, req.Address)
+	params, err := client.EncodeQuery(&n)
+	if err != nil {
+		return nil, err
 	}
 	var results []TransactionOrder
 	err = w.tr.Get(ctx, "/api/v5/wallet/post-transaction/orders", params, &results)
@@ -88,35 +89,58 @@ func (w *WalletAPI) GetTransactionOrder(ctx context.Context, req *TransactionOrd
 
 type GetTransactionHistoryByAddressRequest struct {
 	// Address to query the transaction history for
-	Address string   `json:"address"`
-	Chains  []string `json:"chains"`
+	Address string           `json:"address"`
+	Chains  []chains.ChainID `json:"chains,omitempty"`
 	// Unique identifier for the chain, e.g. ETH=3
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex,omitempty"`
 	// Token contract address; if empty, query addresses with main chain currency balance;if not pass, query all
-	TokenAddress string `json:"tokenAddress"`
+	TokenAddress string `json:"tokenAddress,omitempty"`
 	// Start time, queries transactions after this time. Unix timestamp, in milliseconds, e.g., 1597026383085
-	Begin string `json:"begin"`
+	Begin string `json:"begin,omitempty"`
 	// End time, queries transactions before this time. If both begin and end are not provided, queries transactions before the current time. Unix timestamp, in milliseconds, e.g., 1597026383085
-	End string `json:"end"`
+	End string `json:"end,omitempty"`
 	// Cursor
-	Cursor string `json:"cursor"`
+	Cursor string `json:"cursor,omitempty"`
 	// Number of records to return, defaults to the most recent 20 records.
-	Limit string `json:"limit"`
+	Limit string `json:"limit,omitempty"`
 	// Option to filter out potentially risky airdrop tokens.
 	// Defaults to filtering.
 	// true: filter
 	// false: do not filter
-	ExcludeRiskToken *bool `json:"excludeRiskToken"`
+	ExcludeRiskToken *bool `json:"excludeRiskToken,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetTransactionHistoryByAddressRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("address", r.Address)
+	checkAddress(v, "address", r.chainIds(), r.Address)
+	address.CheckToken(v, "tokenAddress", r.ChainIndex, r.TokenAddress)
+	return v.Err()
+}
+
+func (r *GetTransactionHistoryByAddressRequest) chainIds() []chains.ChainID {
+	if r.ChainIndex != "" {
+		return []chains.ChainID{r.ChainIndex}
+	}
+	return r.Chains
+}
+
+func (r *GetTransactionHistoryByAddressRequest) normalize() *GetTransactionHistoryByAddressRequest {
+	n := *r
+	n.Address = canonicalAddress(r.chainIds(), r.Address)
+	n.TokenAddress = address.NormalizeToken(r.ChainIndex, r.TokenAddress)
+	return &n
 }
 
 type AddressBalance struct {
-	Address string `json:"address"`
-	Amount  string `json:"amount"`
+	Address string        `json:"address"`
+	Amount  types.Decimal `json:"amount"`
 }
 
 type TransactionHistory struct {
 	// Unique identifier for the chain, e.g. ETH=3
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 	// Transaction hash
 	TxHash string `json:"txHash"`
 	// EVM Transaction tier type
@@ -127,9 +151,9 @@ type TransactionHistory struct {
 	// Contract Function Call
 	MethodId string `json:"methodId"`
 	// The nth transaction initiated by the sender address
-	Nonce string `json:"nonce"`
+	Nonce types.Int `json:"nonce"`
 	// Transaction time in Unix timestamp format, in milliseconds, e.g., 1597026383085
-	TxTime string `json:"txTime"`
+	TxTime types.Millis `json:"txTime"`
 	// Sending/input address, comma-separated for multi-signature transactions
 	From []*AddressBalance `json:"from"`
 	// Receiving/output address, comma-separated for multiple addresses
@@ -137,17 +161,26 @@ type TransactionHistory struct {
 	// Token contract address
 	TokenAddress string `json:"tokenAddress"`
 	// Transaction amount
-	Amount string `json:"amount"`
+	Amount types.Decimal `json:"amount"`
 	// Currency symbol corresponding to the transaction amount
 	Symbol string `json:"symbol"`
 	// Transaction fee
-	TxFee string `json:"txFee"`
+	TxFee types.Decimal `json:"txFee"`
 	// Transaction status: success for successful transactions, fail for failed transactions, pending for pending transactions
 	TxStatus string `json:"txStatus"`
 	// false: Not in blacklist, true: In blacklist
 	HitBlacklist bool `json:"hitBlacklist"`
 	// Tag type for blacklisted addresses, including phishing, contract vulnerabilities, etc.
 	Tag string `json:"tag"`
+
+	// Extra holds the response fields that are not declared above yet.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *TransactionHistory) UnmarshalJSON(data []byte) (err error) {
+	type alias TransactionHistory
+	r.Extra, err = client.DecodeExtra(data, (*alias)(r))
+	return
 }
 
 type GetTransactionHistoryByAddressResult struct {
@@ -159,29 +192,12 @@ type GetTransactionHistoryByAddressResult struct {
 
 // Get Transaction History By Address
 func (w *WalletAPI) GetTransactionHistoryByAddress(ctx context.Context, req *GetTransactionHistoryByAddressRequest) (result *GetTransactionHistoryByAddressResult, err error) {
-	params := map[string]string{
-		"address": req.Address,
-	}
-	if len(req.Chains) > 0 {
-		params["chains"] = strings.Join(req.Chains, ",")
-	}
-	if req.TokenAddress != "" {
-		params["tokenAddress"] = req.TokenAddress
-	}
-	if req.Begin != "" {
-		params["begin"] = req.Begin
-	}
-	if req.End != "" {
-		params["end"] = req.End
-	}
-	if req.Cursor != "" {
-		params["cursor"] = req.Cursor
-	}
-	if req.Limit != "" {
-		params["limit"] = req.Limit
+	if err = req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.ExcludeRiskToken != nil {
-		params["excludeRiskToken"] = strconv.FormatBool(*req.ExcludeRiskToken)
+	params, err := client.EncodeQuery(req.normalize())
+	if err != nil {
+		return nil, err
 	}
 	var results []*GetTransactionHistoryByAddressResult
 	err = w.tr.Get(ctx, "/api/v5/wallet/post-transaction/transactions-by-address", params, &results)
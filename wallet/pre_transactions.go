@@ -25,16 +25,28 @@ import (
 	"encoding/json"
 	"io"
 
+	"github.com/imzhongqi/okxos/address"
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/client"
 	"github.com/imzhongqi/okxos/errcode"
+	"github.com/imzhongqi/okxos/types"
 )
 
 type ValidateAddressRequest struct {
 	// Unique identifier for the chain
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 	// Address
 	Address string `json:"address"`
 }
 
+// Validate reports the fields of the request that are missing or malformed.
+func (r *ValidateAddressRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainIndex", r.ChainIndex.String())
+	v.Required("address", r.Address)
+	return v.Err()
+}
+
 // AddressType
 // 0: Invalid address format
 // 1: Valid user address
@@ -63,9 +75,12 @@ type ValidateAddressResult struct {
 
 // ValidateAddress Provide an address to determine if it is a valid user or contract address, and whether it has hit blacklist check.
 func (w *WalletAPI) ValidateAddress(ctx context.Context, req *ValidateAddressRequest) (result *ValidateAddressResult, err error) {
-	params := map[string]string{
-		"chainIndex": req.ChainIndex,
-		"address":    req.Address,
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := client.EncodeQuery(req)
+	if err != nil {
+		return nil, err
 	}
 	var results []*ValidateAddressResult
 	err = w.tr.Get(ctx, "/api/v5/wallet/pre-transaction/validate-address", params, &results)
@@ -79,10 +94,20 @@ func (w *WalletAPI) ValidateAddress(ctx context.Context, req *ValidateAddressReq
 }
 
 type TransactionBroadcastRequest struct {
-	SignedTx   string `json:"signedTx"`
-	ChainIndex string `json:"chainIndex"`
-	Address    string `json:"address"`
-	AccountId  string `json:"accountId"`
+	SignedTx   string         `json:"signedTx"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
+	Address    string         `json:"address"`
+	AccountId  string         `json:"accountId"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *TransactionBroadcastRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("signedTx", r.SignedTx)
+	v.Required("chainIndex", r.ChainIndex.String())
+	v.Required("address", r.Address)
+	address.Check(v, "address", r.ChainIndex, r.Address)
+	return v.Err()
 }
 
 type TransactionBroadcastResult struct {
@@ -90,8 +115,13 @@ type TransactionBroadcastResult struct {
 }
 
 func (w *WalletAPI) TransactionBroadcast(ctx context.Context, tx *TransactionBroadcastRequest) (result *TransactionBroadcastResult, err error) {
+	if err = tx.Validate(); err != nil {
+		return nil, err
+	}
+	n := *tx
+	n.Address = address.Canonical(tx.ChainIndex, tx.Address)
 	var results []*TransactionBroadcastResult
-	err = w.tr.Post(ctx, "/api/v5/wallet/pre-transaction/broadcast-transaction", tx, &results)
+	err = w.tr.Post(ctx, "/api/v5/wallet/pre-transaction/broadcast-transaction", &n, &results)
 	if err != nil {
 		return nil, err
 	}
@@ -103,20 +133,32 @@ func (w *WalletAPI) TransactionBroadcast(ctx context.Context, tx *TransactionBro
 
 type GetNonceRequest struct {
 	// Unique identifier for the chain
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 	// Address
 	Address string `json:"address"`
 }
 
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetNonceRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainIndex", r.ChainIndex.String())
+	v.Required("address", r.Address)
+	address.Check(v, "address", r.ChainIndex, r.Address)
+	return v.Err()
+}
+
 type GetNonceResult struct {
-	Nonce        string `json:"nonce"`
-	PendingNonce string `json:"pendingNonce"`
+	Nonce        types.Int `json:"nonce"`
+	PendingNonce types.Int `json:"pendingNonce"`
 }
 
 func (w *WalletAPI) GetNonce(ctx context.Context, req *GetNonceRequest) (result *GetNonceResult, err error) {
-	params := map[string]string{
-		"chainIndex": req.ChainIndex,
-		"address":    req.Address,
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := client.EncodeQuery(&GetNonceRequest{ChainIndex: req.ChainIndex, Address: address.Canonical(req.ChainIndex, req.Address)})
+	if err != nil {
+		return nil, err
 	}
 	var results []*GetNonceResult
 	err = w.tr.Get(ctx, "/api/v5/wallet/pre-transaction/nonce", params, &results)
@@ -131,20 +173,31 @@ func (w *WalletAPI) GetNonce(ctx context.Context, req *GetNonceRequest) (result
 
 type GetSuiObjectRequest struct {
 	// Unique identifier for the chain
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 	// Wallet address
 	Address string `json:"address"`
 	// Token address
 	TokenAddress string `json:"tokenAddress"`
 	// Number of entries per query, default is 50, maximum is 50
-	Limit string `json:"limit"`
+	Limit string `json:"limit,omitempty"`
 	// Cursor position, defaults to the first entry
-	Cursor string `json:"cursor"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetSuiObjectRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainIndex", r.ChainIndex.String())
+	v.Required("address", r.Address)
+	address.Check(v, "address", r.ChainIndex, r.Address)
+	v.Required("tokenAddress", r.TokenAddress)
+	v.Range("limit", r.Limit, 1, 50)
+	return v.Err()
 }
 
 type SuiObject struct {
 	// Amount Token balance
-	Amount string `json:"amount"`
+	Amount types.Uint256 `json:"amount"`
 	// Digest 32-byte transaction summary indicating the last transaction that included this object as an output
 	Digest string `json:"digest"`
 	// Version 8-byte unsigned integer version, monotonically increasing with each transaction that modifies it
@@ -163,16 +216,14 @@ type SuiObjectResult struct {
 }
 
 func (w *WalletAPI) GetSuiObject(ctx context.Context, req *GetSuiObjectRequest) (result *SuiObjectResult, err error) {
-	params := map[string]string{
-		"chainIndex":   req.ChainIndex,
-		"address":      req.Address,
-		"tokenAddress": req.TokenAddress,
-	}
-	if req.Limit != "" {
-		params["limit"] = req.Limit
+	if err = req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.Cursor != "" {
-		params["cursor"] = req.Cursor
+	n := *req
+	n.Address = address.Canonical(req.ChainIndex, req.Address)
+	params, err := client.EncodeQuery(&n)
+	if err != nil {
+		return nil, err
 	}
 	var results []*SuiObjectResult
 	err = w.tr.Get(ctx, "/api/v5/wallet/pre-transaction/sui-object", params, &results)
@@ -187,7 +238,7 @@ func (w *WalletAPI) GetSuiObject(ctx context.Context, req *GetSuiObjectRequest)
 
 type GetSignInfoRequest struct {
 	// Unique identifier for the chain
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 	// From address
 	FromAddr string `json:"fromAddr"`
 	// To address
@@ -201,6 +252,17 @@ type GetSignInfoRequest struct {
 	ExtJson *ExtJson `json:"extJson,omitempty"`
 }
 
+// Validate reports the fields of the request that are missing or malformed.
+func (r *GetSignInfoRequest) Validate() error {
+	v := new(errcode.ValidationError)
+	v.Required("chainIndex", r.ChainIndex.String())
+	v.Required("fromAddr", r.FromAddr)
+	v.Required("toAddr", r.ToAddr)
+	address.Check(v, "fromAddr", r.ChainIndex, r.FromAddr)
+	address.Check(v, "toAddr", r.ChainIndex, r.ToAddr)
+	return v.Err()
+}
+
 type ExtJson struct {
 	// Only appliable to EVM
 	InputData string `json:"inputData"`
@@ -222,69 +284,69 @@ type ExtJson struct {
 }
 
 type SignInfoEvm struct {
-	GasLimit string    `json:"gasLimit"`
-	Nonce    string    `json:"nonce"`
-	GasPrice *GasPrice `json:"gasPrice"`
+	GasLimit types.Uint256 `json:"gasLimit"`
+	Nonce    types.Int     `json:"nonce"`
+	GasPrice *GasPrice     `json:"gasPrice"`
 }
 
 type Eip1559Protocol struct {
-	BaseFee            string `json:"baseFee"`
-	FastPriorityFee    string `json:"fastPriorityFee"`
-	SafePriorityFee    string `json:"safePriorityFee"`
-	SuggestGasPrice    string `json:"suggestGasPrice"`
-	ProposePriorityFee string `json:"proposePriorityFee"`
+	BaseFee            types.Uint256 `json:"baseFee"`
+	FastPriorityFee    types.Uint256 `json:"fastPriorityFee"`
+	SafePriorityFee    types.Uint256 `json:"safePriorityFee"`
+	SuggestGasPrice    types.Uint256 `json:"suggestGasPrice"`
+	ProposePriorityFee types.Uint256 `json:"proposePriorityFee"`
 }
 
 type GasPrice struct {
-	Normal           string           `json:"normal"`
-	Min              string           `json:"min"`
-	Max              string           `json:"max"`
+	Normal           types.Uint256    `json:"normal"`
+	Min              types.Uint256    `json:"min"`
+	Max              types.Uint256    `json:"max"`
 	SupportedEip1559 bool             `json:"supportedEip1559"`
 	Eip1559Protocol  *Eip1559Protocol `json:"eip1559Protocol"`
 }
 
 type SignInfoUtxo struct {
-	NormalFeeRate     string `json:"normalFeeRate"`
-	MaxFeeRate        string `json:"maxFeeRate"`
-	MinFeeRate        string `json:"minFeeRate"`
-	InscriptionOutput string `json:"inscriptionOutput"`
-	MinOutput         string `json:"minOutput"`
-	NormalCost        string `json:"normalCost"`
-	MaxCost           string `json:"maxCost"`
-	MinCost           string `json:"minCost"`
+	NormalFeeRate     types.Decimal `json:"normalFeeRate"`
+	MaxFeeRate        types.Decimal `json:"maxFeeRate"`
+	MinFeeRate        types.Decimal `json:"minFeeRate"`
+	InscriptionOutput types.Uint256 `json:"inscriptionOutput"`
+	MinOutput         types.Uint256 `json:"minOutput"`
+	NormalCost        types.Decimal `json:"normalCost"`
+	MaxCost           types.Decimal `json:"maxCost"`
+	MinCost           types.Decimal `json:"minCost"`
 }
 
 type SignInfoSolana struct {
-	BaseFee              string            `json:"baseFee"`
+	BaseFee              types.Uint256     `json:"baseFee"`
 	PriorityFee          *PriorityFee      `json:"priorityFee"`
 	RecentBlockHash      string            `json:"recentBlockHash"`
-	LastValidBlockHeight string            `json:"lastValidBlockHeight"`
-	FromAddressRent      string            `json:"fromAddressRent"`
-	ToAddressRent        string            `json:"toAddressRent"`
+	LastValidBlockHeight types.Int         `json:"lastValidBlockHeight"`
+	FromAddressRent      types.Uint256     `json:"fromAddressRent"`
+	ToAddressRent        types.Uint256     `json:"toAddressRent"`
 	TokenAccountInfo     *TokenAccountInfo `json:"tokenAccountInfo"`
 }
 
 type PriorityFee struct {
 	// Normal unit price for the transaction
-	NormalUnitPrice string `json:"normalUnitPrice"`
+	NormalUnitPrice types.Uint256 `json:"normalUnitPrice"`
 	// Min unit price for the transaction
-	MinUnitPrice string `json:"minUnitPrice"`
+	MinUnitPrice types.Uint256 `json:"minUnitPrice"`
 	// Max unit price for the transaction
-	MaxUnitPrice string `json:"maxUnitPrice"`
+	MaxUnitPrice types.Uint256 `json:"maxUnitPrice"`
 }
 
 type TokenAccountInfo struct {
-	Lamports string `json:"lamports"`
+	Lamports types.Uint256 `json:"lamports"`
 	// OwnerAddress from address
 	OwnerAddress string `json:"ownerAddress"`
 	// MintAddress token address
-	MintAddress         string `json:"mintAddress"`
-	TokenAccountAddress string `json:"tokenAccountAddress"`
-	Decimal             string `json:"decimal"`
+	MintAddress         string    `json:"mintAddress"`
+	TokenAccountAddress string    `json:"tokenAccountAddress"`
+	Decimal             types.Int `json:"decimal"`
 }
 
 type SignInfoTron struct {
-	Fee string `json:"fee"`
+	Fee types.Uint256 `json:"fee"`
 	// RefBlockBytes Reference block bytes The 6th to 8th bytes (not included) of the reference block height are used to help
 	// verify whether the transaction is based on the valid state of the current blockchain and prevent forked transaction replay
 	RefBlockBytes string `json:"refBlockBytes"`
@@ -293,9 +355,9 @@ type SignInfoTron struct {
 	// the transaction may be rejected or marked as invalid.
 	RefBlockHash string `json:"refBlockHash"`
 	// Expiration for the transaction
-	Expiration string `json:"expiration"`
+	Expiration types.Millis `json:"expiration"`
 	// Timestamp for the transaction
-	Timestamp string `json:"timestamp"`
+	Timestamp types.Millis `json:"timestamp"`
 }
 
 type SignInfoResult struct {
@@ -344,8 +406,14 @@ func (s *SignInfoResult) UnmarshalJSON(data []byte) (err error) {
 }
 
 func (w *WalletAPI) GetSignInfo(ctx context.Context, req *GetSignInfoRequest) (result *SignInfoResult, err error) {
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	n := *req
+	n.FromAddr = address.Canonical(req.ChainIndex, req.FromAddr)
+	n.ToAddr = address.Canonical(req.ChainIndex, req.ToAddr)
 	var results []*SignInfoResult
-	err = w.tr.Post(ctx, "/api/v5/wallet/pre-transaction/sign-info", req, &results)
+	err = w.tr.Post(ctx, "/api/v5/wallet/pre-transaction/sign-info", &n, &results)
 	if err != nil {
 		return nil, err
 	}
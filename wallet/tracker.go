@@ -0,0 +1,323 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/errcode"
+)
+
+var ErrTrackTimeout = errors.New("wallet: timed out waiting for transaction orders")
+
+// OrderStatus is the status of a transaction order.
+type OrderStatus string
+
+const (
+	// OrderUnknown is the status of an order that is not visible yet.
+	OrderUnknown OrderStatus = "unknown"
+	OrderPending OrderStatus = "pending"
+	OrderSuccess OrderStatus = "success"
+	OrderFailed  OrderStatus = "failed"
+)
+
+// ParseOrderStatus maps the txStatus of a transaction order to an OrderStatus.
+func ParseOrderStatus(txStatus string) OrderStatus {
+	switch txStatus {
+	case "1":
+		return OrderPending
+	case "2":
+		return OrderSuccess
+	case "3":
+		return OrderFailed
+	}
+	return OrderUnknown
+}
+
+// IsFinal reports whether s is a terminal status.
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderSuccess || s == OrderFailed
+}
+
+// Status returns the typed status of the order.
+func (o *TransactionOrder) Status() OrderStatus {
+	return ParseOrderStatus(o.TxStatus)
+}
+
+// OrderRef identifies a broadcast transaction order. Either Address or AccountId is required.
+type OrderRef struct {
+	ChainIndex chains.ChainID
+	Address    string
+	AccountId  string
+	OrderId    string
+}
+
+// OrderTransition is a change of the status of a tracked order.
+type OrderTransition struct {
+	Ref   OrderRef
+	From  OrderStatus
+	To    OrderStatus
+	Order *TransactionOrder
+	Time  time.Time
+}
+
+type TrackerOptions struct {
+	pollInterval time.Duration
+	maxInterval  time.Duration
+	timeout      time.Duration
+	onTransition func(OrderTransition)
+	// onWait, if set, is called with every interval waited between polls.
+	onWait func(time.Duration)
+}
+
+type TrackerOption interface {
+	apply(o *TrackerOptions)
+}
+
+type trackerOptionFunc func(o *TrackerOptions)
+
+func (f trackerOptionFunc) apply(o *TrackerOptions) {
+	f(o)
+}
+
+// WithTrackBackoff sets the first and the largest interval between polls, 2s and 30s by default.
+// The interval doubles while no order changes and resets when one does. An interval that is not
+// positive keeps its default.
+func WithTrackBackoff(initial, maxInterval time.Duration) TrackerOption {
+	return trackerOptionFunc(func(o *TrackerOptions) {
+		if initial > 0 {
+			o.pollInterval = initial
+		}
+		if maxInterval > 0 {
+			o.maxInterval = maxInterval
+		}
+	})
+}
+
+// WithTrackTimeout sets how long to wait for orders to be final, 10m by default.
+// Zero waits until the context is done.
+func WithTrackTimeout(d time.Duration) TrackerOption {
+	return trackerOptionFunc(func(o *TrackerOptions) {
+		o.timeout = d
+	})
+}
+
+// WithTransitionHandler calls handler with every status change of the tracked orders.
+// It is called synchronously, so it should not block.
+func WithTransitionHandler(handler func(OrderTransition)) TrackerOption {
+	return trackerOptionFunc(func(o *TrackerOptions) {
+		o.onTransition = handler
+	})
+}
+
+// Tracker broadcasts transactions and polls their orders until they are final.
+type Tracker struct {
+	w    *WalletAPI
+	opts TrackerOptions
+}
+
+// NewTracker returns a Tracker polling w.
+func NewTracker(w *WalletAPI, opts ...TrackerOption) *Tracker {
+	o := TrackerOptions{pollInterval: 2 * time.Second, maxInterval: 30 * time.Second, timeout: 10 * time.Minute}
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+	if o.maxInterval < o.pollInterval {
+		o.maxInterval = o.pollInterval
+	}
+	return &Tracker{w: w, opts: o}
+}
+
+// Broadcast broadcasts tx and waits for its order to be final.
+func (t *Tracker) Broadcast(ctx context.Context, tx *TransactionBroadcastRequest) (*TransactionOrder, error) {
+	result, err := t.w.TransactionBroadcast(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return t.Wait(ctx, OrderRef{ChainIndex: tx.ChainIndex, Address: tx.Address, AccountId: tx.AccountId, OrderId: result.OrderId})
+}
+
+// Wait polls the order of ref until it is final.
+func (t *Tracker) Wait(ctx context.Context, ref OrderRef) (*TransactionOrder, error) {
+	orders, err := t.WaitAll(ctx, []OrderRef{ref})
+	return orders[0], err
+}
+
+type orderGroup struct {
+	chainIndex chains.ChainID
+	address    string
+	accountId  string
+}
+
+// WaitAll polls the orders of refs until all of them are final, and returns them in the order
+// of refs. Orders of the same chain and address are polled with a single request when possible.
+// Failed polls are retried until the timeout, only invalid requests fail at once. On timeout or
+// cancellation, it returns the last seen orders, nil for those never seen, with ErrTrackTimeout,
+// wrapping the last poll error if any, or the error of ctx.
+func (t *Tracker) WaitAll(ctx context.Context, refs []OrderRef) ([]*TransactionOrder, error) {
+	orders := make([]*TransactionOrder, len(refs))
+	status := make([]OrderStatus, len(refs))
+	for i := range status {
+		status[i] = OrderUnknown
+	}
+
+	var deadline <-chan time.Time
+	if t.opts.timeout > 0 {
+		timer := time.NewTimer(t.opts.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	interval := t.opts.pollInterval
+	var lastErr error
+	for {
+		changed, err := t.poll(ctx, refs, orders, status)
+		if errcode.IsValidationError(err) {
+			return orders, err
+		}
+		if err != nil {
+			lastErr = err
+		}
+		done := true
+		for _, s := range status {
+			done = done && s.IsFinal()
+		}
+		if done {
+			return orders, nil
+		}
+
+		if changed {
+			interval = t.opts.pollInterval
+		} else if interval *= 2; interval > t.opts.maxInterval {
+			interval = t.opts.maxInterval
+		}
+		if t.opts.onWait != nil {
+			t.opts.onWait(interval)
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return orders, ctx.Err()
+		case <-deadline:
+			timer.Stop()
+			if lastErr != nil {
+				return orders, fmt.Errorf("%w: %v", ErrTrackTimeout, lastErr)
+			}
+			return orders, ErrTrackTimeout
+		case <-timer.C:
+		}
+	}
+}
+
+// poll refreshes the orders that are not final yet and reports whether any status changed.
+// A failed request does not stop the others, the first error is returned.
+func (t *Tracker) poll(ctx context.Context, refs []OrderRef, orders []*TransactionOrder, status []OrderStatus) (bool, error) {
+	groups := make(map[orderGroup][]int)
+	var keys []orderGroup
+	for i, ref := range refs {
+		if status[i].IsFinal() {
+			continue
+		}
+		g := orderGroup{chainIndex: ref.ChainIndex, address: strings.ToLower(ref.Address), accountId: ref.AccountId}
+		if _, ok := groups[g]; !ok {
+			keys = append(keys, g)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	var changed bool
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	update := func(i int, o *TransactionOrder) {
+		s := o.Status()
+		orders[i] = o
+		if s == status[i] {
+			return
+		}
+		changed = true
+		if t.opts.onTransition != nil {
+			t.opts.onTransition(OrderTransition{Ref: refs[i], From: status[i], To: s, Order: o, Time: time.Now()})
+		}
+		status[i] = s
+	}
+
+	for _, g := range keys {
+		indexes := groups[g]
+		ref := refs[indexes[0]]
+		missing := indexes
+		if len(indexes) > 1 {
+			// One request for the recent orders of the address, then one per order not among them.
+			list, err := t.w.GetTransactionOrder(ctx, &TransactionOrderRequest{
+				Address:    ref.Address,
+				AccountId:  ref.AccountId,
+				ChainIndex: ref.ChainIndex,
+				Limit:      "100",
+			})
+			if err != nil && !errors.Is(err, errcode.ErrResultsNotFound) {
+				fail(err)
+				continue
+			}
+			byId := make(map[string]*TransactionOrder, len(list))
+			for j := range list {
+				byId[list[j].OrderId] = &list[j]
+			}
+			missing = nil
+			for _, i := range indexes {
+				if o, ok := byId[refs[i].OrderId]; ok {
+					update(i, o)
+				} else {
+					missing = append(missing, i)
+				}
+			}
+		}
+		for _, i := range missing {
+			list, err := t.w.GetTransactionOrder(ctx, &TransactionOrderRequest{
+				Address:    refs[i].Address,
+				AccountId:  refs[i].AccountId,
+				ChainIndex: refs[i].ChainIndex,
+				OrderId:    refs[i].OrderId,
+			})
+			if errors.Is(err, errcode.ErrResultsNotFound) {
+				continue
+			}
+			if err != nil {
+				fail(err)
+				continue
+			}
+			for j := range list {
+				if list[j].OrderId == refs[i].OrderId {
+					update(i, &list[j])
+					break
+				}
+			}
+		}
+	}
+	return changed, firstErr
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wallet
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imzhongqi/okxos/internal/transporttest"
+)
+
+const trackerAddress = "0x3f6a3f57569358a512ccc0e513f171516b0fd42a"
+
+func TestTrackerWaitAll(t *testing.T) {
+	tr := transporttest.New(nil)
+	batches := 0
+	tr.Handle("/api/v5/wallet/post-transaction/orders", func(req *transporttest.Request) (string, error) {
+		if req.Params["limit"] == "100" {
+			batches++
+			switch {
+			case batches == 2:
+				return "", errors.New("temporarily unavailable")
+			case batches < 5:
+				return `[{"orderId":"a","txStatus":"1"},{"orderId":"other","txStatus":"2"}]`, nil
+			}
+			return `[{"orderId":"a","txStatus":"2"}]`, nil
+		}
+		// b is not among the recent orders and is looked up on its own.
+		switch {
+		case req.Params["orderId"] != "b":
+			return "", errors.New("unexpected order")
+		case batches < 3:
+			return `[]`, nil
+		case batches < 5:
+			return `[{"orderId":"b","txStatus":"1"}]`, nil
+		}
+		return `[{"orderId":"b","txStatus":"3"}]`, nil
+	})
+
+	var waits []time.Duration
+	var transitions []string
+	tracker := NewTracker(NewWalletAPI(tr),
+		WithTrackBackoff(time.Millisecond, 8*time.Millisecond),
+		WithTransitionHandler(func(ot OrderTransition) {
+			transitions = append(transitions, ot.Ref.OrderId+":"+string(ot.From)+">"+string(ot.To))
+		}),
+	)
+	tracker.opts.onWait = func(d time.Duration) { waits = append(waits, d) }
+
+	orders, err := tracker.WaitAll(context.Background(), []OrderRef{
+		{ChainIndex: "1", Address: trackerAddress, OrderId: "a"},
+		{ChainIndex: "1", Address: trackerAddress, OrderId: "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orders[0].Status() != OrderSuccess || orders[1].Status() != OrderFailed {
+		t.Fatalf("statuses %s, %s", orders[0].Status(), orders[1].Status())
+	}
+
+	// the interval doubles while nothing changes, including the failed poll, and resets on a change.
+	wantWaits := []time.Duration{time.Millisecond, 2 * time.Millisecond, time.Millisecond, 2 * time.Millisecond}
+	if !reflect.DeepEqual(waits, wantWaits) {
+		t.Errorf("waits = %v, want %v", waits, wantWaits)
+	}
+	wantTransitions := []string{"a:unknown>pending", "b:unknown>pending", "a:pending>success", "b:pending>failed"}
+	if !reflect.DeepEqual(transitions, wantTransitions) {
+		t.Errorf("transitions = %v, want %v", transitions, wantTransitions)
+	}
+	if batches != 5 {
+		t.Errorf("%d batched polls, want 5", batches)
+	}
+}
+
+func TestTrackerTimeout(t *testing.T) {
+	tr := transporttest.New(map[string]string{
+		"/api/v5/wallet/post-transaction/orders": `[{"orderId":"a","txStatus":"1"}]`,
+	})
+	tracker := NewTracker(NewWalletAPI(tr), WithTrackBackoff(time.Millisecond, 2*time.Millisecond), WithTrackTimeout(20*time.Millisecond))
+	ref := OrderRef{ChainIndex: "1", Address: trackerAddress, OrderId: "a"}
+
+	order, err := tracker.Wait(context.Background(), ref)
+	if !errors.Is(err, ErrTrackTimeout) || order == nil || order.Status() != OrderPending {
+		t.Fatalf("Wait = %+v, %v", order, err)
+	}
+
+	tr.Handle("/api/v5/wallet/post-transaction/orders", func(*transporttest.Request) (string, error) {
+		return "", errors.New("node down")
+	})
+	order, err = tracker.Wait(context.Background(), ref)
+	if !errors.Is(err, ErrTrackTimeout) || !strings.Contains(err.Error(), "node down") || order != nil {
+		t.Fatalf("Wait = %+v, %v", order, err)
+	}
+}
+
+func TestTrackerBackoffDefaults(t *testing.T) {
+	// a zero interval would never grow, and poll in a tight loop.
+	tracker := NewTracker(nil, WithTrackBackoff(0, -time.Second))
+	if tracker.opts.pollInterval != 2*time.Second || tracker.opts.maxInterval != 30*time.Second {
+		t.Errorf("backoff = %s, %s, want the defaults", tracker.opts.pollInterval, tracker.opts.maxInterval)
+	}
+}
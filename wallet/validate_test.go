@@ -0,0 +1,47 @@
+// Copyright (c) 2024-NOW imzhongqi <imzhongqi@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imzhongqi/okxos/chains"
+	"github.com/imzhongqi/okxos/errcode"
+)
+
+func TestValidateNilElements(t *testing.T) {
+	const addr = "0x3f6a3f57569358a512ccc0e513f171516b0fd42a"
+	tests := []struct {
+		req interface{ Validate() error }
+		bad string
+	}{
+		{&CreateAccountRequest{Addresses: []*Address{{ChainIndex: chains.Ethereum, Address: addr}, nil}}, "addresses[1]"},
+		{&UpdateAccountRequest{AccountId: "a", UpdateType: UpdateTypeAdd, Addresses: []*Address{nil}}, "addresses[0]"},
+		{&GetTokenBalancesByAddressRequest{Address: addr, TokenAddresses: []*TokenAddress{nil}}, "tokenAddresses[0]"},
+		{&GetTokenBalancesByAccountRequest{AccountId: "a", TokenAddresses: []*TokenAddress{nil}}, "tokenAddresses[0]"},
+	}
+	for _, tt := range tests {
+		err := tt.req.Validate()
+		if !errcode.IsValidationError(err) || !strings.Contains(err.Error(), tt.bad+" is required") {
+			t.Errorf("%T.Validate() = %v, want %s required", tt.req, err, tt.bad)
+		}
+	}
+}
@@ -21,6 +21,8 @@ package wallet
 
 import (
 	"context"
+
+	"github.com/imzhongqi/okxos/chains"
 )
 
 type SubscribeRequest struct {
@@ -31,7 +33,7 @@ type SubscribeRequest struct {
 	// fee_fluctuation: Fee fluctuation
 	Type string `json:"type"`
 	// Chain Index
-	ChainIndex string `json:"chainIndex"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
 	// Name of the subscription
 	Name string `json:"name"`
 	// Fee fluctuation filter, applicable only when the type is fee_fluctuation
@@ -70,11 +72,11 @@ func (w *WalletAPI) Unsubscribe(ctx context.Context, reqs []*UnsubscribeRequest)
 }
 
 type SubscriptionListResult struct {
-	Id         string `json:"id"`
-	ChainIndex string `json:"chainIndex"`
-	Name       string `json:"name"`
-	Url        string `json:"url"`
-	Type       string `json:"type"`
+	Id         string         `json:"id"`
+	ChainIndex chains.ChainID `json:"chainIndex"`
+	Name       string         `json:"name"`
+	Url        string         `json:"url"`
+	Type       string         `json:"type"`
 }
 
 // Subscription List